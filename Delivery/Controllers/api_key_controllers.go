@@ -0,0 +1,78 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type ApiKeyController struct {
+	ApiKeyUsecase domain.ApiKeyUsecase
+}
+
+// CreatePersonal returns the new key; it cannot be fetched again.
+func (ac *ApiKeyController) CreatePersonal(c *gin.Context) {
+	var request domain.ApiKeyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	key, err := ac.ApiKeyUsecase.CreatePersonal(c, c.GetString("user_id"), c.GetString("username"), c.GetString("user_type"), &request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+func (ac *ApiKeyController) FetchPersonal(c *gin.Context) {
+	keys, err := ac.ApiKeyUsecase.FetchPersonal(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (ac *ApiKeyController) RevokePersonal(c *gin.Context) {
+	if err := ac.ApiKeyUsecase.RevokePersonal(c, c.GetString("user_id"), c.Param("key_id")); err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "api key revoked"})
+}
+
+// CreateService returns the new key; it cannot be fetched again.
+func (ac *ApiKeyController) CreateService(c *gin.Context) {
+	var request domain.ApiKeyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	key, err := ac.ApiKeyUsecase.CreateService(c, c.GetString("user_id"), &request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+func (ac *ApiKeyController) FetchService(c *gin.Context) {
+	keys, err := ac.ApiKeyUsecase.FetchService(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+func (ac *ApiKeyController) RevokeService(c *gin.Context) {
+	if err := ac.ApiKeyUsecase.RevokeService(c, c.Param("key_id")); err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "api key revoked"})
+}
@@ -0,0 +1,28 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type AuditController struct {
+	AuditUsecase domain.AuditUsecase
+}
+
+// FetchPage lists audit events, newest first, optionally of one type.
+func (ac *AuditController) FetchPage(c *gin.Context) {
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	events, err := ac.AuditUsecase.FetchPage(c, c.Query("type"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,110 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BoardController struct {
+	BoardUsecase domain.BoardUsecase
+}
+
+func (bc *BoardController) Create(c *gin.Context) {
+	var board domain.Board
+	if err := c.BindJSON(&board); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	board.ID = primitive.NewObjectID()
+	board.BoardID = board.ID.Hex()
+	board.CreatedBy = c.GetString("user_id")
+	board.CreatedAt = time.Now()
+	board.UpdatedAt = time.Now()
+
+	if err := bc.BoardUsecase.Create(c, &board); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+func (bc *BoardController) FetchAll(c *gin.Context) {
+	boards, err := bc.BoardUsecase.FetchAll(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, boards)
+}
+
+func (bc *BoardController) Fetch(c *gin.Context) {
+	board, err := bc.BoardUsecase.FetchById(c, c.Param("board_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+func (bc *BoardController) Update(c *gin.Context) {
+	var board domain.Board
+	if err := c.BindJSON(&board); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	board.UpdatedAt = time.Now()
+
+	if err := bc.BoardUsecase.UpdateById(c, c.Param("board_id"), &board); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Board updated successfully"})
+}
+
+func (bc *BoardController) Delete(c *gin.Context) {
+	if err := bc.BoardUsecase.DeleteById(c, c.Param("board_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Board deleted successfully"})
+}
+
+func (bc *BoardController) View(c *gin.Context) {
+	view, err := bc.BoardUsecase.View(c, c.Param("board_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	for _, column := range view.Columns {
+		localizeTasks(c, column.Tasks...)
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+func (bc *BoardController) Move(c *gin.Context) {
+	var move domain.TaskMove
+	if err := c.BindJSON(&move); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	task, err := bc.BoardUsecase.Move(c, c.Param("board_id"), c.GetString("user_id"), &move)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, domain.ErrWIPLimitReached):
+			status = http.StatusConflict
+		case errors.Is(err, domain.ErrMoveNotAllowed):
+			status = http.StatusForbidden
+		}
+		c.JSON(status, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	localizeTasks(c, task)
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,80 @@
+package Controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type CalendarController struct {
+	CalendarUsecase domain.CalendarUsecase
+}
+
+func (cc *CalendarController) FetchMine(c *gin.Context) {
+	cc.fetch(c, domain.CalendarOwnerUser, c.GetString("user_id"))
+}
+
+func (cc *CalendarController) UpdateMine(c *gin.Context) {
+	cc.update(c, domain.CalendarOwnerUser, c.GetString("user_id"))
+}
+
+func (cc *CalendarController) FetchProject(c *gin.Context) {
+	cc.fetch(c, domain.CalendarOwnerProject, c.Param("project_id"))
+}
+
+func (cc *CalendarController) UpdateProject(c *gin.Context) {
+	cc.update(c, domain.CalendarOwnerProject, c.Param("project_id"))
+}
+
+func (cc *CalendarController) DueIn(c *gin.Context) {
+	workingDays, err := strconv.Atoi(c.Query("working_days"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "working_days must be a number"})
+		return
+	}
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if from == nil {
+		now := time.Now()
+		from = &now
+	}
+
+	estimate, err := cc.CalendarUsecase.DueIn(c, c.GetString("user_id"), c.Query("project_id"), workingDays, *from)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, estimate)
+}
+
+func (cc *CalendarController) fetch(c *gin.Context, ownerType string, ownerID string) {
+	calendar, err := cc.CalendarUsecase.Fetch(c, ownerType, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, calendar)
+}
+
+func (cc *CalendarController) update(c *gin.Context, ownerType string, ownerID string) {
+	var calendar domain.WorkingCalendar
+	if err := c.BindJSON(&calendar); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	calendar.OwnerType = ownerType
+	calendar.OwnerID = ownerID
+	calendar.UpdatedAt = time.Now()
+
+	if err := cc.CalendarUsecase.Update(c, &calendar); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, calendar)
+}
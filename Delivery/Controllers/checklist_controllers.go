@@ -0,0 +1,74 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type ChecklistController struct {
+	ChecklistUsecase domain.ChecklistUsecase
+}
+
+func (cc *ChecklistController) AddItem(c *gin.Context) {
+	var request struct {
+		Text string `json:"text"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	item, err := cc.ChecklistUsecase.AddItem(c, c.Param("task_id"), c.GetString("user_id"), request.Text)
+	if err != nil {
+		c.JSON(checklistErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (cc *ChecklistController) UpdateItem(c *gin.Context) {
+	var patch domain.ChecklistItemPatch
+	if err := c.BindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := cc.ChecklistUsecase.UpdateItem(c, c.Param("task_id"), c.Param("item_id"), c.GetString("user_id"), &patch); err != nil {
+		c.JSON(checklistErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Checklist item updated successfully"})
+}
+
+func (cc *ChecklistController) MoveItem(c *gin.Context) {
+	var move domain.ChecklistItemMove
+	if err := c.BindJSON(&move); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := cc.ChecklistUsecase.MoveItem(c, c.Param("task_id"), c.Param("item_id"), c.GetString("user_id"), &move); err != nil {
+		c.JSON(checklistErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Checklist item moved successfully"})
+}
+
+func (cc *ChecklistController) RemoveItem(c *gin.Context) {
+	if err := cc.ChecklistUsecase.RemoveItem(c, c.Param("task_id"), c.Param("item_id"), c.GetString("user_id")); err != nil {
+		c.JSON(checklistErrorStatus(err, http.StatusInternalServerError), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Checklist item removed successfully"})
+}
+
+// checklistErrorStatus answers 403 to users who may not change the task.
+func checklistErrorStatus(err error, status int) int {
+	if errors.Is(err, domain.ErrTaskChangeNotAllowed) {
+		return http.StatusForbidden
+	}
+	return status
+}
@@ -0,0 +1,51 @@
+package Controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentController struct {
+	CommentUsecase domain.CommentUsecase
+}
+
+func (cc *CommentController) Create(c *gin.Context) {
+	var comment domain.Comment
+	if err := c.BindJSON(&comment); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	comment.ID = primitive.NewObjectID()
+	comment.CommentID = comment.ID.Hex()
+	comment.TaskID = c.Param("task_id")
+	comment.AuthorID = c.GetString("user_id")
+	comment.RuleID = ""
+	comment.CreatedAt = time.Now()
+
+	if err := cc.CommentUsecase.Create(c, &comment); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+func (cc *CommentController) FetchByTask(c *gin.Context) {
+	comments, err := cc.CommentUsecase.FetchByTask(c, c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+func (cc *CommentController) Delete(c *gin.Context) {
+	if err := cc.CommentUsecase.DeleteById(c, c.Param("comment_id"), c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Comment deleted successfully"})
+}
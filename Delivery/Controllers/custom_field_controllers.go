@@ -0,0 +1,93 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CustomFieldController struct {
+	CustomFieldUsecase domain.CustomFieldUsecase
+}
+
+func (cc *CustomFieldController) Create(c *gin.Context) {
+	var field domain.CustomField
+	if err := c.BindJSON(&field); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	field.ID = primitive.NewObjectID()
+	field.FieldID = field.ID.Hex()
+	field.CreatedBy = c.GetString("user_id")
+	field.CreatedAt = time.Now()
+	field.UpdatedAt = time.Now()
+
+	if err := cc.CustomFieldUsecase.Create(c, &field); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, field)
+}
+
+func (cc *CustomFieldController) FetchAll(c *gin.Context) {
+	fields, err := cc.CustomFieldUsecase.FetchAll(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, fields)
+}
+
+func (cc *CustomFieldController) Fetch(c *gin.Context) {
+	field, err := cc.CustomFieldUsecase.FetchById(c, c.Param("field_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, field)
+}
+
+func (cc *CustomFieldController) Update(c *gin.Context) {
+	var field domain.CustomField
+	if err := c.BindJSON(&field); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	field.UpdatedAt = time.Now()
+
+	if err := cc.CustomFieldUsecase.UpdateById(c, c.Param("field_id"), &field); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Custom field updated successfully"})
+}
+
+func (cc *CustomFieldController) Delete(c *gin.Context) {
+	if err := cc.CustomFieldUsecase.DeleteById(c, c.Param("field_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Custom field deleted successfully"})
+}
+
+func (cc *CustomFieldController) SetValues(c *gin.Context) {
+	var values map[string]interface{}
+	if err := c.BindJSON(&values); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := cc.CustomFieldUsecase.SetValues(c, c.Param("task_id"), c.GetString("user_id"), values); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrTaskChangeNotAllowed) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Custom fields updated successfully"})
+}
@@ -0,0 +1,37 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type EmailController struct {
+	EmailUsecase domain.EmailUsecase
+}
+
+// SendVerification mails a new verification token to the caller's email.
+func (ec *EmailController) SendVerification(c *gin.Context) {
+	if err := ec.EmailUsecase.SendVerification(c, c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "a verification token is on its way"})
+}
+
+// Verify takes the token from the email. Holding it proves the address, so
+// it needs no login.
+func (ec *EmailController) Verify(c *gin.Context) {
+	var request domain.VerifyEmailRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := ec.EmailUsecase.Verify(c, request.Token); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "email verified"})
+}
@@ -0,0 +1,19 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type JWKSController struct {
+	KeyRing domain.KeyRing
+}
+
+// JWKS publishes the public keys tokens can be verified with. Verifiers may
+// cache it briefly and should fetch it again when they meet an unknown kid.
+func (jc *JWKSController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jc.KeyRing.JWKS())
+}
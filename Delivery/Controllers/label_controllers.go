@@ -0,0 +1,132 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LabelController struct {
+	LabelUsecase domain.LabelUsecase
+}
+
+func (lc *LabelController) Create(c *gin.Context) {
+	var label domain.Label
+	if err := c.BindJSON(&label); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	label.ID = primitive.NewObjectID()
+	label.LabelID = label.ID.Hex()
+	label.CreatedBy = c.GetString("user_id")
+	label.CreatedAt = time.Now()
+	label.UpdatedAt = time.Now()
+
+	if err := lc.LabelUsecase.Create(c, &label); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, label)
+}
+
+func (lc *LabelController) FetchAll(c *gin.Context) {
+	labels, err := lc.LabelUsecase.FetchAll(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+func (lc *LabelController) Fetch(c *gin.Context) {
+	label, err := lc.LabelUsecase.FetchById(c, c.Param("label_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, label)
+}
+
+func (lc *LabelController) Update(c *gin.Context) {
+	var label domain.Label
+	if err := c.BindJSON(&label); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	label.UpdatedAt = time.Now()
+
+	if err := lc.LabelUsecase.UpdateById(c, c.Param("label_id"), &label); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Label updated successfully"})
+}
+
+func (lc *LabelController) Delete(c *gin.Context) {
+	if err := lc.LabelUsecase.DeleteById(c, c.Param("label_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Label deleted successfully"})
+}
+
+func (lc *LabelController) Usage(c *gin.Context) {
+	usage, err := lc.LabelUsecase.Usage(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, usage)
+}
+
+func (lc *LabelController) Merge(c *gin.Context) {
+	var request struct {
+		TargetID string `json:"target_id"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := lc.LabelUsecase.Merge(c, c.Param("label_id"), request.TargetID); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Labels merged successfully"})
+}
+
+func (lc *LabelController) Attach(c *gin.Context) {
+	var request struct {
+		Label string `json:"label"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := lc.LabelUsecase.Attach(c, c.Param("task_id"), c.GetString("user_id"), request.Label); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, domain.ErrTaskChangeNotAllowed) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Label attached successfully"})
+}
+
+func (lc *LabelController) Detach(c *gin.Context) {
+	if err := lc.LabelUsecase.Detach(c, c.Param("task_id"), c.GetString("user_id"), c.Param("label")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrTaskChangeNotAllowed) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Label detached successfully"})
+}
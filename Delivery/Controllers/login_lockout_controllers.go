@@ -0,0 +1,31 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type LoginLockoutController struct {
+	LoginThrottle domain.LoginThrottleUsecase
+}
+
+func (lc *LoginLockoutController) FetchLocked(c *gin.Context) {
+	locked, err := lc.LoginThrottle.Locked(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, locked)
+}
+
+// Unlock clears the failed logins of a username or an address, which also
+// ends a lockout.
+func (lc *LoginLockoutController) Unlock(c *gin.Context) {
+	if err := lc.LoginThrottle.Unlock(c, c.Param("scope"), c.Param("value"), c.GetString("user_id"), c.ClientIP()); err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "login unlocked"})
+}
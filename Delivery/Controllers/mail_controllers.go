@@ -0,0 +1,22 @@
+package Controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type MailController struct {
+	MailUsecase domain.MailUsecase
+}
+
+func (mc *MailController) PreviewDigest(c *gin.Context) {
+	message, err := mc.MailUsecase.PreviewDigest(c, c.GetString("user_id"), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, message)
+}
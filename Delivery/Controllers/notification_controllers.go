@@ -0,0 +1,95 @@
+package Controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type NotificationController struct {
+	NotificationUsecase domain.NotificationUsecase
+}
+
+func (nc *NotificationController) FetchAll(c *gin.Context) {
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	notifications, err := nc.NotificationUsecase.FetchPage(c, c.GetString("user_id"), c.Query("unread") == "true", page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (nc *NotificationController) UnreadCount(c *gin.Context) {
+	count, err := nc.NotificationUsecase.CountUnread(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+func (nc *NotificationController) MarkRead(c *gin.Context) {
+	if err := nc.NotificationUsecase.MarkRead(c, c.GetString("user_id"), c.Param("notification_id")); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Notification marked as read"})
+}
+
+func (nc *NotificationController) MarkAllRead(c *gin.Context) {
+	count, err := nc.NotificationUsecase.MarkAllRead(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"marked_read": count})
+}
+
+func (nc *NotificationController) FetchPreferences(c *gin.Context) {
+	preferences, err := nc.NotificationUsecase.FetchPreferences(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (nc *NotificationController) UpdatePreferences(c *gin.Context) {
+	var preferences domain.NotificationPreferences
+	if err := c.BindJSON(&preferences); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	preferences.UserID = c.GetString("user_id")
+	preferences.UpdatedAt = time.Now()
+
+	if err := nc.NotificationUsecase.UpdatePreferences(c, &preferences); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (nc *NotificationController) Watch(c *gin.Context) {
+	if err := nc.NotificationUsecase.Watch(c, c.Param("task_id"), c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Watching task"})
+}
+
+func (nc *NotificationController) Unwatch(c *gin.Context) {
+	if err := nc.NotificationUsecase.Unwatch(c, c.Param("task_id"), c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Stopped watching task"})
+}
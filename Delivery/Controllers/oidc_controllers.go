@@ -0,0 +1,65 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type OIDCController struct {
+	OIDCUsecase      domain.OIDCUsecase
+	TokenUsecase     domain.TokenUsecase
+	TwoFactorUsecase domain.TwoFactorUsecase
+	UserUsecase      domain.UserUsecase
+}
+
+func (oc *OIDCController) Providers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": oc.OIDCUsecase.Providers()})
+}
+
+// Login sends the browser to the identity provider.
+func (oc *OIDCController) Login(c *gin.Context) {
+	authorizationURL, err := oc.OIDCUsecase.Begin(c, c.Param("provider"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.Redirect(http.StatusFound, authorizationURL)
+}
+
+// Callback is where the identity provider sends the browser back. It answers
+// like the password login, including the 2FA challenge.
+func (oc *OIDCController) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: providerError + ": " + c.Query("error_description")})
+		return
+	}
+	if c.Query("code") == "" || c.Query("state") == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "code and state are required"})
+		return
+	}
+
+	user, err := oc.OIDCUsecase.Complete(c, c.Param("provider"), c.Query("state"), c.Query("code"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	// the provider stands in for the password, the second factor is still ours
+	challenge, err := oc.TwoFactorUsecase.Challenge(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+	tokens, err := oc.TokenUsecase.Issue(c, user, sessionClient(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	oc.UserUsecase.UpdateAllToken(c, tokens.Token, tokens.RefreshToken, user.UserID)
+	c.JSON(http.StatusOK, loginResponse(user, tokens))
+}
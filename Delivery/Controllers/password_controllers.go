@@ -0,0 +1,56 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type PasswordController struct {
+	PasswordUsecase domain.PasswordUsecase
+}
+
+// Change sets a new password for the caller and ends all their sessions.
+func (pc *PasswordController) Change(c *gin.Context) {
+	var request domain.ChangePasswordRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := pc.PasswordUsecase.Change(c, c.GetString("user_id"), &request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "password changed, please log in again"})
+}
+
+// Forgot answers the same whether or not the username exists.
+func (pc *PasswordController) Forgot(c *gin.Context) {
+	var request domain.ForgotPasswordRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := pc.PasswordUsecase.Forgot(c, request.Username); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "if the account exists, a reset token is on its way"})
+}
+
+func (pc *PasswordController) Reset(c *gin.Context) {
+	var request domain.ResetPasswordRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := pc.PasswordUsecase.Reset(c, &request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "password reset, please log in again"})
+}
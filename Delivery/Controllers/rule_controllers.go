@@ -0,0 +1,107 @@
+package Controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RuleController struct {
+	RuleUsecase domain.RuleUsecase
+}
+
+func (rc *RuleController) Create(c *gin.Context) {
+	var rule domain.Rule
+	if err := c.BindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	rule.ID = primitive.NewObjectID()
+	rule.RuleID = rule.ID.Hex()
+	rule.CreatedBy = c.GetString("user_id")
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	if err := rc.RuleUsecase.Create(c, &rule); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (rc *RuleController) FetchAll(c *gin.Context) {
+	rules, err := rc.RuleUsecase.FetchAll(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+func (rc *RuleController) Fetch(c *gin.Context) {
+	rule, err := rc.RuleUsecase.FetchById(c, c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+func (rc *RuleController) Update(c *gin.Context) {
+	var rule domain.Rule
+	if err := c.BindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	rule.UpdatedAt = time.Now()
+
+	if err := rc.RuleUsecase.UpdateById(c, c.Param("rule_id"), &rule); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Rule updated successfully"})
+}
+
+func (rc *RuleController) Delete(c *gin.Context) {
+	if err := rc.RuleUsecase.DeleteById(c, c.Param("rule_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Rule deleted successfully"})
+}
+
+func (rc *RuleController) DryRun(c *gin.Context) {
+	var request struct {
+		TaskID string `json:"task_id"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	execution, err := rc.RuleUsecase.DryRun(c, c.Param("rule_id"), request.TaskID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, execution)
+}
+
+func (rc *RuleController) Executions(c *gin.Context) {
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "limit must be a number"})
+		return
+	}
+
+	executions, err := rc.RuleUsecase.Executions(c, c.Param("rule_id"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, executions)
+}
@@ -0,0 +1,90 @@
+package Controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SavedViewController struct {
+	SavedViewUsecase domain.SavedViewUsecase
+}
+
+func (sc *SavedViewController) Create(c *gin.Context) {
+	var view domain.SavedView
+	if err := c.BindJSON(&view); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	view.ID = primitive.NewObjectID()
+	view.ViewID = view.ID.Hex()
+	view.OwnerID = c.GetString("user_id")
+	view.CreatedAt = time.Now()
+	view.UpdatedAt = time.Now()
+
+	if err := sc.SavedViewUsecase.Create(c, &view); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+func (sc *SavedViewController) FetchAll(c *gin.Context) {
+	views, err := sc.SavedViewUsecase.FetchVisible(c, c.GetString("user_id"), c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, views)
+}
+
+func (sc *SavedViewController) Fetch(c *gin.Context) {
+	view, err := sc.SavedViewUsecase.FetchById(c, c.Param("view_id"), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+func (sc *SavedViewController) Update(c *gin.Context) {
+	var view domain.SavedView
+	if err := c.BindJSON(&view); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	view.UpdatedAt = time.Now()
+
+	if err := sc.SavedViewUsecase.UpdateById(c, c.Param("view_id"), c.GetString("user_id"), &view); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "View updated successfully"})
+}
+
+func (sc *SavedViewController) Delete(c *gin.Context) {
+	if err := sc.SavedViewUsecase.DeleteById(c, c.Param("view_id"), c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "View deleted successfully"})
+}
+
+func (sc *SavedViewController) Execute(c *gin.Context) {
+	page, limit, err := parsePagination(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	result, err := sc.SavedViewUsecase.Execute(c, c.Param("view_id"), c.GetString("user_id"), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	localizeTasks(c, result.Tasks...)
+	c.JSON(http.StatusOK, result)
+}
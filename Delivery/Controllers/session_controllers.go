@@ -0,0 +1,34 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type SessionController struct {
+	SessionUsecase domain.SessionUsecase
+}
+
+func (sc *SessionController) FetchMine(c *gin.Context) {
+	currentID := ""
+	if value, ok := c.Get("claims"); ok {
+		currentID = value.(*domain.SignedDetails).Family
+	}
+
+	sessions, err := sc.SessionUsecase.FetchActive(c, c.GetString("user_id"), currentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (sc *SessionController) Revoke(c *gin.Context) {
+	if err := sc.SessionUsecase.Revoke(c, c.GetString("user_id"), c.Param("session_id")); err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "session revoked"})
+}
@@ -0,0 +1,150 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SprintController struct {
+	SprintUsecase domain.SprintUsecase
+}
+
+func (sc *SprintController) Create(c *gin.Context) {
+	var sprint domain.Sprint
+	if err := c.BindJSON(&sprint); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	sprint.ID = primitive.NewObjectID()
+	sprint.SprintID = sprint.ID.Hex()
+	sprint.CreatedBy = c.GetString("user_id")
+	sprint.CreatedAt = time.Now()
+	sprint.UpdatedAt = time.Now()
+
+	if err := sc.SprintUsecase.Create(c, &sprint); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (sc *SprintController) FetchAll(c *gin.Context) {
+	sprints, err := sc.SprintUsecase.FetchAll(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sprints)
+}
+
+func (sc *SprintController) Fetch(c *gin.Context) {
+	sprint, err := sc.SprintUsecase.FetchById(c, c.Param("sprint_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (sc *SprintController) Update(c *gin.Context) {
+	var sprint domain.Sprint
+	if err := c.BindJSON(&sprint); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	sprint.UpdatedAt = time.Now()
+
+	if err := sc.SprintUsecase.UpdateById(c, c.Param("sprint_id"), c.GetString("user_id"), c.GetString("user_type"), &sprint); err != nil {
+		c.JSON(sprintErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Sprint updated successfully"})
+}
+
+func (sc *SprintController) Delete(c *gin.Context) {
+	if err := sc.SprintUsecase.DeleteById(c, c.Param("sprint_id"), c.GetString("user_id"), c.GetString("user_type")); err != nil {
+		c.JSON(sprintErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Sprint deleted successfully"})
+}
+
+func (sc *SprintController) Plan(c *gin.Context) {
+	var request struct {
+		TaskIDs []string `json:"task_ids"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := sc.SprintUsecase.Plan(c, c.Param("sprint_id"), c.GetString("user_id"), c.GetString("user_type"), request.TaskIDs); err != nil {
+		c.JSON(sprintErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Tasks planned successfully"})
+}
+
+func (sc *SprintController) Unplan(c *gin.Context) {
+	if err := sc.SprintUsecase.Unplan(c, c.Param("sprint_id"), c.GetString("user_id"), c.GetString("user_type"), c.Param("task_id")); err != nil {
+		c.JSON(sprintErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Task removed from sprint successfully"})
+}
+
+func (sc *SprintController) Start(c *gin.Context) {
+	sprint, err := sc.SprintUsecase.Start(c, c.Param("sprint_id"), c.GetString("user_id"), c.GetString("user_type"))
+	if err != nil {
+		c.JSON(sprintErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (sc *SprintController) Close(c *gin.Context) {
+	var request struct {
+		CarryOverTo string `json:"carry_over_to"`
+	}
+	// the body is optional; without it unfinished tasks return to the backlog
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	sprint, err := sc.SprintUsecase.Close(c, c.Param("sprint_id"), c.GetString("user_id"), c.GetString("user_type"), request.CarryOverTo)
+	if err != nil {
+		c.JSON(sprintErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sprint)
+}
+
+func (sc *SprintController) Burndown(c *gin.Context) {
+	burndown, err := sc.SprintUsecase.Burndown(c, c.Param("sprint_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	// the days stay the sprint's calendar days, only the timestamps move
+	value, _ := c.Get("location")
+	if location, ok := value.(*time.Location); ok {
+		burndown.Sprint.In(location)
+	}
+	c.JSON(http.StatusOK, burndown)
+}
+
+// sprintErrorStatus answers 403 to users who may not change the sprint.
+func sprintErrorStatus(err error, status int) int {
+	if errors.Is(err, domain.ErrSprintChangeNotAllowed) {
+		return http.StatusForbidden
+	}
+	return status
+}
@@ -0,0 +1,103 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type StatsController struct {
+	StatsUsecase domain.StatsUsecase
+}
+
+func (sc *StatsController) Summary(c *gin.Context) {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	summary, err := sc.StatsUsecase.Summary(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// CountBy returns a handler counting tasks per status, assignee or project.
+func (sc *StatsController) CountBy(grouping string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := bindStatsFilter(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+
+		buckets, err := sc.StatsUsecase.CountBy(c, filter, grouping)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, buckets)
+	}
+}
+
+func (sc *StatsController) Overdue(c *gin.Context) {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	overdue, err := sc.StatsUsecase.Overdue(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, overdue)
+}
+
+func (sc *StatsController) Throughput(c *gin.Context) {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	throughput, err := sc.StatsUsecase.Throughput(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, throughput)
+}
+
+func (sc *StatsController) Flow(c *gin.Context) {
+	filter, err := bindStatsFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	flow, err := sc.StatsUsecase.Flow(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, flow)
+}
+
+func bindStatsFilter(c *gin.Context) (*domain.StatsFilter, error) {
+	filter := &domain.StatsFilter{ProjectID: c.Query("project_id")}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
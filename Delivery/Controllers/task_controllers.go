@@ -1,8 +1,13 @@
 package Controllers
 
 import (
+	"encoding/csv"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,6 +30,13 @@ func (tc *TaskController) Create(c *gin.Context) {
 	}
 	task.ID = primitive.NewObjectID()
 	task.TaskID = task.ID.Hex()
+	// labels, board ranks, sprints, checklists, custom fields and watchers have their own endpoints so they can be validated
+	task.Labels = nil
+	task.Rank = ""
+	task.SprintID = ""
+	task.Checklist = nil
+	task.CustomFields = nil
+	task.Watchers = nil
 
 	task.CreatedAt = time.Now()
 	task.UpdatedAt = time.Now()
@@ -45,15 +57,200 @@ func (tc *TaskController) Create(c *gin.Context) {
 }
 
 func (tc *TaskController) FetchAll(c *gin.Context) {
+	query, err := bindTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	page, err := tc.TaskUsecase.FetchPage(c, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	localizeTasks(c, page.Tasks...)
+	c.JSON(http.StatusOK, page)
+}
+
+// Export writes the tasks matching the listing filters as CSV, with one
+// column per custom field found on them.
+func (tc *TaskController) Export(c *gin.Context) {
+	query, err := bindTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	tasks, err := tc.TaskUsecase.Export(c, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	localizeTasks(c, tasks...)
+
+	keySet := map[string]bool{}
+	for _, task := range tasks {
+		for key := range task.CustomFields {
+			keySet[key] = true
+		}
+	}
+	keys := make([]string, 0, len(keySet))
+	for key := range keySet {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=tasks.csv")
+	writer := csv.NewWriter(c.Writer)
+	header := []string{"task_id", "title", "status", "priority", "project_id", "assignee_id", "labels",
+		"story_points", "estimate_minutes", "start_date", "due_date", "due_day", "completed_at", "created_by", "created_at"}
+	for _, key := range keys {
+		header = append(header, domain.CustomFieldSortPrefix+key)
+	}
+	writer.Write(header)
+	for _, task := range tasks {
+		completedAt := ""
+		if task.CompletedAt != nil {
+			completedAt = task.CompletedAt.Format(time.RFC3339)
+		}
+		row := []string{task.TaskID, task.Title, task.Status, task.Priority, task.ProjectID, task.AssigneeID,
+			strings.Join(task.Labels, ";"),
+			strconv.FormatFloat(task.StoryPoints, 'f', -1, 64),
+			strconv.Itoa(task.EstimateMinutes),
+			task.StartDate.Format(time.RFC3339), task.DueDate.Format(time.RFC3339), task.DueDay, completedAt,
+			task.CreatedBy, task.CreatedAt.Format(time.RFC3339)}
+		for _, key := range keys {
+			row = append(row, formatCustomFieldValue(task.CustomFields[key]))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+}
+
+func formatCustomFieldValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (tc *TaskController) Next(c *gin.Context) {
+	userID := c.DefaultQuery("user_id", c.GetString("user_id"))
+	// only admins may look at someone else's queue
+	if userID != c.GetString("user_id") && c.GetString("user_type") != "ADMIN" {
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "you can only see your own queue"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "limit must be a number"})
+		return
+	}
 
-	tasks, err := tc.TaskUsecase.FetchAll(c)
+	tasks, err := tc.TaskUsecase.Next(c, userID, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
+	for _, scored := range tasks {
+		localizeTasks(c, scored.Task)
+	}
 	c.JSON(http.StatusOK, tasks)
 }
 
+// bindTaskQuery reads the filter, sort and pagination parameters of a task listing.
+func bindTaskQuery(c *gin.Context) (*domain.TaskQuery, error) {
+	query := &domain.TaskQuery{
+		Filter: domain.TaskFilter{
+			ProjectID:  c.Query("project_id"),
+			CreatedBy:  c.Query("created_by"),
+			AssigneeID: c.Query("assignee_id"),
+			SprintID:   c.Query("sprint_id"),
+			ParentID:   c.Query("parent_id"),
+			Search:     c.Query("search"),
+		},
+		Sort: domain.TaskSort{
+			Field: c.Query("sort"),
+			Order: c.Query("order"),
+		},
+	}
+	if status := c.Query("status"); status != "" {
+		query.Filter.Statuses = strings.Split(status, ",")
+	}
+	if priority := c.Query("priority"); priority != "" {
+		query.Filter.Priorities = strings.Split(priority, ",")
+	}
+	if labels := c.Query("labels"); labels != "" {
+		query.Filter.Labels = strings.Split(labels, ",")
+		query.Filter.LabelMatch = c.DefaultQuery("label_match", domain.LabelMatchAny)
+	}
+
+	conditions, err := parseCustomFieldConditions(c)
+	if err != nil {
+		return nil, err
+	}
+	query.Filter.CustomFields = conditions
+
+	if query.Filter.DueFrom, err = parseTimeQuery(c, "due_from"); err != nil {
+		return nil, err
+	}
+	if query.Filter.DueTo, err = parseTimeQuery(c, "due_to"); err != nil {
+		return nil, err
+	}
+	if query.Page, query.Limit, err = parsePagination(c); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+func parsePagination(c *gin.Context) (page, limit int64, err error) {
+	if value := c.Query("page"); value != "" {
+		if page, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("page must be a number")
+		}
+	}
+	if value := c.Query("limit"); value != "" {
+		if limit, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("limit must be a number")
+		}
+	}
+	return page, limit, nil
+}
+
+// localizeTasks renders the tasks' dates in the caller's time zone, see
+// Intrastructures.CallerLocation.
+func localizeTasks(c *gin.Context, tasks ...*domain.Task) {
+	value, _ := c.Get("location")
+	location, ok := value.(*time.Location)
+	if !ok {
+		return
+	}
+	for _, task := range tasks {
+		task.In(location)
+	}
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", key)
+	}
+	return &parsed, nil
+}
+
 func (tc *TaskController) Fetch(c *gin.Context) {
 	taskID := c.Param("task_id")
 
@@ -63,6 +260,7 @@ func (tc *TaskController) Fetch(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
+	localizeTasks(c, task)
 	c.JSON(http.StatusOK, task)
 }
 
@@ -70,13 +268,26 @@ func (tc *TaskController) Update(c *gin.Context) {
 	taskID := c.Param("task_id")
 	userID := c.GetString("user_id")
 
-	var task domain.Task
+	stored, err := tc.TaskUsecase.FetchById(c, taskID)
+	if err != nil || stored == nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: fmt.Sprintf("no task found with id '%s'", taskID)})
+		return
+	}
 
+	// the body is read over the stored task, so fields it leaves out keep
+	// their values
+	task := *stored
 	if err := c.BindJSON(&task); err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
+	// a new due date replaces the stored due day it was computed from
+	if !task.DueDate.Equal(stored.DueDate) && task.DueDay == stored.DueDay {
+		task.DueDay = ""
+	}
 
+	// the project is fixed when the task is created
+	task.ProjectID = stored.ProjectID
 	task.UpdatedBy = userID
 	task.UpdatedAt = time.Now()
 
@@ -96,3 +307,30 @@ func (tc *TaskController) Delete(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Task Deleted successfully"})
 }
+
+// parseCustomFieldConditions reads custom field filters such as
+// cf.severity=high or cf.amount.gte=100 from the query string.
+func parseCustomFieldConditions(c *gin.Context) ([]domain.CustomFieldCondition, error) {
+	var conditions []domain.CustomFieldCondition
+	for param, values := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(param, domain.CustomFieldSortPrefix)
+		if !ok {
+			continue
+		}
+		key, op, found := strings.Cut(name, ".")
+		if !found {
+			op = domain.CustomFieldEq
+		}
+		if !domain.CustomFieldKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("'%s' is not a valid custom field filter", param)
+		}
+		conditions = append(conditions, domain.CustomFieldCondition{Key: key, Op: op, Value: values[0]})
+	}
+	sort.Slice(conditions, func(i, j int) bool {
+		if conditions[i].Key != conditions[j].Key {
+			return conditions[i].Key < conditions[j].Key
+		}
+		return conditions[i].Op < conditions[j].Op
+	})
+	return conditions, nil
+}
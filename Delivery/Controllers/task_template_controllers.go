@@ -0,0 +1,99 @@
+package Controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TaskTemplateController struct {
+	TaskTemplateUsecase domain.TaskTemplateUsecase
+}
+
+func (tc *TaskTemplateController) Create(c *gin.Context) {
+	var template domain.TaskTemplate
+	if err := c.BindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	template.ID = primitive.NewObjectID()
+	template.TemplateID = template.ID.Hex()
+	template.CreatedBy = c.GetString("user_id")
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = time.Now()
+
+	if err := tc.TaskTemplateUsecase.Create(c, &template); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (tc *TaskTemplateController) FetchAll(c *gin.Context) {
+	templates, err := tc.TaskTemplateUsecase.FetchAll(c, c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+func (tc *TaskTemplateController) Fetch(c *gin.Context) {
+	template, err := tc.TaskTemplateUsecase.FetchById(c, c.Param("template_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (tc *TaskTemplateController) Update(c *gin.Context) {
+	var template domain.TaskTemplate
+	if err := c.BindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	template.UpdatedAt = time.Now()
+
+	if err := tc.TaskTemplateUsecase.UpdateById(c, c.Param("template_id"), c.GetString("user_id"), c.GetString("user_type"), &template); err != nil {
+		c.JSON(templateErrorStatus(err, http.StatusBadRequest), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Template updated successfully"})
+}
+
+func (tc *TaskTemplateController) Delete(c *gin.Context) {
+	if err := tc.TaskTemplateUsecase.DeleteById(c, c.Param("template_id"), c.GetString("user_id"), c.GetString("user_type")); err != nil {
+		c.JSON(templateErrorStatus(err, http.StatusInternalServerError), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Template deleted successfully"})
+}
+
+func (tc *TaskTemplateController) Instantiate(c *gin.Context) {
+	var request domain.TemplateInstantiation
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	tasks, err := tc.TaskTemplateUsecase.Instantiate(c, c.Param("template_id"), c.GetString("user_id"), &request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	localizeTasks(c, tasks...)
+	c.JSON(http.StatusOK, tasks)
+}
+
+// templateErrorStatus answers 403 to users who may not change the template.
+func templateErrorStatus(err error, status int) int {
+	if errors.Is(err, domain.ErrTemplateChangeNotAllowed) {
+		return http.StatusForbidden
+	}
+	return status
+}
@@ -0,0 +1,142 @@
+package Controllers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TimeEntryController struct {
+	TimeEntryUsecase domain.TimeEntryUsecase
+}
+
+func (tc *TimeEntryController) StartTimer(c *gin.Context) {
+	var request struct {
+		Note string `json:"note"`
+	}
+	// the body is optional when starting a timer
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+
+	entry, err := tc.TimeEntryUsecase.StartTimer(c, c.Param("task_id"), c.GetString("user_id"), request.Note)
+	if err != nil {
+		c.JSON(http.StatusConflict, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+func (tc *TimeEntryController) StopTimer(c *gin.Context) {
+	entry, err := tc.TimeEntryUsecase.StopTimer(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+func (tc *TimeEntryController) FetchRunning(c *gin.Context) {
+	entry, err := tc.TimeEntryUsecase.FetchRunning(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+func (tc *TimeEntryController) Create(c *gin.Context) {
+	var entry domain.TimeEntry
+	if err := c.BindJSON(&entry); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	entry.ID = primitive.NewObjectID()
+	entry.EntryID = entry.ID.Hex()
+	entry.UserID = c.GetString("user_id")
+	entry.CreatedAt = time.Now()
+
+	if err := tc.TimeEntryUsecase.CreateManual(c, &entry); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+func (tc *TimeEntryController) FetchAll(c *gin.Context) {
+	filter, err := bindTimeEntryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if filter.UserID == "" && filter.TaskID == "" {
+		filter.UserID = c.GetString("user_id")
+	}
+
+	entries, err := tc.TimeEntryUsecase.Fetch(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+func (tc *TimeEntryController) Delete(c *gin.Context) {
+	if err := tc.TimeEntryUsecase.DeleteById(c, c.Param("entry_id"), c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Time entry deleted successfully"})
+}
+
+func (tc *TimeEntryController) Timesheet(c *gin.Context) {
+	filter, err := bindTimeEntryFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	timesheet, err := tc.TimeEntryUsecase.Timesheet(c, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if c.Query("format") != "csv" {
+		c.JSON(http.StatusOK, timesheet)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=timesheet.csv")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write([]string{"day", "user_id", "task_id", "hours"})
+	for _, row := range timesheet.Rows {
+		writer.Write([]string{row.Day, row.UserID, row.TaskID, strconv.FormatFloat(row.Hours, 'f', 2, 64)})
+	}
+	writer.Write([]string{"total", "", "", strconv.FormatFloat(timesheet.TotalHours, 'f', 2, 64)})
+	writer.Flush()
+}
+
+func bindTimeEntryFilter(c *gin.Context) (*domain.TimeEntryFilter, error) {
+	filter := &domain.TimeEntryFilter{
+		UserID: c.Query("user_id"),
+		TaskID: c.Query("task_id"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
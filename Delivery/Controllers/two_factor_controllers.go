@@ -0,0 +1,192 @@
+package Controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type TwoFactorController struct {
+	TwoFactorUsecase domain.TwoFactorUsecase
+	TokenUsecase     domain.TokenUsecase
+	UserUsecase      domain.UserUsecase
+}
+
+// VerifyLogin is the second step of a login with 2FA.
+func (tc *TwoFactorController) VerifyLogin(c *gin.Context) {
+	var request domain.TwoFactorLoginRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if request.ChallengeID == "" || (request.Code == "" && request.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "challenge_id and a code or recovery_code are required"})
+		return
+	}
+
+	userID, err := tc.TwoFactorUsecase.Verify(c, &request)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	response, err := tc.login(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// BeginSetup starts the enrollment of a user who has to set up 2FA before
+// logging in.
+func (tc *TwoFactorController) BeginSetup(c *gin.Context) {
+	var request domain.TwoFactorLoginRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	enrollment, err := tc.TwoFactorUsecase.BeginSetup(c, request.ChallengeID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmSetup turns 2FA on and finishes the login. The response carries
+// the recovery codes besides the tokens.
+func (tc *TwoFactorController) ConfirmSetup(c *gin.Context) {
+	var request domain.TwoFactorLoginRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if request.ChallengeID == "" || request.Code == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "challenge_id and code are required"})
+		return
+	}
+
+	userID, codes, err := tc.TwoFactorUsecase.ConfirmSetup(c, request.ChallengeID, request.Code)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	response, err := tc.login(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	response["recovery_codes"] = codes.RecoveryCodes
+	c.JSON(http.StatusOK, response)
+}
+
+func (tc *TwoFactorController) Status(c *gin.Context) {
+	status, err := tc.TwoFactorUsecase.Status(c, c.GetString("user_id"), c.GetString("user_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+func (tc *TwoFactorController) Begin(c *gin.Context) {
+	enrollment, err := tc.TwoFactorUsecase.Begin(c, c.GetString("user_id"), c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+func (tc *TwoFactorController) Confirm(c *gin.Context) {
+	var request domain.TwoFactorCodeRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	codes, err := tc.TwoFactorUsecase.Confirm(c, c.GetString("user_id"), request.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
+
+func (tc *TwoFactorController) RegenerateRecoveryCodes(c *gin.Context) {
+	var request domain.TwoFactorCodeRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	codes, err := tc.TwoFactorUsecase.RegenerateRecoveryCodes(c, c.GetString("user_id"), request.Code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, codes)
+}
+
+func (tc *TwoFactorController) Disable(c *gin.Context) {
+	var request domain.TwoFactorCodeRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	if err := tc.TwoFactorUsecase.Disable(c, c.GetString("user_id"), c.GetString("user_type"), request.Code); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "two-factor authentication disabled"})
+}
+
+func (tc *TwoFactorController) Policies(c *gin.Context) {
+	policies, err := tc.TwoFactorUsecase.Policies(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+func (tc *TwoFactorController) SetPolicy(c *gin.Context) {
+	var request domain.TwoFactorPolicyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	policy, err := tc.TwoFactorUsecase.SetPolicy(c, c.Param("user_type"), request.Required, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// Reset removes a user's 2FA, for users who lost their device and their
+// recovery codes.
+func (tc *TwoFactorController) Reset(c *gin.Context) {
+	if err := tc.TwoFactorUsecase.Reset(c, c.Param("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "two-factor authentication reset"})
+}
+
+func (tc *TwoFactorController) login(c *gin.Context, userID string) (gin.H, error) {
+	user, err := tc.UserUsecase.FetchById(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tc.TokenUsecase.Issue(c, user, sessionClient(c))
+	if err != nil {
+		return nil, err
+	}
+	tc.UserUsecase.UpdateAllToken(c, tokens.Token, tokens.RefreshToken, user.UserID)
+	return loginResponse(user, tokens), nil
+}
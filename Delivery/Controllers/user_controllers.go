@@ -1,8 +1,13 @@
 package Controllers
 
 import (
+	"fmt"
+	"log"
+	"math"
 	"net/http"
+	"net/mail"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,9 +16,13 @@ import (
 )
 
 type UserController struct {
-	UserUsecase domain.UserUsecase
-	Password    domain.PasswordServiceProvider
-	UserToken   domain.IUserToken
+	UserUsecase      domain.UserUsecase
+	TokenUsecase     domain.TokenUsecase
+	TwoFactorUsecase domain.TwoFactorUsecase
+	EmailUsecase     domain.EmailUsecase
+	LoginThrottle    domain.LoginThrottleUsecase
+	Password         domain.PasswordServiceProvider
+	UserToken        domain.IUserToken
 }
 
 func (uc *UserController) Register(c *gin.Context) {
@@ -35,15 +44,12 @@ func (uc *UserController) Register(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: "username should start with alphabet and only contain alpha numeric and underscore"})
 		return
 	}
-
-	token, refreshToken, err := uc.UserToken.GenerateAllTokens(user.Username, user.UserType, user.UserID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	if !validEmail(user.Email) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "email is not a valid address"})
 		return
 	}
-	user.Token = token
-	user.RefreshToken = refreshToken
 
+	user.EmailVerified = false
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
@@ -51,6 +57,11 @@ func (uc *UserController) Register(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
+	if user.Email != "" {
+		if err := uc.EmailUsecase.SendVerification(c, user.UserID); err != nil {
+			log.Println("error sending email verification:", err)
+		}
+	}
 	c.JSON(http.StatusOK, domain.ErrorResponse{Message: "user created successfully"})
 }
 
@@ -61,33 +72,114 @@ func (uc *UserController) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
+	block, err := uc.LoginThrottle.Check(c, user.Username, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if block != nil {
+		abortLoginBlocked(c, block)
+		return
+	}
 	foundUser, err := uc.UserUsecase.GetUserByUsername(c, user.Username)
 	if err != nil {
+		uc.loginFailed(c, user.Username)
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 	passwordIsValid, msg := uc.Password.VerifyPassword(foundUser.Password, user.Password)
 	if !passwordIsValid {
+		uc.loginFailed(c, user.Username)
 		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: msg})
 		return
 	}
-	token, refreshToken, err := uc.UserToken.GenerateAllTokens(foundUser.Username, foundUser.UserType, foundUser.UserID)
+	if err := uc.LoginThrottle.Succeed(c, user.Username, c.ClientIP()); err != nil {
+		log.Println("error clearing failed logins:", err)
+	}
+	// with 2FA the password only earns a challenge for the second step
+	challenge, err := uc.TwoFactorUsecase.Challenge(c, foundUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+	tokens, err := uc.TokenUsecase.Issue(c, foundUser, sessionClient(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	uc.UserUsecase.UpdateAllToken(c, tokens.Token, tokens.RefreshToken, foundUser.UserID)
+	c.JSON(http.StatusOK, loginResponse(foundUser, tokens))
+}
+
+// loginFailed counts a wrong username or password. The wait it causes is
+// enforced on the next attempt, so the response stays the same.
+func (uc *UserController) loginFailed(c *gin.Context, username string) {
+	if _, err := uc.LoginThrottle.Fail(c, username, c.ClientIP()); err != nil {
+		log.Println("error recording failed login:", err)
+	}
+}
+
+// abortLoginBlocked answers a login that comes before its wait is over.
+func abortLoginBlocked(c *gin.Context, block *domain.LoginBlock) {
+	seconds := int(math.Ceil(block.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	message := fmt.Sprintf("too many failed logins, try again in %d seconds", seconds)
+	if block.Locked {
+		locked := "account"
+		if block.Scope == domain.LoginScopeIP {
+			locked = "address"
+		}
+		message = fmt.Sprintf("too many failed logins, this %s is locked for %d seconds", locked, seconds)
+	}
+	c.JSON(http.StatusTooManyRequests, domain.ErrorResponse{Message: message})
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+func (uc *UserController) Refresh(c *gin.Context) {
+	var request domain.RefreshRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if request.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "refresh_token is required"})
+		return
+	}
 
+	tokens, err := uc.TokenUsecase.Refresh(c, request.RefreshToken, sessionClient(c))
 	if err != nil {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the token of the request and the refresh tokens of its login.
+func (uc *UserController) Logout(c *gin.Context) {
+	value, _ := c.Get("claims")
+	claims, ok := value.(*domain.SignedDetails)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "no token to log out"})
+		return
+	}
+	if err := uc.TokenUsecase.Logout(c, claims); err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
 	}
-	uc.UserUsecase.UpdateAllToken(c, token, refreshToken, foundUser.UserID)
-	foundUser.Token = token
-	foundUser.RefreshToken = refreshToken
-	c.JSON(http.StatusOK, gin.H{
-		"user_id":    foundUser.UserID,
-		"username":   foundUser.Username,
-		"first_name": foundUser.FirstName,
-		"last_name":  foundUser.LastName,
-		"token":      foundUser.Token,
-		"user_type":  foundUser.UserType,
-	})
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "logged out"})
+}
 
+// LogoutAll revokes every token of the user, on all devices.
+func (uc *UserController) LogoutAll(c *gin.Context) {
+	if err := uc.TokenUsecase.LogoutAll(c, c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "logged out of all sessions"})
 }
 
 func (uc *UserController) FetchAll(c *gin.Context) {
@@ -112,13 +204,28 @@ func (uc *UserController) Fetch(c *gin.Context) {
 
 func (uc *UserController) Update(c *gin.Context) {
 	userID := c.GetString("user_id")
-	var user domain.User
+	var request domain.UpdateUserRequest
 
-	if err := c.BindJSON(&user); err != nil {
+	if err := c.BindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
-	if err := uc.UserUsecase.UpdateById(c, userID, &user); err != nil {
+	if request.Password != "" {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "change the password through /api/users/me/password"})
+		return
+	}
+	if request.Email != nil && !validEmail(*request.Email) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "email is not a valid address"})
+		return
+	}
+	// the email goes first, so a wrong password changes nothing
+	if request.Email != nil {
+		if err := uc.EmailUsecase.Change(c, userID, *request.Email, request.CurrentPassword); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+	if err := uc.UserUsecase.UpdateById(c, userID, &request); err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -130,7 +237,39 @@ func (uc *UserController) Delete(c *gin.Context) {
 
 	if err := uc.UserUsecase.DeleteById(c, userID); err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	// tokens of a deleted account must stop working before they expire
+	if err := uc.TokenUsecase.LogoutAll(c, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
 	}
 
 	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "user deleted successfully"})
 }
+
+// loginResponse is what every way of logging in answers with.
+func loginResponse(user *domain.User, tokens *domain.TokenPair) gin.H {
+	return gin.H{
+		"user_id":       user.UserID,
+		"username":      user.Username,
+		"first_name":    user.FirstName,
+		"last_name":     user.LastName,
+		"token":         tokens.Token,
+		"refresh_token": tokens.RefreshToken,
+		"user_type":     user.UserType,
+	}
+}
+
+func sessionClient(c *gin.Context) *domain.SessionClient {
+	return &domain.SessionClient{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+// validEmail accepts an empty email, which means the user gets no emails.
+func validEmail(email string) bool {
+	if email == "" {
+		return true
+	}
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
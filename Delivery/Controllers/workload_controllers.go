@@ -0,0 +1,119 @@
+package Controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WorkloadController struct {
+	WorkloadUsecase domain.WorkloadUsecase
+}
+
+func (wc *WorkloadController) FetchCapacity(c *gin.Context) {
+	capacity, err := wc.WorkloadUsecase.FetchCapacity(c, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, capacity)
+}
+
+func (wc *WorkloadController) UpdateCapacity(c *gin.Context) {
+	var request struct {
+		WeeklyMinutes *int `json:"weekly_minutes"`
+	}
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if request.WeeklyMinutes == nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "weekly_minutes is required"})
+		return
+	}
+
+	capacity, err := wc.WorkloadUsecase.SetWeeklyCapacity(c, c.GetString("user_id"), *request.WeeklyMinutes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, capacity)
+}
+
+func (wc *WorkloadController) AddTimeOff(c *gin.Context) {
+	var timeOff domain.TimeOff
+	if err := c.BindJSON(&timeOff); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	timeOff.TimeOffID = primitive.NewObjectID().Hex()
+
+	if err := wc.WorkloadUsecase.AddTimeOff(c, c.GetString("user_id"), &timeOff); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, timeOff)
+}
+
+func (wc *WorkloadController) RemoveTimeOff(c *gin.Context) {
+	if err := wc.WorkloadUsecase.RemoveTimeOff(c, c.GetString("user_id"), c.Param("time_off_id")); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Time off removed successfully"})
+}
+
+func (wc *WorkloadController) Workload(c *gin.Context) {
+	query, err := bindWorkloadQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	workloads, err := wc.WorkloadUsecase.Workload(c, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, workloads)
+}
+
+func (wc *WorkloadController) Suggestions(c *gin.Context) {
+	query, err := bindWorkloadQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	suggestions, err := wc.WorkloadUsecase.Suggestions(c, query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func bindWorkloadQuery(c *gin.Context) (*domain.WorkloadQuery, error) {
+	query := &domain.WorkloadQuery{
+		UserID:    c.Query("user_id"),
+		ProjectID: c.Query("project_id"),
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	if from != nil {
+		query.From = *from
+	}
+	if value := c.Query("weeks"); value != "" {
+		if query.Weeks, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("weeks must be a number")
+		}
+	}
+	return query, nil
+}
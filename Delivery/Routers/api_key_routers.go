@@ -0,0 +1,34 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	"github.com/segnig/task-manager/Intrastructures"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func ApiKeyRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newApiKeyUsecase := usecases.NewApiKeyUsecase(apiKeys(database), tokenRevocations(database), time.Duration(10*time.Second))
+
+	apiKeyController := controller.ApiKeyController{ApiKeyUsecase: newApiKeyUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.RejectApiKeys())
+		protected.GET("/users/me/tokens", apiKeyController.FetchPersonal)
+		protected.POST("/users/me/tokens", apiKeyController.CreatePersonal)
+		protected.DELETE("/users/me/tokens/:key_id", apiKeyController.RevokePersonal)
+	}
+	admin := protected.Group("/service-keys")
+	{
+		admin.Use(Intrastructures.RequireUserType("ADMIN"))
+		admin.GET("", apiKeyController.FetchService)
+		admin.POST("", apiKeyController.CreateService)
+		admin.DELETE("/:key_id", apiKeyController.RevokeService)
+	}
+}
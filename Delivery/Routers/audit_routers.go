@@ -0,0 +1,28 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func AuditRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newAuditRepository := repositories.NewAuditRepository(*database, domain.AuditEventCollection)
+	newAuditUsecase := usecases.NewAuditUsecase(newAuditRepository, time.Duration(10*time.Second))
+
+	auditController := controller.AuditController{AuditUsecase: newAuditUsecase}
+
+	admin := incomingRoutes.Group("/api/audit-events")
+	{
+		admin.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.RequireUserType("ADMIN"))
+		admin.GET("", auditController.FetchPage)
+	}
+}
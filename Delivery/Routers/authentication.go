@@ -0,0 +1,138 @@
+package Routers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	defaultTokenIssuer = "task-manager"
+	defaultKeyRotation = 30 * 24 * time.Hour
+	// keyRotationCheck is how often the ring looks for a due rotation and
+	// for keys added by other instances.
+	keyRotationCheck = 10 * time.Minute
+)
+
+var (
+	keyRingOnce         sync.Once
+	keyRing             *Intrastructures.KeyRing
+	revocationStoreOnce sync.Once
+	revocationStore     domain.TokenRevocationStore
+	sessionStoreOnce    sync.Once
+	sessionStore        domain.SessionRepository
+	apiKeyStoreOnce     sync.Once
+	apiKeyStore         domain.ApiKeyRepository
+	loginAttemptOnce    sync.Once
+	loginAttemptStore   domain.LoginAttemptStore
+)
+
+// userToken signs and verifies tokens with the shared key ring. JWT_ISSUER
+// and JWT_AUDIENCE set the iss and aud claims (both default to
+// "task-manager").
+func userToken(database *mongo.Database) domain.IUserToken {
+	issuer := Intrastructures.GetFromEnv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = defaultTokenIssuer
+	}
+	audience := Intrastructures.GetFromEnv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = defaultTokenIssuer
+	}
+	return Intrastructures.NeWUserToken(signingKeys(database), issuer, audience)
+}
+
+// signingKeys is the key ring shared by every router. JWT_ALGORITHM picks
+// RS256 (the default) or EdDSA, and JWT_KEY_ROTATION how long a key signs
+// new tokens (default 720h).
+func signingKeys(database *mongo.Database) domain.KeyRing {
+	keyRingOnce.Do(func() {
+		algorithm := Intrastructures.GetFromEnv("JWT_ALGORITHM")
+		if algorithm == "" {
+			algorithm = domain.AlgorithmRS256
+		}
+		rotation := defaultKeyRotation
+		if value := Intrastructures.GetFromEnv("JWT_KEY_ROTATION"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed < time.Hour {
+				log.Fatal("JWT_KEY_ROTATION must be a duration of at least 1h")
+			}
+			rotation = parsed
+		}
+
+		newSigningKeyRepository := repositories.NewSigningKeyRepository(*database, domain.SigningKeyCollection)
+		ring, err := Intrastructures.NewKeyRing(newSigningKeyRepository, algorithm, rotation)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rotate := func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := ring.Rotate(ctx); err != nil {
+				log.Println("error rotating signing keys:", err)
+			}
+		}
+		rotate()
+		Intrastructures.RunPeriodically(keyRotationCheck, rotate)
+		keyRing = ring
+	})
+	return keyRing
+}
+
+// tokenRevocations is the revocation store every router authenticates
+// against. It is built once so that the in-memory store is shared too.
+// TOKEN_REVOCATION_STORE picks "mongo" (the default) or "memory".
+func tokenRevocations(database *mongo.Database) domain.TokenRevocationStore {
+	revocationStoreOnce.Do(func() {
+		switch kind := Intrastructures.GetFromEnv("TOKEN_REVOCATION_STORE"); kind {
+		case "", "mongo":
+			revocationStore = repositories.NewTokenRevocationRepository(*database, domain.RevokedTokenCollection, domain.TokenGenerationCollection)
+		case "memory":
+			revocationStore = Intrastructures.NewMemoryTokenRevocation()
+		default:
+			log.Fatalf("TOKEN_REVOCATION_STORE must be mongo or memory, not '%s'", kind)
+		}
+	})
+	return revocationStore
+}
+
+// sessions is shared by every router so the session indexes are only
+// ensured once.
+func sessions(database *mongo.Database) domain.SessionRepository {
+	sessionStoreOnce.Do(func() {
+		sessionStore = repositories.NewSessionRepository(*database, domain.SessionCollection)
+	})
+	return sessionStore
+}
+
+// apiKeys is shared by every router so the api key indexes are only ensured
+// once.
+func apiKeys(database *mongo.Database) domain.ApiKeyRepository {
+	apiKeyStoreOnce.Do(func() {
+		apiKeyStore = repositories.NewApiKeyRepository(*database, domain.ApiKeyCollection)
+	})
+	return apiKeyStore
+}
+
+// loginAttempts is shared by the login and the lockout endpoints, so the
+// in-memory store is shared too. LOGIN_ATTEMPT_STORE picks "mongo" (the
+// default) or "memory".
+func loginAttempts(database *mongo.Database) domain.LoginAttemptStore {
+	loginAttemptOnce.Do(func() {
+		switch kind := Intrastructures.GetFromEnv("LOGIN_ATTEMPT_STORE"); kind {
+		case "", "mongo":
+			loginAttemptStore = repositories.NewLoginAttemptRepository(*database, domain.LoginAttemptCollection)
+		case "memory":
+			loginAttemptStore = Intrastructures.NewMemoryLoginAttempts()
+		default:
+			log.Fatalf("LOGIN_ATTEMPT_STORE must be mongo or memory, not '%s'", kind)
+		}
+	})
+	return loginAttemptStore
+}
@@ -0,0 +1,36 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func BoardRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newBoardRepository := repositories.NewBoardRepository(*database, domain.BoardCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newBoardUsecase := usecases.NewBoardUsecase(newBoardRepository, newTaskRepository, repositories.NewTransactionManager(*database), time.Duration(10*time.Second), taskEventHandlers(database)...)
+
+	boardController := controller.BoardController{BoardUsecase: newBoardUsecase}
+	location := callerLocation(database)
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/boards", boardController.Create)
+		protected.GET("/boards", boardController.FetchAll)
+		protected.GET("/boards/:board_id", boardController.Fetch)
+		protected.PUT("/boards/:board_id", boardController.Update)
+		protected.DELETE("/boards/:board_id", boardController.Delete)
+		protected.GET("/boards/:board_id/tasks", location, boardController.View)
+		protected.POST("/boards/:board_id/move", location, boardController.Move)
+	}
+}
@@ -0,0 +1,45 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func CalendarRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newCalendarRepository := repositories.NewCalendarRepository(*database, domain.CalendarCollection)
+	newCalendarUsecase := usecases.NewCalendarUsecase(newCalendarRepository, time.Duration(10*time.Second))
+
+	calendarController := controller.CalendarController{CalendarUsecase: newCalendarUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.GET("/calendar", calendarController.FetchMine)
+		protected.PUT("/calendar", calendarController.UpdateMine)
+		protected.GET("/calendar/due-date", calendarController.DueIn)
+		protected.GET("/projects/:project_id/calendar", calendarController.FetchProject)
+	}
+	// a project calendar sets the due dates of every task in the project
+	admin := protected.Group("/projects/:project_id/calendar")
+	{
+		admin.Use(Intrastructures.RequireUserType("ADMIN"))
+		admin.PUT("", calendarController.UpdateProject)
+	}
+}
+
+// callerLocation renders task dates in the caller's time zone, for the
+// routes outside TaskRoutes that return tasks.
+func callerLocation(database *mongo.Database) gin.HandlerFunc {
+	newCalendarRepository := repositories.NewCalendarRepository(*database, domain.CalendarCollection)
+	return Intrastructures.CallerLocation(usecases.NewCalendarUsecase(newCalendarRepository, time.Duration(10*time.Second)))
+}
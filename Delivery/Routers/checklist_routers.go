@@ -0,0 +1,31 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func ChecklistRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newChecklistUsecase := usecases.NewChecklistUsecase(newTaskRepository, time.Duration(10*time.Second))
+
+	checklistController := controller.ChecklistController{ChecklistUsecase: newChecklistUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/tasks/:task_id/checklist", checklistController.AddItem)
+		protected.PATCH("/tasks/:task_id/checklist/:item_id", checklistController.UpdateItem)
+		protected.POST("/tasks/:task_id/checklist/:item_id/move", checklistController.MoveItem)
+		protected.DELETE("/tasks/:task_id/checklist/:item_id", checklistController.RemoveItem)
+	}
+}
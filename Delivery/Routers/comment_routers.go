@@ -0,0 +1,34 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func CommentRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newCommentRepository := repositories.NewCommentRepository(*database, domain.CommentCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newCommentUsecase := usecases.NewCommentUsecase(newCommentRepository, newTaskRepository, time.Duration(10*time.Second),
+		newNotificationDispatcher(database),
+		newEmailNotifier(database),
+	)
+
+	commentController := controller.CommentController{CommentUsecase: newCommentUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/tasks/:task_id/comments", commentController.Create)
+		protected.GET("/tasks/:task_id/comments", commentController.FetchByTask)
+		protected.DELETE("/comments/:comment_id", commentController.Delete)
+	}
+}
@@ -0,0 +1,40 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func CustomFieldRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newCustomFieldRepository := repositories.NewCustomFieldRepository(*database, domain.CustomFieldCollection, domain.TaskCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newUserRepository := repositories.NewUserRepository(*database, domain.UserCollection)
+	newCustomFieldUsecase := usecases.NewCustomFieldUsecase(newCustomFieldRepository, newTaskRepository, newUserRepository, time.Duration(10*time.Second))
+
+	customFieldController := controller.CustomFieldController{CustomFieldUsecase: newCustomFieldUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/custom-fields", customFieldController.Create)
+		protected.GET("/custom-fields", customFieldController.FetchAll)
+		protected.GET("/custom-fields/:field_id", customFieldController.Fetch)
+		protected.PATCH("/tasks/:task_id/custom-fields", customFieldController.SetValues)
+	}
+	// changing or deleting a definition touches the values on every task
+	admin := protected.Group("/custom-fields/:field_id")
+	{
+		admin.Use(Intrastructures.RequireUserType("ADMIN"))
+		admin.PUT("", customFieldController.Update)
+		admin.DELETE("", customFieldController.Delete)
+	}
+}
@@ -0,0 +1,16 @@
+package Routers
+
+import (
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	"github.com/segnig/task-manager/Intrastructures"
+)
+
+func JWKSRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+
+	jwksController := controller.JWKSController{KeyRing: signingKeys(database)}
+
+	incomingRoutes.GET("/.well-known/jwks.json", jwksController.JWKS)
+}
@@ -0,0 +1,42 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func LabelRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newLabelRepository := repositories.NewLabelRepository(*database, domain.LabelCollection, domain.TaskCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newLabelUsecase := usecases.NewLabelUsecase(newLabelRepository, newTaskRepository, time.Duration(10*time.Second))
+
+	labelController := controller.LabelController{LabelUsecase: newLabelUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/labels", labelController.Create)
+		protected.GET("/labels", labelController.FetchAll)
+		protected.GET("/labels/usage", labelController.Usage)
+		protected.GET("/labels/:label_id", labelController.Fetch)
+		protected.POST("/tasks/:task_id/labels", labelController.Attach)
+		protected.DELETE("/tasks/:task_id/labels/:label", labelController.Detach)
+	}
+	// renaming, merging and deleting rewrite the labels of every task
+	admin := protected.Group("/labels/:label_id")
+	{
+		admin.Use(Intrastructures.RequireUserType("ADMIN"))
+		admin.PUT("", labelController.Update)
+		admin.DELETE("", labelController.Delete)
+		admin.POST("/merge", labelController.Merge)
+	}
+}
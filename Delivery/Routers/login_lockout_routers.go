@@ -0,0 +1,79 @@
+package Routers
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// a few typos are free, after that every failure doubles the wait
+	loginFreeFailures   = 3
+	ipLoginFreeFailures = 10
+	loginBaseDelay      = time.Second
+	loginMaxDelay       = 30 * time.Second
+)
+
+func LoginLockoutRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+
+	loginLockoutController := controller.LoginLockoutController{LoginThrottle: loginThrottle(database)}
+
+	admin := incomingRoutes.Group("/api/login-lockouts")
+	{
+		admin.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.RequireUserType("ADMIN"))
+		admin.GET("", loginLockoutController.FetchLocked)
+		admin.DELETE("/:scope/:value", loginLockoutController.Unlock)
+	}
+}
+
+// loginThrottle is used by the password login and the lockout endpoints.
+// LOGIN_MAX_FAILURES (default 10) and LOGIN_MAX_IP_FAILURES (default 100)
+// failures lock a username or an address out for LOGIN_LOCKOUT (default
+// 15m). Failures are forgotten after the same time without one.
+func loginThrottle(database *mongo.Database) domain.LoginThrottleUsecase {
+	policy := domain.LoginPolicy{
+		Username:  domain.LoginLimit{FreeFailures: loginFreeFailures, MaxFailures: loginLimitFromEnv("LOGIN_MAX_FAILURES", domain.DefaultMaxLoginFailures)},
+		IP:        domain.LoginLimit{FreeFailures: ipLoginFreeFailures, MaxFailures: loginLimitFromEnv("LOGIN_MAX_IP_FAILURES", domain.DefaultMaxIPLoginFailures)},
+		BaseDelay: loginBaseDelay,
+		MaxDelay:  loginMaxDelay,
+		Lockout:   domain.DefaultLoginLockout,
+	}
+	if value := Intrastructures.GetFromEnv("LOGIN_LOCKOUT"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < time.Minute {
+			log.Fatal("LOGIN_LOCKOUT must be a duration of at least 1m")
+		}
+		policy.Lockout = parsed
+	}
+	policy.Window = policy.Lockout
+
+	return usecases.NewLoginThrottleUsecase(
+		loginAttempts(database),
+		repositories.NewAuditRepository(*database, domain.AuditEventCollection),
+		policy,
+		time.Duration(10*time.Second),
+	)
+}
+
+func loginLimitFromEnv(name string, fallback int) int {
+	value := Intrastructures.GetFromEnv(name)
+	if value == "" {
+		return fallback
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 {
+		log.Fatalf("%s must be a positive number", name)
+	}
+	return limit
+}
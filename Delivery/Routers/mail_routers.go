@@ -0,0 +1,55 @@
+package Routers
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+const (
+	mailSweepInterval = 15 * time.Minute
+	defaultDigestHour = 7
+)
+
+func MailRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newUserRepository := repositories.NewUserRepository(*database, domain.UserCollection)
+	newPreferenceRepository := repositories.NewNotificationPreferenceRepository(*database, domain.NotificationPreferenceCollection)
+	newEmailLogRepository := repositories.NewEmailLogRepository(*database, domain.EmailLogCollection)
+	newCalendarRepository := repositories.NewCalendarRepository(*database, domain.CalendarCollection)
+
+	digestHour := defaultDigestHour
+	if value := Intrastructures.GetFromEnv("DIGEST_HOUR"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 23 {
+			log.Fatal("DIGEST_HOUR must be an hour between 0 and 23")
+		}
+		digestHour = parsed
+	}
+	newMailUsecase := usecases.NewMailUsecase(Intrastructures.NewMailer(), newTaskRepository, newUserRepository, newPreferenceRepository, newEmailLogRepository, newCalendarRepository, digestHour, time.Duration(10*time.Second))
+
+	Intrastructures.RunPeriodically(mailSweepInterval, func() {
+		now := time.Now()
+		newMailUsecase.SendDueReminders(context.Background(), now)
+		newMailUsecase.SendDailyDigests(context.Background(), now)
+	})
+
+	mailController := controller.MailController{MailUsecase: newMailUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.GET("/notifications/digest", mailController.PreviewDigest)
+	}
+}
@@ -0,0 +1,37 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func NotificationRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newNotificationRepository := repositories.NewNotificationRepository(*database, domain.NotificationCollection)
+	newPreferenceRepository := repositories.NewNotificationPreferenceRepository(*database, domain.NotificationPreferenceCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newNotificationUsecase := usecases.NewNotificationUsecase(newNotificationRepository, newPreferenceRepository, newTaskRepository, time.Duration(10*time.Second))
+
+	notificationController := controller.NotificationController{NotificationUsecase: newNotificationUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.GET("/notifications", notificationController.FetchAll)
+		protected.GET("/notifications/unread-count", notificationController.UnreadCount)
+		protected.POST("/notifications/read-all", notificationController.MarkAllRead)
+		protected.POST("/notifications/:notification_id/read", notificationController.MarkRead)
+		protected.GET("/notifications/preferences", notificationController.FetchPreferences)
+		protected.PUT("/notifications/preferences", notificationController.UpdatePreferences)
+		protected.POST("/tasks/:task_id/watch", notificationController.Watch)
+		protected.DELETE("/tasks/:task_id/watch", notificationController.Unwatch)
+	}
+}
@@ -0,0 +1,109 @@
+package Routers
+
+import (
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func OIDCRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newUserRepository := repositories.NewUserRepository(*database, domain.UserCollection)
+	newUserUsecase := usecases.NewUserUsecase(newUserRepository, time.Duration(10*time.Second))
+	newRefreshTokenRepository := repositories.NewRefreshTokenRepository(*database, domain.RefreshTokenCollection)
+	newTokenUsecase := usecases.NewTokenUsecase(ut, newRefreshTokenRepository, tokenRevocations(database), sessions(database), newUserRepository, time.Duration(10*time.Second))
+
+	newOIDCStateRepository := repositories.NewOIDCStateRepository(*database, domain.OIDCStateCollection)
+	newExternalIdentityRepository := repositories.NewExternalIdentityRepository(*database, domain.ExternalIdentityCollection)
+	// the provider is called during the request, so the timeout covers
+	// discovery, the code exchange and fetching keys
+	newOIDCUsecase := usecases.NewOIDCUsecase(oidcProviders(), newOIDCStateRepository, newExternalIdentityRepository, newUserRepository, tokenRevocations(database), time.Duration(30*time.Second))
+
+	oidcController := controller.OIDCController{
+		OIDCUsecase:      newOIDCUsecase,
+		TokenUsecase:     newTokenUsecase,
+		TwoFactorUsecase: twoFactor(database, newUserRepository, Intrastructures.NewPasswordProvider(12)),
+		UserUsecase:      newUserUsecase,
+	}
+
+	public := incomingRoutes.Group("/api/users/oidc")
+	{
+		public.GET("/providers", oidcController.Providers)
+		public.GET("/:provider/login", oidcController.Login)
+		public.GET("/:provider/callback", oidcController.Callback)
+	}
+}
+
+// oidcProviders reads the identity providers named in OIDC_PROVIDERS, e.g.
+// "corp". Each is configured with OIDC_<NAME>_ variables:
+//
+//	ISSUER, CLIENT_ID, REDIRECT_URL  required
+//	CLIENT_SECRET                    empty for public clients
+//	SCOPES                           default "openid profile email"
+//	GROUPS_CLAIM                     default "groups"
+//	GROUP_ROLES                      e.g. "task-admins=ADMIN,staff=USER"
+//	DEFAULT_ROLE                     role without a mapped group, default
+//	                                 USER; NONE refuses those users
+func oidcProviders() []domain.OIDCProvider {
+	providers := []domain.OIDCProvider{}
+	for _, name := range strings.Split(Intrastructures.GetFromEnv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		setting := func(key string, fallback string) string {
+			if value := strings.TrimSpace(Intrastructures.GetFromEnv(prefix + key)); value != "" {
+				return value
+			}
+			return fallback
+		}
+
+		config := &domain.OIDCProviderConfig{
+			Name:         name,
+			Issuer:       setting("ISSUER", ""),
+			ClientID:     setting("CLIENT_ID", ""),
+			ClientSecret: setting("CLIENT_SECRET", ""),
+			RedirectURL:  setting("REDIRECT_URL", ""),
+			Scopes:       strings.Fields(setting("SCOPES", "openid profile email")),
+			GroupsClaim:  setting("GROUPS_CLAIM", "groups"),
+			GroupRoles:   map[string]string{},
+			DefaultRole:  setting("DEFAULT_ROLE", "USER"),
+		}
+		if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			log.Fatalf("%sISSUER, %sCLIENT_ID and %sREDIRECT_URL are required", prefix, prefix, prefix)
+		}
+		if !slices.Contains(config.Scopes, "openid") {
+			config.Scopes = append([]string{"openid"}, config.Scopes...)
+		}
+		if config.DefaultRole == "NONE" {
+			config.DefaultRole = ""
+		}
+		for _, mapping := range strings.Split(setting("GROUP_ROLES", ""), ",") {
+			if strings.TrimSpace(mapping) == "" {
+				continue
+			}
+			group, role, ok := strings.Cut(mapping, "=")
+			role = strings.TrimSpace(role)
+			if !ok || (role != "ADMIN" && role != "USER") {
+				log.Fatalf("%sGROUP_ROLES must look like group=ADMIN,other=USER", prefix)
+			}
+			config.GroupRoles[strings.TrimSpace(group)] = role
+		}
+		if config.DefaultRole != "" && config.DefaultRole != "ADMIN" && config.DefaultRole != "USER" {
+			log.Fatalf("%sDEFAULT_ROLE must be ADMIN, USER or NONE", prefix)
+		}
+		providers = append(providers, Intrastructures.NewOIDCProvider(config, 10*time.Second))
+	}
+	return providers
+}
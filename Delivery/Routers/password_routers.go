@@ -0,0 +1,40 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+// PasswordRoutes emails reset tokens through the SMTP settings.
+// PASSWORD_RESET_URL is the page of the frontend that takes the token; the
+// email links to it with the token in the token query parameter.
+func PasswordRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newUserRepository := repositories.NewUserRepository(*database, domain.UserCollection)
+	newRefreshTokenRepository := repositories.NewRefreshTokenRepository(*database, domain.RefreshTokenCollection)
+	newTokenUsecase := usecases.NewTokenUsecase(ut, newRefreshTokenRepository, tokenRevocations(database), sessions(database), newUserRepository, time.Duration(10*time.Second))
+	newPasswordResetRepository := repositories.NewPasswordResetRepository(*database, domain.PasswordResetCollection)
+	newNotifier := usecases.NewEmailPasswordResetNotifier(Intrastructures.NewMailer(), Intrastructures.GetFromEnv("PASSWORD_RESET_URL"))
+	newPasswordUsecase := usecases.NewPasswordUsecase(newUserRepository, newPasswordResetRepository, newNotifier, newTokenUsecase, Intrastructures.NewPasswordProvider(12), time.Duration(10*time.Second))
+
+	passwordController := controller.PasswordController{PasswordUsecase: newPasswordUsecase}
+
+	public := incomingRoutes.Group("/api/users/password")
+	{
+		public.POST("/forgot", passwordController.Forgot)
+		public.POST("/reset", passwordController.Reset)
+	}
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.RejectApiKeys())
+		protected.POST("/users/me/password", passwordController.Change)
+	}
+}
@@ -0,0 +1,61 @@
+package Routers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+const (
+	defaultOverdueSweepInterval = 5 * time.Minute
+	webhookTimeout              = 5 * time.Second
+)
+
+func RuleRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newRuleRepository := repositories.NewRuleRepository(*database, domain.RuleCollection)
+	newExecutionRepository := repositories.NewRuleExecutionRepository(*database, domain.RuleExecutionCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newRuleUsecase := usecases.NewRuleUsecase(newRuleRepository, newExecutionRepository, newTaskRepository, Intrastructures.NewWebhookSender(webhookTimeout), time.Duration(10*time.Second))
+
+	engine := newAutomationEngine(database, newStatusHistoryRecorder(database), newNotificationDispatcher(database), newEmailNotifier(database))
+	interval := defaultOverdueSweepInterval
+	if value := Intrastructures.GetFromEnv("AUTOMATION_SWEEP_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			log.Fatal("AUTOMATION_SWEEP_INTERVAL must be a positive duration like 5m")
+		}
+		interval = parsed
+	}
+	Intrastructures.RunPeriodically(interval, func() {
+		engine.SweepOverdue(context.Background())
+	})
+
+	ruleController := controller.RuleController{RuleUsecase: newRuleUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.GET("/rules", ruleController.FetchAll)
+		protected.GET("/rules/:rule_id", ruleController.Fetch)
+		protected.POST("/rules/:rule_id/dry-run", ruleController.DryRun)
+		protected.GET("/rules/:rule_id/executions", ruleController.Executions)
+	}
+	// rules act on every matching task and can call out to webhooks
+	admin := protected.Group("/rules")
+	{
+		admin.Use(Intrastructures.RequireUserType("ADMIN"))
+		admin.POST("", ruleController.Create)
+		admin.PUT("/:rule_id", ruleController.Update)
+		admin.DELETE("/:rule_id", ruleController.Delete)
+	}
+}
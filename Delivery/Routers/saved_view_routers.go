@@ -0,0 +1,34 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func SavedViewRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newSavedViewRepository := repositories.NewSavedViewRepository(*database, domain.SavedViewCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newSavedViewUsecase := usecases.NewSavedViewUsecase(newSavedViewRepository, newTaskRepository, time.Duration(10*time.Second))
+
+	savedViewController := controller.SavedViewController{SavedViewUsecase: newSavedViewUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/views", savedViewController.Create)
+		protected.GET("/views", savedViewController.FetchAll)
+		protected.GET("/views/:view_id", savedViewController.Fetch)
+		protected.PUT("/views/:view_id", savedViewController.Update)
+		protected.DELETE("/views/:view_id", savedViewController.Delete)
+		protected.GET("/views/:view_id/tasks", callerLocation(database), savedViewController.Execute)
+	}
+}
@@ -0,0 +1,39 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func SprintRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newSprintRepository := repositories.NewSprintRepository(*database, domain.SprintCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newHistoryRepository := repositories.NewTaskStatusHistoryRepository(*database, domain.TaskStatusHistoryCollection)
+	newSprintUsecase := usecases.NewSprintUsecase(newSprintRepository, newTaskRepository, newHistoryRepository, repositories.NewTransactionManager(*database), time.Duration(10*time.Second))
+
+	sprintController := controller.SprintController{SprintUsecase: newSprintUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/sprints", sprintController.Create)
+		protected.GET("/sprints", sprintController.FetchAll)
+		protected.GET("/sprints/:sprint_id", sprintController.Fetch)
+		protected.PUT("/sprints/:sprint_id", sprintController.Update)
+		protected.DELETE("/sprints/:sprint_id", sprintController.Delete)
+		protected.POST("/sprints/:sprint_id/tasks", sprintController.Plan)
+		protected.DELETE("/sprints/:sprint_id/tasks/:task_id", sprintController.Unplan)
+		protected.POST("/sprints/:sprint_id/start", sprintController.Start)
+		protected.POST("/sprints/:sprint_id/close", sprintController.Close)
+		protected.GET("/sprints/:sprint_id/burndown", callerLocation(database), sprintController.Burndown)
+	}
+}
@@ -0,0 +1,34 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func StatsRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newStatsRepository := repositories.NewStatsRepository(*database, domain.TaskCollection, domain.TaskStatusHistoryCollection)
+	newStatsUsecase := usecases.NewStatsUsecase(newStatsRepository, time.Duration(10*time.Second))
+
+	statsController := controller.StatsController{StatsUsecase: newStatsUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.GET("/stats", statsController.Summary)
+		protected.GET("/stats/status", statsController.CountBy("status"))
+		protected.GET("/stats/assignees", statsController.CountBy("assignee"))
+		protected.GET("/stats/projects", statsController.CountBy("project"))
+		protected.GET("/stats/overdue", statsController.Overdue)
+		protected.GET("/stats/throughput", statsController.Throughput)
+		protected.GET("/stats/flow", statsController.Flow)
+	}
+}
@@ -0,0 +1,63 @@
+package Routers
+
+import (
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// taskEventHandlers builds the handlers told about every task change, in the
+// order they run.
+func taskEventHandlers(database *mongo.Database) []domain.TaskEventHandler {
+	recorder := newStatusHistoryRecorder(database)
+	dispatcher := newNotificationDispatcher(database)
+	emailNotifier := newEmailNotifier(database)
+	return []domain.TaskEventHandler{
+		recorder,
+		dispatcher,
+		emailNotifier,
+		newAutomationEngine(database, recorder, dispatcher, emailNotifier),
+	}
+}
+
+func newEmailNotifier(database *mongo.Database) domain.EmailNotifier {
+	return usecases.NewEmailNotifier(
+		Intrastructures.NewMailer(),
+		repositories.NewNotificationPreferenceRepository(*database, domain.NotificationPreferenceCollection),
+		repositories.NewTaskRepository(*database, domain.TaskCollection),
+		repositories.NewUserRepository(*database, domain.UserCollection),
+		repositories.NewCalendarRepository(*database, domain.CalendarCollection),
+	)
+}
+
+func newNotificationDispatcher(database *mongo.Database) domain.NotificationDispatcher {
+	return usecases.NewNotificationDispatcher(
+		repositories.NewNotificationRepository(*database, domain.NotificationCollection),
+		repositories.NewNotificationPreferenceRepository(*database, domain.NotificationPreferenceCollection),
+		repositories.NewTaskRepository(*database, domain.TaskCollection),
+		repositories.NewUserRepository(*database, domain.UserCollection),
+	)
+}
+
+func newStatusHistoryRecorder(database *mongo.Database) domain.TaskEventHandler {
+	newHistoryRepository := repositories.NewTaskStatusHistoryRepository(*database, domain.TaskStatusHistoryCollection)
+	return usecases.NewStatusHistoryRecorder(newHistoryRepository)
+}
+
+// newAutomationEngine passes the changes rules make on to followUp.
+func newAutomationEngine(database *mongo.Database, followUp ...domain.TaskEventHandler) domain.AutomationEngine {
+	return usecases.NewAutomationEngine(
+		repositories.NewRuleRepository(*database, domain.RuleCollection),
+		repositories.NewRuleExecutionRepository(*database, domain.RuleExecutionCollection),
+		repositories.NewTaskRepository(*database, domain.TaskCollection),
+		repositories.NewLabelRepository(*database, domain.LabelCollection, domain.TaskCollection),
+		repositories.NewCommentRepository(*database, domain.CommentCollection),
+		Intrastructures.NewWebhookSender(webhookTimeout),
+		time.Duration(10*time.Second),
+		followUp...,
+	)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
 	"github.com/segnig/task-manager/Intrastructures"
 	repositories "github.com/segnig/task-manager/Repositories"
 	usecases "github.com/segnig/task-manager/Usecases"
@@ -12,25 +13,28 @@ import (
 
 func TaskRoutes(incomingRoutes *gin.Engine) {
 
-	SECRET_KEY := Intrastructures.GetFromEnv("SECRET_KEY")
-	ut := Intrastructures.NeWUserToken(SECRET_KEY)
-
 	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
 	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
 	newTaskRepository := repositories.NewTaskRepository(*database, "task")
-	newTaskUsecase := usecases.NewTaskUsecase(newTaskRepository, time.Duration(10*time.Second))
+	newCalendarRepository := repositories.NewCalendarRepository(*database, domain.CalendarCollection)
+	newTaskUsecase := usecases.NewTaskUsecase(newTaskRepository, newCalendarRepository, time.Duration(10*time.Second), taskEventHandlers(database)...)
+	newCalendarUsecase := usecases.NewCalendarUsecase(newCalendarRepository, time.Duration(10*time.Second))
 
 	taskController := controller.TaskController{TaskUsecase: newTaskUsecase}
 
 	protected := incomingRoutes.Group("/api")
 	{
-		protected.Use(Intrastructures.Authentication(ut))
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.CallerLocation(newCalendarUsecase))
 		protected.DELETE("/tasks/:task_id", taskController.Delete)
 		protected.PUT("/tasks/:task_id", taskController.Update)
 		protected.POST("/tasks", taskController.Create)
+		protected.GET("/tasks/next", taskController.Next)
+		protected.GET("/tasks/export", taskController.Export)
 	}
 	public := incomingRoutes.Group("/api")
 	{
+		public.Use(Intrastructures.CallerLocation(newCalendarUsecase))
 		public.GET("/tasks/:task_id", taskController.Fetch)
 		public.GET("/tasks", taskController.FetchAll)
 	}
@@ -0,0 +1,36 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func TaskTemplateRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newTemplateRepository := repositories.NewTaskTemplateRepository(*database, domain.TaskTemplateCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newCalendarRepository := repositories.NewCalendarRepository(*database, domain.CalendarCollection)
+	newTaskUsecase := usecases.NewTaskUsecase(newTaskRepository, newCalendarRepository, time.Duration(10*time.Second), taskEventHandlers(database)...)
+	newTemplateUsecase := usecases.NewTaskTemplateUsecase(newTemplateRepository, newTaskUsecase, repositories.NewTransactionManager(*database), time.Duration(30*time.Second))
+
+	templateController := controller.TaskTemplateController{TaskTemplateUsecase: newTemplateUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/templates", templateController.Create)
+		protected.GET("/templates", templateController.FetchAll)
+		protected.GET("/templates/:template_id", templateController.Fetch)
+		protected.PUT("/templates/:template_id", templateController.Update)
+		protected.DELETE("/templates/:template_id", templateController.Delete)
+		protected.POST("/templates/:template_id/instantiate", callerLocation(database), templateController.Instantiate)
+	}
+}
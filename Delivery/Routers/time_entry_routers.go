@@ -0,0 +1,35 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func TimeEntryRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newTimeEntryRepository := repositories.NewTimeEntryRepository(*database, domain.TimeEntryCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newTimeEntryUsecase := usecases.NewTimeEntryUsecase(newTimeEntryRepository, newTaskRepository, time.Duration(10*time.Second))
+
+	timeEntryController := controller.TimeEntryController{TimeEntryUsecase: newTimeEntryUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.POST("/tasks/:task_id/timer/start", timeEntryController.StartTimer)
+		protected.POST("/timer/stop", timeEntryController.StopTimer)
+		protected.GET("/timer", timeEntryController.FetchRunning)
+		protected.POST("/time-entries", timeEntryController.Create)
+		protected.GET("/time-entries", timeEntryController.FetchAll)
+		protected.DELETE("/time-entries/:entry_id", timeEntryController.Delete)
+		protected.GET("/timesheets", timeEntryController.Timesheet)
+	}
+}
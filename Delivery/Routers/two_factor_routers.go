@@ -0,0 +1,73 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultTOTPIssuer = "Task Manager"
+
+func TwoFactorRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newUserRepository := repositories.NewUserRepository(*database, domain.UserCollection)
+	newUserUsecase := usecases.NewUserUsecase(newUserRepository, time.Duration(10*time.Second))
+	newRefreshTokenRepository := repositories.NewRefreshTokenRepository(*database, domain.RefreshTokenCollection)
+	newTokenUsecase := usecases.NewTokenUsecase(ut, newRefreshTokenRepository, tokenRevocations(database), sessions(database), newUserRepository, time.Duration(10*time.Second))
+
+	twoFactorController := controller.TwoFactorController{
+		TwoFactorUsecase: twoFactor(database, newUserRepository, Intrastructures.NewPasswordProvider(12)),
+		TokenUsecase:     newTokenUsecase,
+		UserUsecase:      newUserUsecase,
+	}
+
+	public := incomingRoutes.Group("/api/users/login/2fa")
+	{
+		public.POST("", twoFactorController.VerifyLogin)
+		public.POST("/setup", twoFactorController.BeginSetup)
+		public.POST("/setup/confirm", twoFactorController.ConfirmSetup)
+	}
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.RejectApiKeys())
+		protected.GET("/users/me/2fa", twoFactorController.Status)
+		protected.POST("/users/me/2fa", twoFactorController.Begin)
+		protected.POST("/users/me/2fa/confirm", twoFactorController.Confirm)
+		protected.POST("/users/me/2fa/recovery-codes", twoFactorController.RegenerateRecoveryCodes)
+		protected.POST("/users/me/2fa/disable", twoFactorController.Disable)
+	}
+	admin := protected.Group("/two-factor")
+	{
+		admin.Use(Intrastructures.RequireUserType("ADMIN"))
+		admin.GET("/policies", twoFactorController.Policies)
+		admin.PUT("/policies/:user_type", twoFactorController.SetPolicy)
+		admin.DELETE("/users/:user_id", twoFactorController.Reset)
+	}
+}
+
+// twoFactor is used by the password login and the 2FA endpoints. TOTP_ISSUER
+// names the app in authenticator apps (default "Task Manager").
+func twoFactor(database *mongo.Database, userRepository domain.UserRepository, password domain.PasswordServiceProvider) domain.TwoFactorUsecase {
+	issuer := Intrastructures.GetFromEnv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = defaultTOTPIssuer
+	}
+	return usecases.NewTwoFactorUsecase(
+		repositories.NewTwoFactorRepository(*database, domain.TwoFactorCollection),
+		repositories.NewTwoFactorChallengeRepository(*database, domain.TwoFactorChallengeCollection),
+		repositories.NewTwoFactorPolicyRepository(*database, domain.TwoFactorPolicyCollection),
+		userRepository,
+		Intrastructures.NewTOTPProvider(),
+		password,
+		issuer,
+		time.Duration(10*time.Second),
+	)
+}
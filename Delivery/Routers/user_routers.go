@@ -5,40 +5,67 @@ import (
 
 	"github.com/gin-gonic/gin"
 	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
 	"github.com/segnig/task-manager/Intrastructures"
 	repositories "github.com/segnig/task-manager/Repositories"
 	usecases "github.com/segnig/task-manager/Usecases"
 )
 
+// UserRoutes emails verification tokens through the SMTP settings.
+// EMAIL_VERIFICATION_URL is the page of the frontend that takes the token;
+// the email links to it with the token in the token query parameter.
 func UserRoutes(incomingRoutes *gin.Engine) {
-	SECRET_KEY := Intrastructures.GetFromEnv("SECRET_KEY")
-
 	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
 	database := Intrastructures.DBinstance(mongoDB)
+	newUserToke := userToken(database)
 	newUserRepository := repositories.NewUserRepository(*database, "user")
 	newUserUsecase := usecases.NewUserUsecase(newUserRepository, time.Duration(10*time.Second))
 
 	newPasswordProvider := Intrastructures.NewPasswordProvider(12)
 
-	newUserToke := Intrastructures.NeWUserToken(SECRET_KEY)
+	newRefreshTokenRepository := repositories.NewRefreshTokenRepository(*database, domain.RefreshTokenCollection)
+	newTokenUsecase := usecases.NewTokenUsecase(newUserToke, newRefreshTokenRepository, tokenRevocations(database), sessions(database), newUserRepository, time.Duration(10*time.Second))
+
+	newSessionUsecase := usecases.NewSessionUsecase(sessions(database), newRefreshTokenRepository, time.Duration(10*time.Second))
+
+	newEmailVerificationRepository := repositories.NewEmailVerificationRepository(*database, domain.EmailVerificationCollection)
+	newEmailNotifier := usecases.NewEmailVerificationNotifier(Intrastructures.NewMailer(), Intrastructures.GetFromEnv("EMAIL_VERIFICATION_URL"))
+	newEmailUsecase := usecases.NewEmailUsecase(newUserRepository, newEmailVerificationRepository, newEmailNotifier, newPasswordProvider, time.Duration(10*time.Second))
 
 	userController := controller.UserController{
-		UserUsecase: newUserUsecase,
-		Password:    newPasswordProvider,
-		UserToken:   newUserToke,
+		UserUsecase:      newUserUsecase,
+		TokenUsecase:     newTokenUsecase,
+		TwoFactorUsecase: twoFactor(database, newUserRepository, newPasswordProvider),
+		EmailUsecase:     newEmailUsecase,
+		LoginThrottle:    loginThrottle(database),
+		Password:         newPasswordProvider,
+		UserToken:        newUserToke,
 	}
+	sessionController := controller.SessionController{SessionUsecase: newSessionUsecase}
+	emailController := controller.EmailController{EmailUsecase: newEmailUsecase}
 
 	protected := incomingRoutes.Group("/api")
 	{
-		protected.Use(Intrastructures.Authentication(newUserToke))
-		protected.DELETE("/users/:user_id", userController.Delete)
-		protected.PUT("/users/:user_id", userController.Update)
+		protected.Use(Intrastructures.Authentication(newUserToke, tokenRevocations(database), sessions(database), apiKeys(database)))
 		protected.GET("/users/:user_id", userController.Fetch)
 		protected.GET("/users", userController.FetchAll)
 	}
+	account := incomingRoutes.Group("/api/users")
+	{
+		account.Use(Intrastructures.Authentication(newUserToke, tokenRevocations(database), sessions(database), apiKeys(database)), Intrastructures.RejectApiKeys())
+		account.POST("/logout", userController.Logout)
+		account.POST("/logout-all", userController.LogoutAll)
+		account.GET("/me/sessions", sessionController.FetchMine)
+		account.DELETE("/me/sessions/:session_id", sessionController.Revoke)
+		account.POST("/me/email/verification", emailController.SendVerification)
+		account.DELETE("/:user_id", userController.Delete)
+		account.PUT("/:user_id", userController.Update)
+	}
 	public := incomingRoutes.Group("/api/users")
 	{
 		public.POST("/register", userController.Register)
 		public.POST("/login", userController.Login)
+		public.POST("/token/refresh", userController.Refresh)
+		public.POST("/email/verify", emailController.Verify)
 	}
 }
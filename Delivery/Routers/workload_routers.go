@@ -0,0 +1,35 @@
+package Routers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	controller "github.com/segnig/task-manager/Delivery/Controllers"
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	repositories "github.com/segnig/task-manager/Repositories"
+	usecases "github.com/segnig/task-manager/Usecases"
+)
+
+func WorkloadRoutes(incomingRoutes *gin.Engine) {
+	mongoDB := Intrastructures.GetFromEnv("MONGO_DB")
+	database := Intrastructures.DBinstance(mongoDB)
+	ut := userToken(database)
+	newCapacityRepository := repositories.NewCapacityRepository(*database, domain.CapacityCollection)
+	newTaskRepository := repositories.NewTaskRepository(*database, domain.TaskCollection)
+	newUserRepository := repositories.NewUserRepository(*database, domain.UserCollection)
+	newWorkloadUsecase := usecases.NewWorkloadUsecase(newCapacityRepository, newTaskRepository, newUserRepository, time.Duration(10*time.Second))
+
+	workloadController := controller.WorkloadController{WorkloadUsecase: newWorkloadUsecase}
+
+	protected := incomingRoutes.Group("/api")
+	{
+		protected.Use(Intrastructures.Authentication(ut, tokenRevocations(database), sessions(database), apiKeys(database)))
+		protected.GET("/capacity", workloadController.FetchCapacity)
+		protected.PUT("/capacity", workloadController.UpdateCapacity)
+		protected.POST("/capacity/time-off", workloadController.AddTimeOff)
+		protected.DELETE("/capacity/time-off/:time_off_id", workloadController.RemoveTimeOff)
+		protected.GET("/workload", workloadController.Workload)
+		protected.GET("/workload/suggestions", workloadController.Suggestions)
+	}
+}
@@ -12,6 +12,28 @@ func main() {
 	router.Use(gin.Logger())
 	routers.TaskRoutes(router)
 	routers.UserRoutes(router)
+	routers.OIDCRoutes(router)
+	routers.TwoFactorRoutes(router)
+	routers.PasswordRoutes(router)
+	routers.SavedViewRoutes(router)
+	routers.LabelRoutes(router)
+	routers.TimeEntryRoutes(router)
+	routers.BoardRoutes(router)
+	routers.SprintRoutes(router)
+	routers.TaskTemplateRoutes(router)
+	routers.ChecklistRoutes(router)
+	routers.CustomFieldRoutes(router)
+	routers.CommentRoutes(router)
+	routers.RuleRoutes(router)
+	routers.NotificationRoutes(router)
+	routers.MailRoutes(router)
+	routers.StatsRoutes(router)
+	routers.WorkloadRoutes(router)
+	routers.CalendarRoutes(router)
+	routers.JWKSRoutes(router)
+	routers.ApiKeyRoutes(router)
+	routers.LoginLockoutRoutes(router)
+	routers.AuditRoutes(router)
 
 	port := Intrastructures.GetFromEnv("PORT")
 	router.Run(":" + port)
@@ -0,0 +1,108 @@
+package domains
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const ApiKeyCollection = "api_key"
+
+const (
+	// ApiKeyPersonal keys act as the user who created them.
+	ApiKeyPersonal = "personal"
+	// ApiKeyService keys belong to a service account rather than a person.
+	ApiKeyService = "service"
+)
+
+// ApiKeyPrefix starts every API key, which tells them apart from JWTs.
+const ApiKeyPrefix = "tm_"
+
+const (
+	// ScopeRead allows GET requests.
+	ScopeRead = "read"
+	// ScopeWrite allows every other request.
+	ScopeWrite = "write"
+)
+
+// ServiceUserType is the user_type of requests made with a service key.
+const ServiceUserType = "SERVICE"
+
+const (
+	DefaultApiKeyLifetimeDays = 90
+	MaxApiKeyLifetimeDays     = 365
+)
+
+// ApiKey is a long-lived credential for scripts and CI jobs. Only a hash of
+// the key is stored; the key itself is shown once, when it is created.
+// Personal keys remember the user's token generation, so logging out
+// everywhere revokes them too.
+type ApiKey struct {
+	KeyID      string     `json:"key_id" bson:"key_id"`
+	Kind       string     `json:"kind" bson:"kind"`
+	Name       string     `json:"name" bson:"name"`
+	Prefix     string     `json:"prefix" bson:"prefix"`
+	Hash       string     `json:"-" bson:"hash"`
+	Scopes     []string   `json:"scopes" bson:"scopes"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	Username   string     `json:"username" bson:"username"`
+	UserType   string     `json:"user_type" bson:"user_type"`
+	Generation int        `json:"-" bson:"generation"`
+	CreatedBy  string     `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at" bson:"last_used_at"`
+	RevokedAt  *time.Time `json:"-" bson:"revoked_at"`
+}
+
+func (k *ApiKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && k.ExpiresAt.After(now)
+}
+
+// Allows reports whether the key's scopes cover a request with the method.
+func (k *ApiKey) Allows(method string) bool {
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		return slices.Contains(k.Scopes, ScopeRead) || slices.Contains(k.Scopes, ScopeWrite)
+	}
+	return slices.Contains(k.Scopes, ScopeWrite)
+}
+
+// HashApiKey is how keys are stored and looked up.
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type ApiKeyRequest struct {
+	Name          string   `json:"name" validate:"required,min=1,max=50"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"min=0,max=365"`
+}
+
+// NewApiKey is the response to creating a key, the only one that has Key.
+type NewApiKey struct {
+	*ApiKey
+	Key string `json:"key"`
+}
+
+type ApiKeyRepository interface {
+	Create(ctx context.Context, key *ApiKey) error
+	FetchByHash(ctx context.Context, hash string) (*ApiKey, error)
+	// FetchActive lists the unexpired, unrevoked keys of a kind; userID
+	// narrows the list to one user's keys when it is not empty.
+	FetchActive(ctx context.Context, kind string, userID string) ([]*ApiKey, error)
+	Revoke(ctx context.Context, kind string, userID string, keyID string) error
+	Touch(ctx context.Context, keyID string, usedAt time.Time) error
+}
+
+type ApiKeyUsecase interface {
+	CreatePersonal(ctx context.Context, userID string, username string, userType string, request *ApiKeyRequest) (*NewApiKey, error)
+	CreateService(ctx context.Context, createdBy string, request *ApiKeyRequest) (*NewApiKey, error)
+	FetchPersonal(ctx context.Context, userID string) ([]*ApiKey, error)
+	FetchService(ctx context.Context) ([]*ApiKey, error)
+	RevokePersonal(ctx context.Context, userID string, keyID string) error
+	RevokeService(ctx context.Context, keyID string) error
+}
@@ -0,0 +1,45 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const AuditEventCollection = "audit_event"
+
+const (
+	AuditLoginLockout = "LOGIN_LOCKOUT"
+	AuditLoginUnlock  = "LOGIN_UNLOCK"
+)
+
+// AuditEvent records a security relevant action. ActorID is empty for
+// events the system raised on its own, like a lockout.
+type AuditEvent struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Type       string             `json:"type" bson:"type"`
+	ActorID    string             `json:"actor_id,omitempty" bson:"actor_id"`
+	Subject    string             `json:"subject" bson:"subject"`
+	IP         string             `json:"ip,omitempty" bson:"ip"`
+	Details    map[string]string  `json:"details,omitempty" bson:"details"`
+	OccurredAt time.Time          `json:"occurred_at" bson:"occurred_at"`
+}
+
+type AuditPage struct {
+	Events []*AuditEvent `json:"events"`
+	Page   int64         `json:"page"`
+	Limit  int64         `json:"limit"`
+	Total  int64         `json:"total"`
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, event *AuditEvent) error
+	// FetchPage returns the newest events first; an empty eventType matches
+	// every event.
+	FetchPage(ctx context.Context, eventType string, page int64, limit int64) (*AuditPage, error)
+}
+
+type AuditUsecase interface {
+	FetchPage(ctx context.Context, eventType string, page int64, limit int64) (*AuditPage, error)
+}
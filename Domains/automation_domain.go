@@ -0,0 +1,138 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RuleCollection          = "rule"
+	RuleExecutionCollection = "rule_execution"
+)
+
+// TaskOverdue is the trigger raised by the periodic sweep for open tasks past
+// their due date. The other triggers are the task event types.
+const TaskOverdue = "TASK_OVERDUE"
+
+const (
+	ConditionEq       = "eq"
+	ConditionNeq      = "neq"
+	ConditionIn       = "in"
+	ConditionContains = "contains"
+	ConditionGt       = "gt"
+	ConditionLt       = "lt"
+	ConditionSet      = "set"
+	ConditionUnset    = "unset"
+)
+
+const (
+	ActionSetField = "SET_FIELD"
+	ActionAssign   = "ASSIGN"
+	ActionAddLabel = "ADD_LABEL"
+	ActionComment  = "COMMENT"
+	ActionWebhook  = "WEBHOOK"
+)
+
+const (
+	ActionSucceeded = "OK"
+	ActionFailed    = "FAILED"
+	ActionPlanned   = "PLANNED"
+	// ActionQueued is a webhook that is sent after the run is logged.
+	ActionQueued = "QUEUED"
+)
+
+// RuleCondition compares a task field with a value. Fields prefixed with
+// "previous." read the task as it was before the event.
+type RuleCondition struct {
+	Field  string   `json:"field" bson:"field"`
+	Op     string   `json:"op" bson:"op"`
+	Value  string   `json:"value,omitempty" bson:"value,omitempty"`
+	Values []string `json:"values,omitempty" bson:"values,omitempty"`
+}
+
+// RuleAction is one step a rule performs. Value may use placeholders like
+// {{created_by}} that are filled from the task.
+type RuleAction struct {
+	Type  string `json:"type" bson:"type"`
+	Field string `json:"field,omitempty" bson:"field,omitempty"`
+	Value string `json:"value,omitempty" bson:"value,omitempty"`
+	URL   string `json:"url,omitempty" bson:"url,omitempty"`
+}
+
+// Rule runs its actions when the trigger fires for a task of its project
+// (or any project when ProjectID is empty) and all conditions hold.
+type Rule struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	RuleID     string             `json:"rule_id" bson:"rule_id"`
+	Name       string             `json:"name" bson:"name" validate:"required,min=1,max=50"`
+	ProjectID  string             `json:"project_id" bson:"project_id"`
+	Trigger    string             `json:"trigger" bson:"trigger"`
+	Conditions []RuleCondition    `json:"conditions" bson:"conditions"`
+	Actions    []RuleAction       `json:"actions" bson:"actions"`
+	Enabled    bool               `json:"enabled" bson:"enabled"`
+	CreatedBy  string             `json:"created_by" bson:"created_by"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type ActionResult struct {
+	Type    string `json:"type" bson:"type"`
+	Status  string `json:"status" bson:"status"`
+	Message string `json:"message,omitempty" bson:"message,omitempty"`
+}
+
+// RuleExecution records a rule firing for a task. DueDate is kept for overdue
+// runs so a task only fires once per due date.
+type RuleExecution struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ExecutionID string             `json:"execution_id" bson:"execution_id"`
+	RuleID      string             `json:"rule_id" bson:"rule_id"`
+	TaskID      string             `json:"task_id" bson:"task_id"`
+	Trigger     string             `json:"trigger" bson:"trigger"`
+	DueDate     *time.Time         `json:"due_date,omitempty" bson:"due_date,omitempty"`
+	DryRun      bool               `json:"dry_run" bson:"dry_run"`
+	Matched     bool               `json:"matched" bson:"matched"`
+	Results     []ActionResult     `json:"results" bson:"results"`
+	OccurredAt  time.Time          `json:"occurred_at" bson:"occurred_at"`
+}
+
+type RuleRepository interface {
+	Create(ctx context.Context, rule *Rule) error
+	FetchAll(ctx context.Context, projectID string) ([]*Rule, error)
+	FetchById(ctx context.Context, ruleID string) (*Rule, error)
+	FetchEnabled(ctx context.Context, trigger string) ([]*Rule, error)
+	UpdateById(ctx context.Context, ruleID string, rule *Rule) error
+	DeleteById(ctx context.Context, ruleID string) error
+}
+
+type RuleExecutionRepository interface {
+	Create(ctx context.Context, execution *RuleExecution) error
+	FetchByRule(ctx context.Context, ruleID string, limit int64) ([]*RuleExecution, error)
+	FiredForDueDate(ctx context.Context, ruleID string, taskID string, dueDate time.Time) (bool, error)
+}
+
+type RuleUsecase interface {
+	Create(ctx context.Context, rule *Rule) error
+	FetchAll(ctx context.Context, projectID string) ([]*Rule, error)
+	FetchById(ctx context.Context, ruleID string) (*Rule, error)
+	UpdateById(ctx context.Context, ruleID string, rule *Rule) error
+	DeleteById(ctx context.Context, ruleID string) error
+	DryRun(ctx context.Context, ruleID string, taskID string) (*RuleExecution, error)
+	Executions(ctx context.Context, ruleID string, limit int64) ([]*RuleExecution, error)
+}
+
+// AutomationEngine evaluates rules on task events and on the overdue sweep.
+type AutomationEngine interface {
+	TaskEventHandler
+	SweepOverdue(ctx context.Context)
+}
+
+// WebhookSender posts a JSON payload to an external URL. Webhooks may only
+// reach public addresses.
+type WebhookSender interface {
+	Send(ctx context.Context, url string, payload interface{}) error
+	// CheckURL reports why url cannot be used as a webhook.
+	CheckURL(ctx context.Context, url string) error
+}
@@ -0,0 +1,77 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const BoardCollection = "board"
+
+var (
+	ErrWIPLimitReached = errors.New("column is at its WIP limit")
+	// ErrMoveNotAllowed is returned when someone other than the creator or
+	// the assignee moves a task.
+	ErrMoveNotAllowed = errors.New("only the creator or the assignee can move a task")
+)
+
+// Column groups the tasks of one status; a WIPLimit of zero means no limit.
+type Column struct {
+	ColumnID string `json:"column_id" bson:"column_id"`
+	Name     string `json:"name" bson:"name" validate:"required,min=1,max=30"`
+	Status   string `json:"status" bson:"status" validate:"required"`
+	WIPLimit int    `json:"wip_limit" bson:"wip_limit" validate:"min=0"`
+}
+
+type Board struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BoardID   string             `json:"board_id" bson:"board_id"`
+	Name      string             `json:"name" bson:"name" validate:"required,min=1,max=50"`
+	ProjectID string             `json:"project_id" bson:"project_id"`
+	Columns   []Column           `json:"columns" bson:"columns"`
+	CreatedBy string             `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type BoardColumnView struct {
+	Column Column  `json:"column"`
+	Tasks  []*Task `json:"tasks"`
+}
+
+type BoardView struct {
+	Board   *Board             `json:"board"`
+	Columns []*BoardColumnView `json:"columns"`
+}
+
+// TaskMove places a task in a column, after AfterTaskID and before BeforeTaskID.
+// Leaving both empty appends the task to the end of the column.
+type TaskMove struct {
+	TaskID       string `json:"task_id"`
+	ColumnID     string `json:"column_id"`
+	AfterTaskID  string `json:"after_task_id"`
+	BeforeTaskID string `json:"before_task_id"`
+}
+
+type BoardRepository interface {
+	Create(ctx context.Context, board *Board) error
+	FetchAll(ctx context.Context, projectID string) ([]*Board, error)
+	FetchById(ctx context.Context, boardID string) (*Board, error)
+	UpdateById(ctx context.Context, boardID string, board *Board) error
+	DeleteById(ctx context.Context, boardID string) error
+	// Lock writes to the board inside a transaction, so two transactions
+	// that move tasks on it conflict and one of them is retried.
+	Lock(ctx context.Context, boardID string) error
+}
+
+type BoardUsecase interface {
+	Create(ctx context.Context, board *Board) error
+	FetchAll(ctx context.Context, projectID string) ([]*Board, error)
+	FetchById(ctx context.Context, boardID string) (*Board, error)
+	UpdateById(ctx context.Context, boardID string, board *Board) error
+	DeleteById(ctx context.Context, boardID string) error
+	View(ctx context.Context, boardID string) (*BoardView, error)
+	Move(ctx context.Context, boardID string, userID string, move *TaskMove) (*Task, error)
+}
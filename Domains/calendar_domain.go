@@ -0,0 +1,130 @@
+package domains
+
+import (
+	"context"
+	"slices"
+	"time"
+	// time zones resolve even on hosts without a zoneinfo database
+	_ "time/tzdata"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CalendarCollection = "calendar"
+
+const (
+	CalendarOwnerUser    = "USER"
+	CalendarOwnerProject = "PROJECT"
+)
+
+// Weekdays names the days a calendar can work on, in time.Weekday order.
+var Weekdays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+type Holiday struct {
+	Date string `json:"date" bson:"date"`
+	Name string `json:"name" bson:"name"`
+}
+
+// WorkingCalendar is the time zone, working week and holidays of a user or a
+// project. WorkStart and WorkEnd are local times like "09:00"; holidays are
+// local days like "2025-12-25".
+type WorkingCalendar struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	OwnerType   string             `json:"owner_type" bson:"owner_type"`
+	OwnerID     string             `json:"owner_id" bson:"owner_id"`
+	TimeZone    string             `json:"time_zone" bson:"time_zone"`
+	WorkingDays []string           `json:"working_days" bson:"working_days"`
+	WorkStart   string             `json:"work_start" bson:"work_start"`
+	WorkEnd     string             `json:"work_end" bson:"work_end"`
+	Holidays    []Holiday          `json:"holidays" bson:"holidays"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// DefaultCalendar is used by owners that never stored one: UTC, Monday to
+// Friday, nine to five.
+func DefaultCalendar(ownerType string, ownerID string) *WorkingCalendar {
+	return &WorkingCalendar{
+		OwnerType:   ownerType,
+		OwnerID:     ownerID,
+		TimeZone:    "UTC",
+		WorkingDays: []string{"MON", "TUE", "WED", "THU", "FRI"},
+		WorkStart:   "09:00",
+		WorkEnd:     "17:00",
+		Holidays:    []Holiday{},
+	}
+}
+
+// Location returns the calendar's time zone, or UTC when it is unknown.
+func (c *WorkingCalendar) Location() *time.Location {
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// IsWorkingDay reports whether the local day holding t is worked.
+func (c *WorkingCalendar) IsWorkingDay(t time.Time) bool {
+	local := t.In(c.Location())
+	if !slices.Contains(c.WorkingDays, Weekdays[local.Weekday()]) {
+		return false
+	}
+	day := local.Format(time.DateOnly)
+	for _, holiday := range c.Holidays {
+		if holiday.Date == day {
+			return false
+		}
+	}
+	return true
+}
+
+// AddWorkingDays returns the local day that is n working days after the day
+// holding from. With n = 0 it is that day itself if work has not ended yet,
+// and the next working day otherwise.
+func (c *WorkingCalendar) AddWorkingDays(from time.Time, n int) string {
+	local := from.In(c.Location())
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	if n == 0 {
+		if c.IsWorkingDay(day) && local.Format("15:04") < c.WorkEnd {
+			return day.Format(time.DateOnly)
+		}
+		n = 1
+	}
+	for n > 0 {
+		day = day.AddDate(0, 0, 1)
+		if c.IsWorkingDay(day) {
+			n--
+		}
+	}
+	return day.Format(time.DateOnly)
+}
+
+// EndOfDay returns the last millisecond of the local day, which is when a task
+// due that day becomes overdue.
+func (c *WorkingCalendar) EndOfDay(day string) (time.Time, error) {
+	start, err := time.ParseInLocation(time.DateOnly, day, c.Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.AddDate(0, 0, 1).Add(-time.Millisecond), nil
+}
+
+// DueDateEstimate is a due day computed in working days, with the instant it
+// ends in the calendar's time zone.
+type DueDateEstimate struct {
+	DueDay   string    `json:"due_day"`
+	DueDate  time.Time `json:"due_date"`
+	TimeZone string    `json:"time_zone"`
+}
+
+type CalendarRepository interface {
+	FetchMany(ctx context.Context, ownerType string, ownerIDs []string) (map[string]*WorkingCalendar, error)
+	Upsert(ctx context.Context, calendar *WorkingCalendar) error
+}
+
+type CalendarUsecase interface {
+	Fetch(ctx context.Context, ownerType string, ownerID string) (*WorkingCalendar, error)
+	Update(ctx context.Context, calendar *WorkingCalendar) error
+	DueIn(ctx context.Context, userID string, projectID string, workingDays int, from time.Time) (*DueDateEstimate, error)
+	Location(ctx context.Context, userID string, override string) (*time.Location, error)
+}
@@ -0,0 +1,37 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+// ChecklistItem is a small step inside a task. Items are kept sorted by Rank,
+// which uses the same lexicographic scheme as board ranks.
+type ChecklistItem struct {
+	ItemID    string     `json:"item_id" bson:"item_id"`
+	Text      string     `json:"text" bson:"text" validate:"required,min=1,max=200"`
+	Done      bool       `json:"done" bson:"done"`
+	Rank      string     `json:"rank" bson:"rank"`
+	DoneBy    string     `json:"done_by,omitempty" bson:"done_by,omitempty"`
+	DoneAt    *time.Time `json:"done_at,omitempty" bson:"done_at,omitempty"`
+	CreatedBy string     `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+}
+
+// ChecklistItemPatch holds the fields of an item to change; nil fields are left untouched.
+type ChecklistItemPatch struct {
+	Text *string `json:"text"`
+	Done *bool   `json:"done"`
+}
+
+type ChecklistItemMove struct {
+	AfterItemID  string `json:"after_item_id"`
+	BeforeItemID string `json:"before_item_id"`
+}
+
+type ChecklistUsecase interface {
+	AddItem(ctx context.Context, taskID string, userID string, text string) (*ChecklistItem, error)
+	UpdateItem(ctx context.Context, taskID string, itemID string, userID string, patch *ChecklistItemPatch) error
+	MoveItem(ctx context.Context, taskID string, itemID string, userID string, move *ChecklistItemMove) error
+	RemoveItem(ctx context.Context, taskID string, itemID string, userID string) error
+}
@@ -0,0 +1,49 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CommentCollection = "comment"
+
+const CommentCreated = "COMMENT_CREATED"
+
+// Comment is a note left on a task. RuleID is set when an automation rule
+// wrote the comment on behalf of its author.
+type Comment struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CommentID string             `json:"comment_id" bson:"comment_id"`
+	TaskID    string             `json:"task_id" bson:"task_id"`
+	AuthorID  string             `json:"author_id" bson:"author_id"`
+	Body      string             `json:"body" bson:"body" validate:"required,min=1,max=5000"`
+	RuleID    string             `json:"rule_id,omitempty" bson:"rule_id,omitempty"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// CommentEvent describes a comment written by a user.
+type CommentEvent struct {
+	Type       string
+	Comment    *Comment
+	OccurredAt time.Time
+}
+
+// CommentEventHandler reacts to comments after they have been stored.
+type CommentEventHandler interface {
+	HandleCommentEvent(ctx context.Context, event *CommentEvent)
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *Comment) error
+	FetchByTask(ctx context.Context, taskID string) ([]*Comment, error)
+	FetchById(ctx context.Context, commentID string) (*Comment, error)
+	DeleteById(ctx context.Context, commentID string) error
+}
+
+type CommentUsecase interface {
+	Create(ctx context.Context, comment *Comment) error
+	FetchByTask(ctx context.Context, taskID string) ([]*Comment, error)
+	DeleteById(ctx context.Context, commentID string, userID string) error
+}
@@ -0,0 +1,71 @@
+package domains
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CustomFieldCollection = "custom_field"
+
+const (
+	CustomFieldText   = "TEXT"
+	CustomFieldNumber = "NUMBER"
+	CustomFieldDate   = "DATE"
+	CustomFieldEnum   = "ENUM"
+	CustomFieldUser   = "USER"
+)
+
+const (
+	CustomFieldEq  = "eq"
+	CustomFieldGte = "gte"
+	CustomFieldLte = "lte"
+)
+
+// CustomFieldSortPrefix marks a custom field in the sort parameter, e.g. "cf.severity".
+const CustomFieldSortPrefix = "cf."
+
+var CustomFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+// CustomField defines a piece of project specific metadata. Tasks store the
+// values under custom_fields.<key>; the key and type cannot change once the
+// field exists.
+type CustomField struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	FieldID   string             `json:"field_id" bson:"field_id"`
+	ProjectID string             `json:"project_id" bson:"project_id" validate:"required"`
+	Key       string             `json:"key" bson:"key" validate:"required"`
+	Name      string             `json:"name" bson:"name" validate:"required,min=1,max=50"`
+	Type      string             `json:"type" bson:"type" validate:"eq=TEXT|eq=NUMBER|eq=DATE|eq=ENUM|eq=USER"`
+	Options   []string           `json:"options,omitempty" bson:"options,omitempty"`
+	CreatedBy string             `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CustomFieldCondition filters tasks on a custom field. Values are kept as
+// sent so saved views can store them.
+type CustomFieldCondition struct {
+	Key   string `json:"key" bson:"key"`
+	Op    string `json:"op" bson:"op"`
+	Value string `json:"value" bson:"value"`
+}
+
+type CustomFieldRepository interface {
+	Create(ctx context.Context, field *CustomField) error
+	FetchAll(ctx context.Context, projectID string) ([]*CustomField, error)
+	FetchById(ctx context.Context, fieldID string) (*CustomField, error)
+	UpdateById(ctx context.Context, fieldID string, field *CustomField) error
+	DeleteById(ctx context.Context, fieldID string) error
+}
+
+type CustomFieldUsecase interface {
+	Create(ctx context.Context, field *CustomField) error
+	FetchAll(ctx context.Context, projectID string) ([]*CustomField, error)
+	FetchById(ctx context.Context, fieldID string) (*CustomField, error)
+	UpdateById(ctx context.Context, fieldID string, field *CustomField) error
+	DeleteById(ctx context.Context, fieldID string) error
+	SetValues(ctx context.Context, taskID string, userID string, values map[string]interface{}) error
+}
@@ -0,0 +1,58 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const EmailVerificationCollection = "email_verification"
+
+const (
+	EmailVerificationLifetime = 24 * time.Hour
+	// EmailVerificationCooldown is the least time between two verification
+	// emails to the same user.
+	EmailVerificationCooldown = time.Minute
+)
+
+// EmailVerification is a single-use token that proves the user owns Email.
+// Only a hash of the token is stored.
+type EmailVerification struct {
+	TokenHash string     `bson:"token_hash"`
+	UserID    string     `bson:"user_id"`
+	Email     string     `bson:"email"`
+	CreatedAt time.Time  `bson:"created_at"`
+	ExpiresAt time.Time  `bson:"expires_at"`
+	UsedAt    *time.Time `bson:"used_at"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// EmailVerificationNotifier delivers a verification token to the address
+// that is to be verified, which is the user's current email.
+type EmailVerificationNotifier interface {
+	NotifyEmailVerification(ctx context.Context, user *User, token string, expiresAt time.Time) error
+}
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, verification *EmailVerification) error
+	// Consume marks the token as used and returns it, or nil when it is
+	// unknown, used or expired.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*EmailVerification, error)
+	// RevokeUser ends every unused token of the user.
+	RevokeUser(ctx context.Context, userID string, now time.Time) error
+	CountSince(ctx context.Context, userID string, since time.Time) (int64, error)
+}
+
+// EmailUsecase changes and verifies the email of a user. Password resets are
+// only sent to verified addresses.
+type EmailUsecase interface {
+	// Change sets a new, unverified email after checking the current
+	// password, and sends a verification to the new address. An empty
+	// email removes the address.
+	Change(ctx context.Context, userID string, email string, currentPassword string) error
+	// SendVerification sends a new token to the user's unverified email.
+	SendVerification(ctx context.Context, userID string) error
+	Verify(ctx context.Context, token string) error
+}
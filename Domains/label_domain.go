@@ -0,0 +1,61 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const LabelCollection = "label"
+
+const (
+	LabelScopeGlobal  = "GLOBAL"
+	LabelScopeProject = "PROJECT"
+)
+
+const (
+	LabelMatchAny = "any"
+	LabelMatchAll = "all"
+)
+
+// Label names are unique across scopes; tasks reference labels by name.
+type Label struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	LabelID   string             `json:"label_id" bson:"label_id"`
+	Name      string             `json:"name" bson:"name" validate:"required,min=1,max=30"`
+	Color     string             `json:"color" bson:"color" validate:"hexcolor"`
+	Scope     string             `json:"scope" bson:"scope" validate:"eq=GLOBAL|eq=PROJECT"`
+	ProjectID string             `json:"project_id" bson:"project_id"`
+	CreatedBy string             `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type LabelUsage struct {
+	Label *Label `json:"label"`
+	Count int64  `json:"count"`
+}
+
+type LabelRepository interface {
+	Create(ctx context.Context, label *Label) error
+	FetchAll(ctx context.Context, projectID string) ([]*Label, error)
+	FetchById(ctx context.Context, labelID string) (*Label, error)
+	FetchByName(ctx context.Context, name string) (*Label, error)
+	UpdateById(ctx context.Context, labelID string, label *Label) error
+	DeleteById(ctx context.Context, labelID string) error
+	UsageCounts(ctx context.Context) (map[string]int64, error)
+	Merge(ctx context.Context, sourceID string, targetID string) error
+}
+
+type LabelUsecase interface {
+	Create(ctx context.Context, label *Label) error
+	FetchAll(ctx context.Context, projectID string) ([]*Label, error)
+	FetchById(ctx context.Context, labelID string) (*Label, error)
+	UpdateById(ctx context.Context, labelID string, label *Label) error
+	DeleteById(ctx context.Context, labelID string) error
+	Usage(ctx context.Context, projectID string) ([]*LabelUsage, error)
+	Merge(ctx context.Context, sourceID string, targetID string) error
+	Attach(ctx context.Context, taskID string, userID string, labelName string) error
+	Detach(ctx context.Context, taskID string, userID string, labelName string) error
+}
@@ -0,0 +1,128 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const LoginAttemptCollection = "login_attempt"
+
+const (
+	// LoginScopeUsername counts the failures for one username, whichever
+	// address they come from.
+	LoginScopeUsername = "username"
+	// LoginScopeIP counts the failures from one address, whichever
+	// usernames it tries.
+	LoginScopeIP = "ip"
+)
+
+const (
+	DefaultMaxLoginFailures   = 10
+	DefaultMaxIPLoginFailures = 100
+	DefaultLoginLockout       = 15 * time.Minute
+)
+
+// LoginLimit is how many failures a scope gets before it has to wait between
+// attempts and before it is locked out.
+type LoginLimit struct {
+	FreeFailures int
+	MaxFailures  int
+}
+
+// LoginPolicy decides how failed logins slow down and lock out. Failures are
+// forgotten once none happened for Window.
+type LoginPolicy struct {
+	Username  LoginLimit
+	IP        LoginLimit
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	Lockout   time.Duration
+	Window    time.Duration
+}
+
+// Limit returns the limit of the scope.
+func (lp *LoginPolicy) Limit(scope string) LoginLimit {
+	if scope == LoginScopeIP {
+		return lp.IP
+	}
+	return lp.Username
+}
+
+// Delay is how long the scope waits after its failures-th failure. It
+// doubles with every failure after the free ones, up to MaxDelay.
+func (lp *LoginPolicy) Delay(scope string, failures int) time.Duration {
+	extra := failures - lp.Limit(scope).FreeFailures
+	if extra <= 0 {
+		return 0
+	}
+	delay := lp.BaseDelay
+	for i := 1; i < extra && delay < lp.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, lp.MaxDelay)
+}
+
+// LoginAttempt tracks the recent failed logins of a username or an address.
+// Neither may try again before RetryAt; LockedUntil is set while a lockout
+// lasts.
+type LoginAttempt struct {
+	Scope          string     `json:"scope" bson:"scope"`
+	Value          string     `json:"value" bson:"value"`
+	Failures       int        `json:"failures" bson:"failures"`
+	FirstFailureAt time.Time  `json:"first_failure_at" bson:"first_failure_at"`
+	LastFailureAt  time.Time  `json:"last_failure_at" bson:"last_failure_at"`
+	RetryAt        time.Time  `json:"retry_at" bson:"retry_at"`
+	LockedUntil    *time.Time `json:"locked_until" bson:"locked_until"`
+	ExpiresAt      time.Time  `json:"-" bson:"expires_at"`
+}
+
+// Locked reports whether a lockout is in force.
+func (la *LoginAttempt) Locked(now time.Time) bool {
+	return la.LockedUntil != nil && la.LockedUntil.After(now)
+}
+
+// LoginBlock tells a client that it has to wait before its next login.
+type LoginBlock struct {
+	Scope      string
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+// LoginAttemptStore keeps the failed login counters. The in-memory store
+// suits a single instance; every instance behind a load balancer has to
+// share the mongo one.
+type LoginAttemptStore interface {
+	// Fetch returns nil when the scope has no failures on record.
+	Fetch(ctx context.Context, scope string, value string) (*LoginAttempt, error)
+	// Fail counts a failure and returns the record after it. A record whose
+	// last failure is older than window and that is not blocked starts over.
+	Fail(ctx context.Context, scope string, value string, now time.Time, window time.Duration) (*LoginAttempt, error)
+	// Refund takes back one failure, for an attempt that was counted before
+	// it turned out not to fail.
+	Refund(ctx context.Context, scope string, value string) error
+	// Block keeps the scope from trying again before until and, when locked
+	// is set, marks it as locked out. The record is kept for window after.
+	Block(ctx context.Context, scope string, value string, until time.Time, locked bool, window time.Duration) error
+	// Reserve moves the retry time of the scope to until if it is not after
+	// now, and reports false when another attempt got there first or the
+	// record is gone. Only one login gets through per wait, also when
+	// guesses come in concurrently.
+	Reserve(ctx context.Context, scope string, value string, now time.Time, until time.Time, window time.Duration) (bool, error)
+	FetchLocked(ctx context.Context, now time.Time) ([]*LoginAttempt, error)
+	// Reset forgets the failures and reports false when there were none.
+	Reset(ctx context.Context, scope string, value string) (bool, error)
+}
+
+type LoginThrottleUsecase interface {
+	// Check returns nil when the username may try to log in from ip now.
+	Check(ctx context.Context, username string, ip string) (*LoginBlock, error)
+	// Fail applies the wait a wrong password causes and returns it, if any.
+	// Check has already counted the attempt.
+	Fail(ctx context.Context, username string, ip string) (*LoginBlock, error)
+	// Succeed clears the username's failures and takes back the attempt
+	// Check counted against the address. The address keeps its other
+	// failures, so one known password cannot be used to reset them.
+	Succeed(ctx context.Context, username string, ip string) error
+	Locked(ctx context.Context) ([]*LoginAttempt, error)
+	Unlock(ctx context.Context, scope string, value string, actorID string, ip string) error
+}
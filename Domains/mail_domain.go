@@ -0,0 +1,60 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const EmailLogCollection = "email_log"
+
+const (
+	EmailAssignment  = "ASSIGNMENT"
+	EmailMention     = "MENTION"
+	EmailDueReminder = "DUE_REMINDER"
+	EmailDigest      = "DIGEST"
+)
+
+// EmailPasswordReset and EmailAddressVerification are always sent; users
+// cannot opt out of them.
+const (
+	EmailPasswordReset       = "PASSWORD_RESET"
+	EmailAddressVerification = "EMAIL_VERIFICATION"
+)
+
+// EmailTypes lists the emails users can opt out of.
+var EmailTypes = map[string]bool{
+	EmailAssignment:  true,
+	EmailMention:     true,
+	EmailDueReminder: true,
+	EmailDigest:      true,
+}
+
+type MailMessage struct {
+	To       []string `json:"to"`
+	Subject  string   `json:"subject"`
+	TextBody string   `json:"text_body"`
+	HTMLBody string   `json:"html_body"`
+}
+
+// MailSender delivers a message to its recipients.
+type MailSender interface {
+	Send(message *MailMessage) error
+}
+
+// EmailLogRepository remembers which emails went out so scheduled mail is
+// sent once. MarkSent reports false when the email was already recorded.
+type EmailLogRepository interface {
+	MarkSent(ctx context.Context, kind string, userID string, key string) (bool, error)
+}
+
+// EmailNotifier emails assignments and mentions as they happen.
+type EmailNotifier interface {
+	TaskEventHandler
+	CommentEventHandler
+}
+
+type MailUsecase interface {
+	SendDueReminders(ctx context.Context, now time.Time)
+	SendDailyDigests(ctx context.Context, now time.Time)
+	PreviewDigest(ctx context.Context, userID string, now time.Time) (*MailMessage, error)
+}
@@ -0,0 +1,96 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	NotificationCollection           = "notification"
+	NotificationPreferenceCollection = "notification_preference"
+)
+
+const (
+	NotificationTaskAssigned      = "TASK_ASSIGNED"
+	NotificationTaskUpdated       = "TASK_UPDATED"
+	NotificationTaskStatusChanged = "TASK_STATUS_CHANGED"
+	NotificationTaskDeleted       = "TASK_DELETED"
+	NotificationCommentAdded      = "COMMENT_ADDED"
+	NotificationMentioned         = "MENTIONED"
+)
+
+// NotificationTypes lists the types users can switch off in their preferences.
+var NotificationTypes = map[string]bool{
+	NotificationTaskAssigned:      true,
+	NotificationTaskUpdated:       true,
+	NotificationTaskStatusChanged: true,
+	NotificationTaskDeleted:       true,
+	NotificationCommentAdded:      true,
+	NotificationMentioned:         true,
+}
+
+type Notification struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	NotificationID string             `json:"notification_id" bson:"notification_id"`
+	UserID         string             `json:"user_id" bson:"user_id"`
+	Type           string             `json:"type" bson:"type"`
+	TaskID         string             `json:"task_id" bson:"task_id"`
+	CommentID      string             `json:"comment_id,omitempty" bson:"comment_id,omitempty"`
+	ActorID        string             `json:"actor_id" bson:"actor_id"`
+	Message        string             `json:"message" bson:"message"`
+	Read           bool               `json:"read" bson:"read"`
+	ReadAt         *time.Time         `json:"read_at,omitempty" bson:"read_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int64           `json:"unread_count"`
+	Page          int64           `json:"page"`
+	Limit         int64           `json:"limit"`
+	Total         int64           `json:"total"`
+}
+
+// NotificationPreferences holds a user's choices. Users without stored
+// preferences get every notification and email and auto-watch the tasks they
+// create or are assigned.
+type NotificationPreferences struct {
+	UserID           string    `json:"user_id" bson:"user_id"`
+	Disabled         []string  `json:"disabled" bson:"disabled"`
+	EmailDisabled    []string  `json:"email_disabled" bson:"email_disabled"`
+	DisableAutoWatch bool      `json:"disable_auto_watch" bson:"disable_auto_watch"`
+	UpdatedAt        time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+type NotificationRepository interface {
+	CreateMany(ctx context.Context, notifications []*Notification) error
+	FetchPage(ctx context.Context, userID string, unreadOnly bool, page int64, limit int64) (*NotificationPage, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID string, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+}
+
+type NotificationPreferenceRepository interface {
+	Fetch(ctx context.Context, userID string) (*NotificationPreferences, error)
+	FetchMany(ctx context.Context, userIDs []string) (map[string]*NotificationPreferences, error)
+	Upsert(ctx context.Context, preferences *NotificationPreferences) error
+}
+
+// NotificationDispatcher creates notifications from task and comment events.
+type NotificationDispatcher interface {
+	TaskEventHandler
+	CommentEventHandler
+}
+
+type NotificationUsecase interface {
+	FetchPage(ctx context.Context, userID string, unreadOnly bool, page int64, limit int64) (*NotificationPage, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID string, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) (int64, error)
+	FetchPreferences(ctx context.Context, userID string) (*NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, preferences *NotificationPreferences) error
+	Watch(ctx context.Context, taskID string, userID string) error
+	Unwatch(ctx context.Context, taskID string, userID string) error
+}
@@ -0,0 +1,112 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	OIDCStateCollection        = "oidc_state"
+	ExternalIdentityCollection = "external_identity"
+)
+
+// OIDCStateLifetime is how long a user has to finish logging in at the
+// identity provider.
+const OIDCStateLifetime = 10 * time.Minute
+
+// OIDCProviderConfig describes one OpenID Connect identity provider. Users
+// get the highest role any of their groups maps to in GroupRoles, otherwise
+// DefaultRole; with no DefaultRole users without a mapped group are refused.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	GroupRoles   map[string]string
+	DefaultRole  string
+}
+
+// Role maps the provider's groups to a user type.
+func (p *OIDCProviderConfig) Role(groups []string) (string, bool) {
+	role := p.DefaultRole
+	for _, group := range groups {
+		switch p.GroupRoles[group] {
+		case "ADMIN":
+			return "ADMIN", true
+		case "USER":
+			role = "USER"
+		}
+	}
+	return role, role != ""
+}
+
+// OIDCLoginState is kept between sending the browser to the provider and
+// the provider sending it back. It is used once.
+type OIDCLoginState struct {
+	State        string    `bson:"state"`
+	Provider     string    `bson:"provider"`
+	CodeVerifier string    `bson:"code_verifier"`
+	Nonce        string    `bson:"nonce"`
+	CreatedAt    time.Time `bson:"created_at"`
+	ExpiresAt    time.Time `bson:"expires_at"`
+}
+
+// OIDCIdentity is what a provider tells about the user who logged in.
+type OIDCIdentity struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	PreferredUsername string
+	GivenName         string
+	FamilyName        string
+	Name              string
+	Groups            []string
+}
+
+// ExternalIdentity links a provider account to a user. The issuer and
+// subject identify the account; the email may change at the provider.
+type ExternalIdentity struct {
+	Provider    string    `json:"provider" bson:"provider"`
+	Issuer      string    `json:"issuer" bson:"issuer"`
+	Subject     string    `json:"subject" bson:"subject"`
+	UserID      string    `json:"user_id" bson:"user_id"`
+	Email       string    `json:"email" bson:"email"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at" bson:"last_login_at"`
+}
+
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *OIDCLoginState) error
+	// Consume returns the state and deletes it.
+	Consume(ctx context.Context, state string) (*OIDCLoginState, error)
+}
+
+type ExternalIdentityRepository interface {
+	Create(ctx context.Context, identity *ExternalIdentity) error
+	// FetchBySubject returns nil when the account never logged in before.
+	FetchBySubject(ctx context.Context, issuer string, subject string) (*ExternalIdentity, error)
+	Touch(ctx context.Context, issuer string, subject string, email string, loginAt time.Time) error
+}
+
+// OIDCProvider runs the authorization code flow against one provider.
+type OIDCProvider interface {
+	Config() *OIDCProviderConfig
+	// AuthorizationURL is where the browser logs in. The challenge is the
+	// PKCE S256 challenge of the code verifier.
+	AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	// Exchange redeems the code and returns the user of the verified ID token.
+	Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*OIDCIdentity, error)
+}
+
+type OIDCUsecase interface {
+	Providers() []string
+	// Begin returns the provider URL to send the browser to.
+	Begin(ctx context.Context, provider string) (string, error)
+	// Complete finishes a login. A user is created the first time an
+	// account logs in, and the user's role follows their provider groups.
+	Complete(ctx context.Context, provider string, state string, code string) (*User, error)
+}
@@ -0,0 +1,67 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const PasswordResetCollection = "password_reset"
+
+const (
+	PasswordResetLifetime = time.Hour
+	// PasswordResetCooldown is the least time between two reset emails to
+	// the same user.
+	PasswordResetCooldown = time.Minute
+	MinPasswordLength     = 8
+	// MaxPasswordLength is where bcrypt stops reading.
+	MaxPasswordLength = 72
+)
+
+// PasswordReset is a single-use token that sets a new password. Only a hash
+// of the token is stored.
+type PasswordReset struct {
+	TokenHash string     `bson:"token_hash"`
+	UserID    string     `bson:"user_id"`
+	CreatedAt time.Time  `bson:"created_at"`
+	ExpiresAt time.Time  `bson:"expires_at"`
+	UsedAt    *time.Time `bson:"used_at"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ForgotPasswordRequest struct {
+	Username string `json:"username"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// PasswordResetNotifier delivers a reset token to the user it was issued
+// for, by email or any other channel the user can prove to own.
+type PasswordResetNotifier interface {
+	NotifyPasswordReset(ctx context.Context, user *User, token string, expiresAt time.Time) error
+}
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, reset *PasswordReset) error
+	// Consume marks the token as used and returns it, or nil when it is
+	// unknown, used or expired. A token can only be consumed once, also by
+	// concurrent requests.
+	Consume(ctx context.Context, tokenHash string, now time.Time) (*PasswordReset, error)
+	// RevokeUser ends every unused token of the user.
+	RevokeUser(ctx context.Context, userID string, now time.Time) error
+	CountSince(ctx context.Context, userID string, since time.Time) (int64, error)
+}
+
+type PasswordUsecase interface {
+	Change(ctx context.Context, userID string, request *ChangePasswordRequest) error
+	// Forgot sends a reset token when the username exists. It never tells
+	// whether it did, so it cannot be used to look for usernames.
+	Forgot(ctx context.Context, username string) error
+	Reset(ctx context.Context, request *ResetPasswordRequest) error
+}
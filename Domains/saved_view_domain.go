@@ -0,0 +1,72 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const SavedViewCollection = "saved_view"
+
+const (
+	SavedViewPrivate = "PRIVATE"
+	SavedViewProject = "PROJECT"
+)
+
+// TaskColumns lists the task fields a saved view may display.
+var TaskColumns = map[string]bool{
+	"task_id":          true,
+	"title":            true,
+	"description":      true,
+	"status":           true,
+	"start_date":       true,
+	"due_date":         true,
+	"created_at":       true,
+	"updated_at":       true,
+	"created_by":       true,
+	"updated_by":       true,
+	"project_id":       true,
+	"labels":           true,
+	"assignee_id":      true,
+	"priority":         true,
+	"story_points":     true,
+	"estimate_minutes": true,
+	"sprint_id":        true,
+	"completed_at":     true,
+	"parent_id":        true,
+	"checklist":        true,
+	"custom_fields":    true,
+	"watchers":         true,
+}
+
+type SavedView struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ViewID     string             `json:"view_id" bson:"view_id"`
+	Name       string             `json:"name" bson:"name" validate:"required,min=1,max=50"`
+	OwnerID    string             `json:"owner_id" bson:"owner_id"`
+	ProjectID  string             `json:"project_id" bson:"project_id"`
+	Visibility string             `json:"visibility" bson:"visibility" validate:"eq=PRIVATE|eq=PROJECT"`
+	Filter     TaskFilter         `json:"filter" bson:"filter"`
+	Sort       TaskSort           `json:"sort" bson:"sort"`
+	Columns    []string           `json:"columns" bson:"columns"`
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type SavedViewRepository interface {
+	Create(ctx context.Context, view *SavedView) error
+	FetchVisible(ctx context.Context, userID string, projectID string) ([]*SavedView, error)
+	FetchById(ctx context.Context, viewID string) (*SavedView, error)
+	UpdateById(ctx context.Context, viewID string, userID string, view *SavedView) error
+	DeleteById(ctx context.Context, viewID string, userID string) error
+}
+
+type SavedViewUsecase interface {
+	Create(ctx context.Context, view *SavedView) error
+	FetchVisible(ctx context.Context, userID string, projectID string) ([]*SavedView, error)
+	FetchById(ctx context.Context, viewID string, userID string) (*SavedView, error)
+	UpdateById(ctx context.Context, viewID string, userID string, view *SavedView) error
+	DeleteById(ctx context.Context, viewID string, userID string) error
+	Execute(ctx context.Context, viewID string, userID string, page int64, limit int64) (*TaskPage, error)
+}
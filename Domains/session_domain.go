@@ -0,0 +1,49 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const SessionCollection = "session"
+
+// Session is one login on one device. Its SessionID is the family ID that
+// every token minted from the login carries, so ending the session ends
+// those tokens.
+type Session struct {
+	SessionID  string     `json:"session_id" bson:"session_id"`
+	UserID     string     `json:"user_id" bson:"user_id"`
+	UserAgent  string     `json:"user_agent" bson:"user_agent"`
+	IP         string     `json:"ip" bson:"ip"`
+	Current    bool       `json:"current" bson:"-"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" bson:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at"`
+	RevokedAt  *time.Time `json:"-" bson:"revoked_at"`
+}
+
+// Active reports whether tokens of the session may still be used.
+func (s *Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && s.ExpiresAt.After(now)
+}
+
+// SessionClient describes the device a login comes from.
+type SessionClient struct {
+	UserAgent string
+	IP        string
+}
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *Session) error
+	FetchById(ctx context.Context, sessionID string) (*Session, error)
+	FetchActive(ctx context.Context, userID string) ([]*Session, error)
+	// Touch records activity; expiresAt is only moved when it is not zero.
+	Touch(ctx context.Context, sessionID string, ip string, seenAt time.Time, expiresAt time.Time) error
+	Revoke(ctx context.Context, userID string, sessionID string) error
+	RevokeUser(ctx context.Context, userID string) error
+}
+
+type SessionUsecase interface {
+	FetchActive(ctx context.Context, userID string, currentID string) ([]*Session, error)
+	Revoke(ctx context.Context, userID string, sessionID string) error
+}
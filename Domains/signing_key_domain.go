@@ -0,0 +1,63 @@
+package domains
+
+import (
+	"context"
+	"crypto"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const SigningKeyCollection = "signing_key"
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is one key of the key ring. New tokens are signed with the
+// newest key; a key keeps verifying tokens until ExpiresAt, which is past
+// the lifetime of the last token it may have signed.
+type SigningKey struct {
+	KeyID       string    `json:"kid" bson:"key_id"`
+	Algorithm   string    `json:"alg" bson:"algorithm"`
+	PrivateKey  string    `json:"-" bson:"private_key"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`
+	ActiveUntil time.Time `json:"active_until" bson:"active_until"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+// JSONWebKey is the public half of a signing key as published in the JWKS
+// (RFC 7517). N and E are set for RSA keys, Crv and X for Ed25519 keys; Y
+// is only set on the EC keys of identity providers.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *SigningKey) error
+	// FetchValid returns the keys that have not expired, newest first.
+	FetchValid(ctx context.Context) ([]*SigningKey, error)
+}
+
+// KeyRing holds the keys tokens are signed and verified with.
+type KeyRing interface {
+	SigningKey() (keyID string, method jwt.SigningMethod, key crypto.PrivateKey, err error)
+	VerificationKey(keyID string) (method jwt.SigningMethod, key crypto.PublicKey, err error)
+	// Rotate adds a new key when the newest one is due for replacement and
+	// picks up keys added by other instances.
+	Rotate(ctx context.Context) error
+	JWKS() *JSONWebKeySet
+}
@@ -0,0 +1,98 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const SprintCollection = "sprint"
+
+// ErrSprintChangeNotAllowed is returned when someone other than the creator
+// of a sprint or an ADMIN changes it.
+var ErrSprintChangeNotAllowed = errors.New("only the creator of the sprint or an ADMIN can change it")
+
+const (
+	SprintPlanned = "PLANNED"
+	SprintActive  = "ACTIVE"
+	SprintClosed  = "CLOSED"
+)
+
+// Sprint is a time-boxed iteration of a project. TaskIDs and CarriedOver are
+// recorded when the sprint closes, since unfinished tasks leave it at that point.
+type Sprint struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SprintID    string             `json:"sprint_id" bson:"sprint_id"`
+	ProjectID   string             `json:"project_id" bson:"project_id" validate:"required"`
+	Name        string             `json:"name" bson:"name" validate:"required,min=1,max=50"`
+	Goal        string             `json:"goal" bson:"goal" validate:"max=200"`
+	StartDate   time.Time          `json:"start_date" bson:"start_date"`
+	EndDate     time.Time          `json:"end_date" bson:"end_date"`
+	Status      string             `json:"status" bson:"status"`
+	TaskIDs     []string           `json:"task_ids,omitempty" bson:"task_ids,omitempty"`
+	CarriedOver []string           `json:"carried_over,omitempty" bson:"carried_over,omitempty"`
+	StartedAt   *time.Time         `json:"started_at" bson:"started_at"`
+	ClosedAt    *time.Time         `json:"closed_at" bson:"closed_at"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// In converts the sprint's timestamps to loc for display. Unset dates are
+// left zero.
+func (s *Sprint) In(loc *time.Location) {
+	for _, date := range []*time.Time{&s.StartDate, &s.EndDate, &s.CreatedAt, &s.UpdatedAt} {
+		if !date.IsZero() {
+			*date = date.In(loc)
+		}
+	}
+	if s.StartedAt != nil {
+		startedAt := s.StartedAt.In(loc)
+		s.StartedAt = &startedAt
+	}
+	if s.ClosedAt != nil {
+		closedAt := s.ClosedAt.In(loc)
+		s.ClosedAt = &closedAt
+	}
+}
+
+type BurndownPoint struct {
+	Day              string  `json:"day"`
+	ScopePoints      float64 `json:"scope_points"`
+	RemainingPoints  float64 `json:"remaining_points"`
+	CompletedPoints  float64 `json:"completed_points"`
+	IdealPoints      float64 `json:"ideal_points"`
+	RemainingMinutes int     `json:"remaining_minutes"`
+}
+
+type Burndown struct {
+	Sprint *Sprint          `json:"sprint"`
+	Points []*BurndownPoint `json:"points"`
+}
+
+type SprintRepository interface {
+	Create(ctx context.Context, sprint *Sprint) error
+	FetchAll(ctx context.Context, projectID string) ([]*Sprint, error)
+	FetchById(ctx context.Context, sprintID string) (*Sprint, error)
+	FetchActive(ctx context.Context, projectID string) (*Sprint, error)
+	UpdateById(ctx context.Context, sprintID string, sprint *Sprint) error
+	// Start makes a planned sprint the active one of its project. It fails
+	// when the sprint is no longer planned or another sprint is active.
+	Start(ctx context.Context, sprintID string, startedAt time.Time) error
+	DeleteById(ctx context.Context, sprintID string) error
+}
+
+type SprintUsecase interface {
+	Create(ctx context.Context, sprint *Sprint) error
+	FetchAll(ctx context.Context, projectID string) ([]*Sprint, error)
+	FetchById(ctx context.Context, sprintID string) (*Sprint, error)
+	UpdateById(ctx context.Context, sprintID string, userID string, userType string, sprint *Sprint) error
+	DeleteById(ctx context.Context, sprintID string, userID string, userType string) error
+	Plan(ctx context.Context, sprintID string, userID string, userType string, taskIDs []string) error
+	Unplan(ctx context.Context, sprintID string, userID string, userType string, taskID string) error
+	Start(ctx context.Context, sprintID string, userID string, userType string) (*Sprint, error)
+	Close(ctx context.Context, sprintID string, userID string, userType string, carryOverTo string) (*Sprint, error)
+	Burndown(ctx context.Context, sprintID string) (*Burndown, error)
+}
@@ -0,0 +1,76 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+// StatsFilter narrows the statistics to a project and a time range. The range
+// applies to the date each statistic is about: created_at for counts,
+// completed_at for cycle and lead times, and both for throughput.
+type StatsFilter struct {
+	ProjectID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// CountBucket is the number of tasks sharing a value. An empty key stands for
+// tasks without the value, like unassigned tasks.
+type CountBucket struct {
+	Key   string `json:"key" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
+
+// ThroughputWeek counts the tasks created and completed in the week starting
+// on Monday WeekStart (UTC).
+type ThroughputWeek struct {
+	WeekStart time.Time `json:"week_start" bson:"_id"`
+	Created   int64     `json:"created" bson:"created"`
+	Completed int64     `json:"completed" bson:"completed"`
+}
+
+// DurationStats summarises how long completed tasks took. Tasks holds how
+// many tasks the averages are over.
+type DurationStats struct {
+	Tasks        int64   `json:"tasks" bson:"tasks"`
+	AverageHours float64 `json:"average_hours" bson:"average_hours"`
+	MinHours     float64 `json:"min_hours" bson:"min_hours"`
+	MaxHours     float64 `json:"max_hours" bson:"max_hours"`
+}
+
+// FlowStats holds the cycle time, from the task first moving to IN_PROGRESS
+// until it was completed, and the lead time, from creation until completion.
+type FlowStats struct {
+	CycleTime DurationStats `json:"cycle_time" bson:"cycle_time"`
+	LeadTime  DurationStats `json:"lead_time" bson:"lead_time"`
+}
+
+type OverdueStats struct {
+	Overdue    int64          `json:"overdue"`
+	ByAssignee []*CountBucket `json:"by_assignee"`
+}
+
+type StatsSummary struct {
+	Total      int64             `json:"total"`
+	ByStatus   []*CountBucket    `json:"by_status"`
+	ByAssignee []*CountBucket    `json:"by_assignee"`
+	ByProject  []*CountBucket    `json:"by_project"`
+	Overdue    int64             `json:"overdue"`
+	Throughput []*ThroughputWeek `json:"throughput"`
+	Flow       *FlowStats        `json:"flow"`
+}
+
+type StatsRepository interface {
+	CountBy(ctx context.Context, filter *StatsFilter, field string) ([]*CountBucket, error)
+	Overdue(ctx context.Context, filter *StatsFilter, now time.Time) ([]*CountBucket, error)
+	Throughput(ctx context.Context, filter *StatsFilter) ([]*ThroughputWeek, error)
+	Flow(ctx context.Context, filter *StatsFilter) (*FlowStats, error)
+}
+
+type StatsUsecase interface {
+	Summary(ctx context.Context, filter *StatsFilter) (*StatsSummary, error)
+	CountBy(ctx context.Context, filter *StatsFilter, field string) ([]*CountBucket, error)
+	Overdue(ctx context.Context, filter *StatsFilter) (*OverdueStats, error)
+	Throughput(ctx context.Context, filter *StatsFilter) ([]*ThroughputWeek, error)
+	Flow(ctx context.Context, filter *StatsFilter) (*FlowStats, error)
+}
@@ -2,6 +2,9 @@ package domains
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -9,32 +12,185 @@ import (
 
 const TaskCollection = "task"
 
+// ErrTaskChangeNotAllowed is returned when someone other than the creator or
+// the assignee changes a task through one of its sub-resources.
+var ErrTaskChangeNotAllowed = errors.New("only the creator or the assignee can change this task")
+
+const (
+	TaskStatusTodo       = "TODO"
+	TaskStatusInProgress = "IN_PROGRESS"
+	TaskStatusDone       = "DONE"
+)
+
+const (
+	PriorityLow    = "LOW"
+	PriorityMedium = "MEDIUM"
+	PriorityHigh   = "HIGH"
+	PriorityUrgent = "URGENT"
+)
+
+// PriorityRanks orders priorities; the rank is stored with the task so listings can sort on it.
+var PriorityRanks = map[string]int{
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+// StoryPoints are the estimates accepted for a task.
+var StoryPoints = map[float64]bool{0: true, 0.5: true, 1: true, 2: true, 3: true, 5: true, 8: true, 13: true, 21: true}
+
 type Task struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Title       string             `json:"title" bson:"title" validate:"required,min=4,max=50"`
-	Description string             `json:"description" bson:"description" validate:"max=100"`
-	Status      string             `json:"status" bson:"status"`
-	StartDate   time.Time          `json:"start_date" bson:"start_date"`
-	DueDate     time.Time          `json:"due_date" bson:"due_date"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
-	CreatedBy   string             `json:"created_by" bson:"created_by"`
-	UpdatedBy   string             `json:"updated_by" bson:"updated_by"`
-	TaskID      string             `json:"task_id" bson:"task_id"`
+	ID               primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Title            string                 `json:"title" bson:"title" validate:"required,min=4,max=50"`
+	Description      string                 `json:"description" bson:"description" validate:"max=100"`
+	Status           string                 `json:"status" bson:"status"`
+	StartDate        time.Time              `json:"start_date" bson:"start_date"`
+	DueDate          time.Time              `json:"due_date" bson:"due_date"`
+	DueDay           string                 `json:"due_day" bson:"due_day"`
+	DueInWorkingDays *int                   `json:"due_in_working_days,omitempty" bson:"-"`
+	CreatedAt        time.Time              `json:"created_at" bson:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at" bson:"updated_at"`
+	CreatedBy        string                 `json:"created_by" bson:"created_by"`
+	UpdatedBy        string                 `json:"updated_by" bson:"updated_by"`
+	TaskID           string                 `json:"task_id" bson:"task_id"`
+	ProjectID        string                 `json:"project_id" bson:"project_id"`
+	Labels           []string               `json:"labels" bson:"labels,omitempty"`
+	AssigneeID       string                 `json:"assignee_id" bson:"assignee_id"`
+	Priority         string                 `json:"priority" bson:"priority" validate:"eq=LOW|eq=MEDIUM|eq=HIGH|eq=URGENT"`
+	PriorityRank     int                    `json:"-" bson:"priority_rank"`
+	StoryPoints      float64                `json:"story_points" bson:"story_points"`
+	EstimateMinutes  int                    `json:"estimate_minutes" bson:"estimate_minutes" validate:"min=0"`
+	Rank             string                 `json:"rank" bson:"rank,omitempty"`
+	SprintID         string                 `json:"sprint_id" bson:"sprint_id,omitempty"`
+	CompletedAt      *time.Time             `json:"completed_at" bson:"completed_at"`
+	ParentID         string                 `json:"parent_id" bson:"parent_id,omitempty"`
+	Checklist        []ChecklistItem        `json:"checklist" bson:"checklist,omitempty"`
+	CustomFields     map[string]interface{} `json:"custom_fields" bson:"custom_fields,omitempty"`
+	Watchers         []string               `json:"watchers" bson:"watchers,omitempty"`
+}
+
+// ChecklistProgress returns the percentage of checklist items that are done.
+func (t Task) ChecklistProgress() float64 {
+	if len(t.Checklist) == 0 {
+		return 0
+	}
+	done := 0
+	for _, item := range t.Checklist {
+		if item.Done {
+			done++
+		}
+	}
+	return math.Round(float64(done)/float64(len(t.Checklist))*1000) / 10
+}
+
+// In converts the task's timestamps to loc for display. Unset dates are left
+// zero.
+func (t *Task) In(loc *time.Location) {
+	for _, date := range []*time.Time{&t.StartDate, &t.DueDate, &t.CreatedAt, &t.UpdatedAt} {
+		if !date.IsZero() {
+			*date = date.In(loc)
+		}
+	}
+	if t.CompletedAt != nil {
+		completedAt := t.CompletedAt.In(loc)
+		t.CompletedAt = &completedAt
+	}
+}
+
+// MarshalJSON adds the checklist progress to every task response.
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task
+	return json.Marshal(struct {
+		task
+		ChecklistProgress float64 `json:"checklist_progress"`
+	}{task(t), t.ChecklistProgress()})
+}
+
+type ScoredTask struct {
+	Task  *Task   `json:"task"`
+	Score float64 `json:"score"`
+}
+
+// TaskSortFields maps the sort keys accepted by the API to task document fields.
+var TaskSortFields = map[string]string{
+	"title":      "title",
+	"status":     "status",
+	"priority":   "priority_rank",
+	"start_date": "start_date",
+	"due_date":   "due_date",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+type TaskFilter struct {
+	ProjectID    string                 `json:"project_id,omitempty" bson:"project_id,omitempty"`
+	Statuses     []string               `json:"statuses,omitempty" bson:"statuses,omitempty"`
+	CreatedBy    string                 `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	AssigneeID   string                 `json:"assignee_id,omitempty" bson:"assignee_id,omitempty"`
+	Priorities   []string               `json:"priorities,omitempty" bson:"priorities,omitempty"`
+	Search       string                 `json:"search,omitempty" bson:"search,omitempty"`
+	DueFrom      *time.Time             `json:"due_from,omitempty" bson:"due_from,omitempty"`
+	DueTo        *time.Time             `json:"due_to,omitempty" bson:"due_to,omitempty"`
+	Labels       []string               `json:"labels,omitempty" bson:"labels,omitempty"`
+	LabelMatch   string                 `json:"label_match,omitempty" bson:"label_match,omitempty"`
+	SprintID     string                 `json:"sprint_id,omitempty" bson:"sprint_id,omitempty"`
+	ParentID     string                 `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	CustomFields []CustomFieldCondition `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
+	TaskIDs      []string               `json:"-" bson:"-"`
+}
+
+type TaskSort struct {
+	Field string `json:"field" bson:"field"`
+	Order string `json:"order" bson:"order"`
+}
+
+type TaskQuery struct {
+	Filter TaskFilter
+	Sort   TaskSort
+	Page   int64
+	Limit  int64
+}
+
+type TaskPage struct {
+	Tasks []*Task `json:"tasks"`
+	Page  int64   `json:"page"`
+	Limit int64   `json:"limit"`
+	Total int64   `json:"total"`
 }
 
 type TaskRepository interface {
 	Create(ctx context.Context, task *Task) error
 	FetchAll(ctx context.Context) ([]*Task, error)
+	FetchPage(ctx context.Context, query *TaskQuery) (*TaskPage, error)
 	FetchById(ctx context.Context, taskId string) (*Task, error)
 	UpdateById(ctx context.Context, taskId string, userID string, task *Task) error
 	DeleteById(ctx context.Context, taskId string, userId string) error
+	AddLabel(ctx context.Context, taskId string, label string) error
+	RemoveLabel(ctx context.Context, taskId string, label string) error
+	FetchOpenAssigned(ctx context.Context, userID string) ([]*Task, error)
+	FetchMatching(ctx context.Context, filter *TaskFilter) ([]*Task, error)
+	CountMatching(ctx context.Context, filter *TaskFilter) (int64, error)
+	Move(ctx context.Context, taskId string, userID string, status string, rank string, completedAt *time.Time) error
+	SetRank(ctx context.Context, taskId string, rank string) error
+	SetSprint(ctx context.Context, taskIds []string, sprintID string) error
+	AddChecklistItem(ctx context.Context, taskId string, item *ChecklistItem) error
+	UpdateChecklistItem(ctx context.Context, taskId string, itemId string, userID string, patch *ChecklistItemPatch) error
+	SetChecklistItemRank(ctx context.Context, taskId string, itemId string, rank string) error
+	RemoveChecklistItem(ctx context.Context, taskId string, itemId string) error
+	SetCustomFields(ctx context.Context, taskId string, userID string, set map[string]interface{}, unset []string) error
+	SetFields(ctx context.Context, taskId string, userID string, fields map[string]interface{}) error
+	AddWatchers(ctx context.Context, taskId string, userIDs []string) error
+	RemoveWatcher(ctx context.Context, taskId string, userID string) error
 }
 
 type TaskUsecase interface {
 	Create(ctx context.Context, task *Task) error
 	FetchAll(ctx context.Context) ([]*Task, error)
+	FetchPage(ctx context.Context, query *TaskQuery) (*TaskPage, error)
 	FetchById(ctx context.Context, taskId string) (*Task, error)
 	UpdateById(ctx context.Context, taskId string, userID string, task *Task) error
 	DeleteById(ctx context.Context, taskId string, userID string) error
+	Next(ctx context.Context, userID string, limit int) ([]*ScoredTask, error)
+	Export(ctx context.Context, query *TaskQuery) ([]*Task, error)
 }
@@ -0,0 +1,45 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const TaskStatusHistoryCollection = "task_status_history"
+
+const (
+	TaskCreated       = "TASK_CREATED"
+	TaskUpdated       = "TASK_UPDATED"
+	TaskStatusChanged = "TASK_STATUS_CHANGED"
+	TaskDeleted       = "TASK_DELETED"
+)
+
+// TaskEvent describes a change made to a task. Previous is nil for created tasks.
+type TaskEvent struct {
+	Type       string
+	Task       *Task
+	Previous   *Task
+	ActorID    string
+	OccurredAt time.Time
+}
+
+// TaskEventHandler reacts to task changes after they have been stored.
+type TaskEventHandler interface {
+	HandleTaskEvent(ctx context.Context, event *TaskEvent)
+}
+
+type TaskStatusChange struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TaskID    string             `json:"task_id" bson:"task_id"`
+	From      string             `json:"from" bson:"from"`
+	To        string             `json:"to" bson:"to"`
+	ChangedBy string             `json:"changed_by" bson:"changed_by"`
+	ChangedAt time.Time          `json:"changed_at" bson:"changed_at"`
+}
+
+type TaskStatusHistoryRepository interface {
+	Create(ctx context.Context, change *TaskStatusChange) error
+	FetchByTasks(ctx context.Context, taskIDs []string) ([]*TaskStatusChange, error)
+}
@@ -0,0 +1,71 @@
+package domains
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const TaskTemplateCollection = "task_template"
+
+// ErrTemplateChangeNotAllowed is returned when someone other than the creator
+// of a template or an ADMIN changes it.
+var ErrTemplateChangeNotAllowed = errors.New("only the creator of the template or an ADMIN can change it")
+
+// TemplateTask is one node of a template tree. Title and Description may use
+// {{placeholders}}; the offsets are days from the date the template is used.
+type TemplateTask struct {
+	Title           string         `json:"title" bson:"title" validate:"required"`
+	Description     string         `json:"description" bson:"description"`
+	Priority        string         `json:"priority" bson:"priority"`
+	StoryPoints     float64        `json:"story_points" bson:"story_points"`
+	EstimateMinutes int            `json:"estimate_minutes" bson:"estimate_minutes"`
+	StartOffsetDays int            `json:"start_offset_days" bson:"start_offset_days" validate:"min=0"`
+	DueOffsetDays   int            `json:"due_offset_days" bson:"due_offset_days" validate:"min=0"`
+	Children        []TemplateTask `json:"children" bson:"children"`
+}
+
+type TaskTemplate struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TemplateID  string             `json:"template_id" bson:"template_id"`
+	Name        string             `json:"name" bson:"name" validate:"required,min=1,max=50"`
+	Description string             `json:"description" bson:"description"`
+	ProjectID   string             `json:"project_id" bson:"project_id"`
+	Tasks       []TemplateTask     `json:"tasks" bson:"tasks"`
+	Variables   []string           `json:"variables" bson:"variables"`
+	CreatedBy   string             `json:"created_by" bson:"created_by"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type TemplateInstantiation struct {
+	Variables  map[string]string `json:"variables"`
+	StartDate  time.Time         `json:"start_date"`
+	ProjectID  string            `json:"project_id"`
+	AssigneeID string            `json:"assignee_id"`
+}
+
+// TransactionManager runs fn so that every write made with the context it
+// receives is committed together or not at all.
+type TransactionManager interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type TaskTemplateRepository interface {
+	Create(ctx context.Context, template *TaskTemplate) error
+	FetchAll(ctx context.Context, projectID string) ([]*TaskTemplate, error)
+	FetchById(ctx context.Context, templateID string) (*TaskTemplate, error)
+	UpdateById(ctx context.Context, templateID string, template *TaskTemplate) error
+	DeleteById(ctx context.Context, templateID string) error
+}
+
+type TaskTemplateUsecase interface {
+	Create(ctx context.Context, template *TaskTemplate) error
+	FetchAll(ctx context.Context, projectID string) ([]*TaskTemplate, error)
+	FetchById(ctx context.Context, templateID string) (*TaskTemplate, error)
+	UpdateById(ctx context.Context, templateID string, userID string, userType string, template *TaskTemplate) error
+	DeleteById(ctx context.Context, templateID string, userID string, userType string) error
+	Instantiate(ctx context.Context, templateID string, userID string, request *TemplateInstantiation) ([]*Task, error)
+}
@@ -0,0 +1,69 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const TimeEntryCollection = "time_entry"
+
+const (
+	TimeEntryTimer  = "TIMER"
+	TimeEntryManual = "MANUAL"
+)
+
+type TimeEntry struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	EntryID         string             `json:"entry_id" bson:"entry_id"`
+	TaskID          string             `json:"task_id" bson:"task_id" validate:"required"`
+	UserID          string             `json:"user_id" bson:"user_id"`
+	Source          string             `json:"source" bson:"source"`
+	Note            string             `json:"note" bson:"note" validate:"max=200"`
+	StartedAt       time.Time          `json:"started_at" bson:"started_at"`
+	EndedAt         *time.Time         `json:"ended_at" bson:"ended_at"`
+	DurationSeconds int64              `json:"duration_seconds" bson:"duration_seconds"`
+	Running         bool               `json:"running" bson:"running"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type TimeEntryFilter struct {
+	UserID string
+	TaskID string
+	From   *time.Time
+	To     *time.Time
+}
+
+type TimesheetRow struct {
+	UserID  string  `json:"user_id" bson:"user_id"`
+	TaskID  string  `json:"task_id" bson:"task_id"`
+	Day     string  `json:"day" bson:"day"`
+	Seconds int64   `json:"seconds" bson:"seconds"`
+	Hours   float64 `json:"hours" bson:"-"`
+}
+
+type Timesheet struct {
+	Rows       []*TimesheetRow `json:"rows"`
+	TotalHours float64         `json:"total_hours"`
+}
+
+type TimeEntryRepository interface {
+	Create(ctx context.Context, entry *TimeEntry) error
+	StartTimer(ctx context.Context, entry *TimeEntry) error
+	StopTimer(ctx context.Context, userID string, endedAt time.Time) (*TimeEntry, error)
+	FetchRunning(ctx context.Context, userID string) (*TimeEntry, error)
+	Fetch(ctx context.Context, filter *TimeEntryFilter) ([]*TimeEntry, error)
+	DeleteById(ctx context.Context, entryID string, userID string) error
+	Timesheet(ctx context.Context, filter *TimeEntryFilter) ([]*TimesheetRow, error)
+}
+
+type TimeEntryUsecase interface {
+	StartTimer(ctx context.Context, taskID string, userID string, note string) (*TimeEntry, error)
+	StopTimer(ctx context.Context, userID string) (*TimeEntry, error)
+	FetchRunning(ctx context.Context, userID string) (*TimeEntry, error)
+	CreateManual(ctx context.Context, entry *TimeEntry) error
+	Fetch(ctx context.Context, filter *TimeEntryFilter) ([]*TimeEntry, error)
+	DeleteById(ctx context.Context, entryID string, userID string) error
+	Timesheet(ctx context.Context, filter *TimeEntryFilter) (*Timesheet, error)
+}
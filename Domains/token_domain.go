@@ -0,0 +1,82 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	RefreshTokenCollection    = "refresh_token"
+	RevokedTokenCollection    = "revoked_token"
+	TokenGenerationCollection = "token_generation"
+)
+
+const (
+	AccessTokenLifetime = 24 * time.Hour
+	// RefreshTokenLifetime is how long a refresh token can be exchanged.
+	RefreshTokenLifetime = 200 * time.Hour
+)
+
+// RefreshToken records an issued refresh token. Every refresh token minted
+// from the same login shares a FamilyID; a token is used once, and using it
+// again revokes the whole family.
+type RefreshToken struct {
+	TokenID   string     `json:"token_id" bson:"token_id"`
+	FamilyID  string     `json:"family_id" bson:"family_id"`
+	UserID    string     `json:"user_id" bson:"user_id"`
+	UsedAt    *time.Time `json:"used_at" bson:"used_at"`
+	Revoked   bool       `json:"revoked" bson:"revoked"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" bson:"expires_at"`
+}
+
+// TokenSubject is what goes into a new token pair. Generation is the user's
+// token generation when the pair is minted; logging out everywhere bumps it
+// and so invalidates every older token.
+type TokenSubject struct {
+	Username   string
+	Uid        string
+	UserType   string
+	Generation int
+	FamilyID   string
+	AccessID   string
+	RefreshID  string
+}
+
+// TokenPair is what a login or a refresh hands back to the client.
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	FetchById(ctx context.Context, tokenID string) (*RefreshToken, error)
+	// MarkUsed reports false when the token was already used.
+	MarkUsed(ctx context.Context, tokenID string, usedAt time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeUser(ctx context.Context, userID string) error
+}
+
+// TokenRevocationStore is consulted on every authenticated request. Revoked
+// token IDs only need to be kept until the token would have expired.
+type TokenRevocationStore interface {
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	Generation(ctx context.Context, userID string) (int, error)
+	BumpGeneration(ctx context.Context, userID string) (int, error)
+}
+
+type TokenUsecase interface {
+	// Issue starts a new session for a login.
+	Issue(ctx context.Context, user *User, client *SessionClient) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string, client *SessionClient) (*TokenPair, error)
+	// Logout ends the session the access token belongs to.
+	Logout(ctx context.Context, claims *SignedDetails) error
+	// LogoutAll invalidates every token the user holds.
+	LogoutAll(ctx context.Context, userID string) error
+}
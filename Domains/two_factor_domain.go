@@ -0,0 +1,153 @@
+package domains
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	TwoFactorCollection          = "two_factor"
+	TwoFactorChallengeCollection = "two_factor_challenge"
+	TwoFactorPolicyCollection    = "two_factor_policy"
+)
+
+const (
+	// TwoFactorChallengeLifetime is how long the second step of a login
+	// may take.
+	TwoFactorChallengeLifetime = 5 * time.Minute
+	// MaxTwoFactorAttempts wrong codes end a challenge; the user has to
+	// enter the password again.
+	MaxTwoFactorAttempts = 5
+	RecoveryCodeCount    = 10
+)
+
+const (
+	// ChallengeVerify asks for a code of an enrolled user.
+	ChallengeVerify = "verify"
+	// ChallengeSetup asks a user whose role requires 2FA to enroll first.
+	ChallengeSetup = "setup"
+)
+
+// TwoFactor is a user's TOTP enrollment. It is pending until the first code
+// is confirmed. LastStep is the time step of the last accepted code, so a
+// code cannot be used twice. Recovery codes are stored hashed and removed
+// when used.
+type TwoFactor struct {
+	UserID        string     `bson:"user_id"`
+	Secret        string     `bson:"secret"`
+	Enabled       bool       `bson:"enabled"`
+	RecoveryCodes []string   `bson:"recovery_codes"`
+	LastStep      int64      `bson:"last_step"`
+	CreatedAt     time.Time  `bson:"created_at"`
+	EnabledAt     *time.Time `bson:"enabled_at"`
+}
+
+// TwoFactorPolicy makes 2FA mandatory for a user type.
+type TwoFactorPolicy struct {
+	UserType  string    `json:"user_type" bson:"user_type"`
+	Required  bool      `json:"required" bson:"required"`
+	UpdatedBy string    `json:"updated_by" bson:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// TwoFactorChallenge stands for a login whose password was right but which
+// still needs the second factor.
+type TwoFactorChallenge struct {
+	ChallengeID string    `json:"challenge_id" bson:"challenge_id"`
+	UserID      string    `json:"-" bson:"user_id"`
+	Purpose     string    `json:"purpose" bson:"purpose"`
+	Attempts    int       `json:"-" bson:"attempts"`
+	CreatedAt   time.Time `json:"-" bson:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth:// URI authenticator apps read from a QR code.
+	URI string `json:"uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool `json:"enabled"`
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorLoginRequest finishes a login with a code from the app or, when
+// the device is lost, a recovery code.
+type TwoFactorLoginRequest struct {
+	ChallengeID  string `json:"challenge_id"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorPolicyRequest struct {
+	Required bool `json:"required"`
+}
+
+// TOTPProvider generates and checks RFC 6238 codes.
+type TOTPProvider interface {
+	GenerateSecret() (string, error)
+	URI(issuer string, account string, secret string) string
+	// Validate returns the time step of the code when it is valid now.
+	Validate(secret string, code string, now time.Time) (int64, bool)
+}
+
+type TwoFactorRepository interface {
+	// Fetch returns nil when the user never started an enrollment.
+	Fetch(ctx context.Context, userID string) (*TwoFactor, error)
+	Save(ctx context.Context, twoFactor *TwoFactor) error
+	Enable(ctx context.Context, userID string, recoveryCodes []string, step int64, enabledAt time.Time) error
+	// UseStep reports false when a code of the step or a later one was
+	// already accepted.
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	// UseRecoveryCode reports false when the code was already used.
+	UseRecoveryCode(ctx context.Context, userID string, hashedCode string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, recoveryCodes []string) error
+	Delete(ctx context.Context, userID string) error
+}
+
+type TwoFactorChallengeRepository interface {
+	Create(ctx context.Context, challenge *TwoFactorChallenge) error
+	FetchById(ctx context.Context, challengeID string) (*TwoFactorChallenge, error)
+	// Fail counts a wrong code and returns the attempts so far.
+	Fail(ctx context.Context, challengeID string) (int, error)
+	Delete(ctx context.Context, challengeID string) error
+}
+
+type TwoFactorPolicyRepository interface {
+	FetchAll(ctx context.Context) ([]*TwoFactorPolicy, error)
+	Required(ctx context.Context, userType string) (bool, error)
+	Upsert(ctx context.Context, policy *TwoFactorPolicy) error
+}
+
+type TwoFactorUsecase interface {
+	// Challenge returns nil when the user can log in with the password
+	// alone.
+	Challenge(ctx context.Context, user *User) (*TwoFactorChallenge, error)
+	// Verify finishes a login and returns the user ID.
+	Verify(ctx context.Context, request *TwoFactorLoginRequest) (string, error)
+	// BeginSetup and ConfirmSetup enroll a user during a setup challenge.
+	BeginSetup(ctx context.Context, challengeID string) (*TwoFactorEnrollment, error)
+	ConfirmSetup(ctx context.Context, challengeID string, code string) (string, *RecoveryCodes, error)
+
+	Status(ctx context.Context, userID string, userType string) (*TwoFactorStatus, error)
+	Begin(ctx context.Context, userID string, username string) (*TwoFactorEnrollment, error)
+	Confirm(ctx context.Context, userID string, code string) (*RecoveryCodes, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID string, code string) (*RecoveryCodes, error)
+	Disable(ctx context.Context, userID string, userType string, code string) error
+
+	Policies(ctx context.Context) ([]*TwoFactorPolicy, error)
+	SetPolicy(ctx context.Context, userType string, required bool, updatedBy string) (*TwoFactorPolicy, error)
+	// Reset removes a user's enrollment, for users who lost their device
+	// and their recovery codes.
+	Reset(ctx context.Context, userID string) error
+}
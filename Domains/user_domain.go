@@ -2,6 +2,7 @@ package domains
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -10,42 +11,92 @@ import (
 
 const UserCollection = "user"
 
+// User is an account. EmailVerified is set once the user proved to own
+// Email, or when the identity provider did.
 type User struct {
-	ID           primitive.ObjectID `bson:"_id"`
-	FirstName    string             `json:"first_name" validate:"required,min=3,max=50"`
-	LastName     string             `json:"last_name" validate:"required,min=3,max=50"`
-	Username     string             `json:"username" validate:"required,min=5,max=25"`
-	Token        string             `json:"token"`
-	Password     string             `json:"password"`
-	UserType     string             `json:"user_type" validate:"required,eq=ADMIN|eq=USER"`
-	RefreshToken string             `json:"refresh_token"`
-	CreatedAt    time.Time          `json:"created_at"`
-	UpdatedAt    time.Time          `json:"updated_at"`
-	UserID       string             `json:"user_id"`
+	ID            primitive.ObjectID `bson:"_id"`
+	FirstName     string             `json:"first_name" validate:"required,min=3,max=50"`
+	LastName      string             `json:"last_name" validate:"required,min=3,max=50"`
+	Username      string             `json:"username" validate:"required,min=5,max=25"`
+	Email         string             `json:"email" validate:"omitempty,email"`
+	EmailVerified bool               `json:"email_verified"`
+	Token         string             `json:"-"`
+	Password      string             `json:"password"`
+	UserType      string             `json:"user_type" validate:"required,eq=ADMIN|eq=USER"`
+	RefreshToken  string             `json:"-"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	UserID        string             `json:"user_id"`
 }
 
+// UpdateUserRequest changes the profile. Fields left out stay as they are;
+// an empty email removes the address. Changing the email takes the current
+// password and goes through the EmailUsecase.
+type UpdateUserRequest struct {
+	FirstName       string  `json:"first_name"`
+	LastName        string  `json:"last_name"`
+	Email           *string `json:"email"`
+	CurrentPassword string  `json:"current_password"`
+	// Password is only read to turn it away, it has its own endpoints.
+	Password string `json:"password"`
+}
+
+// TokenClockSkew is tolerated between the instances that issue and verify
+// tokens.
+const TokenClockSkew = time.Minute
+
+// SignedDetails are the claims of both tokens. The user is the standard sub
+// claim, copied to Uid once a token is validated. Each token has its own ID
+// in the standard jti claim and names the family (login) it belongs to.
 type SignedDetails struct {
-	Username string
-	Uid      string
-	UserType string
+	Username   string
+	Uid        string `json:"-"`
+	UserType   string
+	Family     string `json:",omitempty"`
+	Generation int    `json:",omitempty"`
+	Refresh    bool   `json:",omitempty"`
 	jwt.StandardClaims
 }
 
+// Valid checks the time claims with TokenClockSkew of leeway and that the
+// token names its subject and ID. The issuer and audience are checked by the
+// token service, which knows the expected values.
+func (c *SignedDetails) Valid() error {
+	now := time.Now()
+	switch {
+	case c.ExpiresAt == 0 || now.Add(-TokenClockSkew).Unix() > c.ExpiresAt:
+		return fmt.Errorf("token is expired")
+	case c.IssuedAt == 0 || now.Add(TokenClockSkew).Unix() < c.IssuedAt:
+		return fmt.Errorf("token was issued in the future")
+	case now.Add(TokenClockSkew).Unix() < c.NotBefore:
+		return fmt.Errorf("token is not valid yet")
+	case c.Subject == "" || c.Id == "":
+		return fmt.Errorf("token has no subject or id")
+	}
+	return nil
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	FetchAll(ctx context.Context) ([]*User, error)
 	FetchById(ctx context.Context, userId string) (*User, error)
 	UpdateById(ctx context.Context, userId string, user *User) error
+	// UpdateEmail sets an unverified email.
+	UpdateEmail(ctx context.Context, userID string, email string) error
+	// VerifyEmail marks the email as verified if it is still email.
+	VerifyEmail(ctx context.Context, userID string, email string) (bool, error)
 	DeleteById(ctx context.Context, userId string) error
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
 	UpdateAllToken(ctx context.Context, signedToken, signedRefreshToken, UserID string) error
+	UpdateUserType(ctx context.Context, userID string, userType string) error
+	UpdatePassword(ctx context.Context, userID string, hashedPassword string) error
 }
 
 type UserUsecase interface {
 	Create(ctx context.Context, user *User) error
 	FetchAll(ctx context.Context) ([]*User, error)
 	FetchById(ctx context.Context, userId string) (*User, error)
-	UpdateById(ctx context.Context, userId string, user *User) error
+	UpdateById(ctx context.Context, userId string, request *UpdateUserRequest) error
 	DeleteById(ctx context.Context, userId string) error
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
 	UpdateAllToken(ctx context.Context, signedToken, signedRefreshToken, UserID string) error
@@ -57,6 +108,6 @@ type PasswordServiceProvider interface {
 }
 
 type IUserToken interface {
-	GenerateAllTokens(username, userType, UserID string) (signedToken, signedRefreshToken string, err error)
+	GenerateAllTokens(subject *TokenSubject) (signedToken, signedRefreshToken string, err error)
 	ValidateToken(signedToken string) (claims *SignedDetails, err error)
 }
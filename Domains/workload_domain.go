@@ -0,0 +1,117 @@
+package domains
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CapacityCollection = "capacity"
+
+// DefaultWeeklyCapacityMinutes is the capacity of users who never declared
+// one: a 40 hour week spread over Monday to Friday.
+const DefaultWeeklyCapacityMinutes = 40 * 60
+
+// TimeOff is a range of whole days, From and To included, on which the user
+// does not work.
+type TimeOff struct {
+	TimeOffID string    `json:"time_off_id" bson:"time_off_id"`
+	From      time.Time `json:"from" bson:"from"`
+	To        time.Time `json:"to" bson:"to"`
+	Note      string    `json:"note" bson:"note" validate:"max=100"`
+}
+
+// Capacity is how much a user can work each week. It is split evenly over
+// Monday to Friday, and days off reduce it.
+type Capacity struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID        string             `json:"user_id" bson:"user_id"`
+	WeeklyMinutes int                `json:"weekly_minutes" bson:"weekly_minutes"`
+	TimeOff       []TimeOff          `json:"time_off" bson:"time_off"`
+	UpdatedAt     time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// WorksOn reports whether day is a working day the user is not off.
+func (c *Capacity) WorksOn(day time.Time) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	for _, off := range c.TimeOff {
+		if !day.Before(off.From) && !day.After(off.To) {
+			return false
+		}
+	}
+	return true
+}
+
+// DailyMinutes is the capacity of a single working day.
+func (c *Capacity) DailyMinutes() int {
+	return c.WeeklyMinutes / 5
+}
+
+// WorkloadQuery selects the weeks, starting with the one holding From, and
+// the assignees the workload is computed for.
+type WorkloadQuery struct {
+	From      time.Time
+	Weeks     int
+	UserID    string
+	ProjectID string
+}
+
+// TaskAllocation is the share of a task's estimate planned in a week.
+type TaskAllocation struct {
+	TaskID  string `json:"task_id"`
+	Title   string `json:"title"`
+	Status  string `json:"status"`
+	Minutes int    `json:"minutes"`
+}
+
+type WorkloadWeek struct {
+	WeekStart        time.Time         `json:"week_start"`
+	CapacityMinutes  int               `json:"capacity_minutes"`
+	AllocatedMinutes int               `json:"allocated_minutes"`
+	OverMinutes      int               `json:"over_minutes"`
+	Utilization      float64           `json:"utilization"`
+	Tasks            []*TaskAllocation `json:"tasks"`
+}
+
+// UserWorkload spreads the estimates of a user's open tasks over the working
+// days until they are due. Tasks without a due date cannot be planned and are
+// only counted as unscheduled.
+type UserWorkload struct {
+	UserID             string          `json:"user_id"`
+	Weeks              []*WorkloadWeek `json:"weeks"`
+	OverAllocated      bool            `json:"over_allocated"`
+	UnscheduledMinutes int             `json:"unscheduled_minutes"`
+	UnscheduledTasks   []string        `json:"unscheduled_tasks"`
+}
+
+// RebalanceSuggestion proposes reassigning a task from an over-allocated user
+// to one with room for it. Nothing changes until the task is updated.
+type RebalanceSuggestion struct {
+	TaskID     string    `json:"task_id"`
+	Title      string    `json:"title"`
+	WeekStart  time.Time `json:"week_start"`
+	Minutes    int       `json:"minutes"`
+	FromUserID string    `json:"from_user_id"`
+	ToUserID   string    `json:"to_user_id"`
+	Reason     string    `json:"reason"`
+}
+
+type CapacityRepository interface {
+	Fetch(ctx context.Context, userID string) (*Capacity, error)
+	FetchMany(ctx context.Context, userIDs []string) (map[string]*Capacity, error)
+	SetWeeklyMinutes(ctx context.Context, userID string, minutes int) error
+	AddTimeOff(ctx context.Context, userID string, timeOff *TimeOff) error
+	RemoveTimeOff(ctx context.Context, userID string, timeOffID string) error
+}
+
+type WorkloadUsecase interface {
+	FetchCapacity(ctx context.Context, userID string) (*Capacity, error)
+	SetWeeklyCapacity(ctx context.Context, userID string, minutes int) (*Capacity, error)
+	AddTimeOff(ctx context.Context, userID string, timeOff *TimeOff) error
+	RemoveTimeOff(ctx context.Context, userID string, timeOffID string) error
+	Workload(ctx context.Context, query *WorkloadQuery) ([]*UserWorkload, error)
+	Suggestions(ctx context.Context, query *WorkloadQuery) ([]*RebalanceSuggestion, error)
+}
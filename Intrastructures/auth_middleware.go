@@ -1,26 +1,68 @@
 package Intrastructures
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	domain "github.com/segnig/task-manager/Domains"
 )
 
-func Authentication(userToken domain.IUserToken) gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		clientToken := ctx.Request.Header.Get("token")
+// touchInterval limits how often the last-seen time of a session or the
+// last-used time of an API key is written.
+const touchInterval = time.Minute
+
+// authRealm names the protection space in WWW-Authenticate challenges.
+const authRealm = "task-manager"
 
+// Authentication accepts a valid access token unless it was revoked by a
+// logout, minted before the user's latest "log out everywhere", or belongs to
+// a session that has ended. The token is sent as "Authorization: Bearer"
+// (RFC 6750); the old "token" header still works but is deprecated. API keys
+// are sent the same way.
+func Authentication(userToken domain.IUserToken, revocations domain.TokenRevocationStore, sessions domain.SessionRepository, apiKeys domain.ApiKeyRepository) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clientToken := bearerToken(ctx)
+		if clientToken == "" {
+			if clientToken = ctx.GetHeader("token"); clientToken != "" {
+				ctx.Header("Deprecation", "true")
+			}
+		}
 		if clientToken == "" {
-			ctx.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: "No Authentication header provided"})
-			ctx.Abort()
+			ctx.Header("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s"`, authRealm))
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "No Authentication header provided"})
 			return
 		}
+		if strings.HasPrefix(clientToken, domain.ApiKeyPrefix) {
+			apiKeyAuthentication(ctx, clientToken, revocations, apiKeys)
+			return
+		}
+
 		claims, err := userToken.ValidateToken(clientToken)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
-			ctx.Abort()
+			abortInvalidToken(ctx, err.Error())
+			return
+		}
+		if claims.Refresh {
+			abortInvalidToken(ctx, "a refresh token cannot be used to call the api")
+			return
+		}
+		revoked, err := tokenRevoked(ctx, revocations, claims)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+		if revoked {
+			abortInvalidToken(ctx, "token has been revoked")
+			return
+		}
+		if claims.Family != "" && !sessionActive(ctx, sessions, claims.Family) {
+			abortInvalidToken(ctx, "session has ended")
 			return
 		}
 		log.Println("Claim Id", claims.Uid)
@@ -28,8 +70,133 @@ func Authentication(userToken domain.IUserToken) gin.HandlerFunc {
 		log.Println("claim username", claims.Username)
 
 		ctx.Set("username", claims.Username)
-		ctx.Set("user_id", claims.UserType)
-		ctx.Set("user_type", claims.Uid)
+		ctx.Set("user_id", claims.Uid)
+		ctx.Set("user_type", claims.UserType)
+		ctx.Set("claims", claims)
 		ctx.Next()
 	}
 }
+
+// RequireUserType lets only the given user types through. It runs after
+// Authentication: the caller is known, so a refusal is 403, not 401.
+func RequireUserType(userTypes ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if slices.Contains(userTypes, ctx.GetString("user_type")) {
+			ctx.Next()
+			return
+		}
+		abortInsufficientScope(ctx, fmt.Sprintf("only %s users can do this", strings.Join(userTypes, " or ")))
+	}
+}
+
+// RejectApiKeys keeps API keys away from routes that need a login, so a
+// leaked key cannot be used to mint more keys.
+func RejectApiKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get("api_key"); ok {
+			abortInsufficientScope(ctx, "api keys cannot be used here, log in instead")
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// apiKeyAuthentication accepts an active API key whose scopes cover the
+// request. Personal keys stop working when their user logs out everywhere.
+func apiKeyAuthentication(ctx *gin.Context, clientKey string, revocations domain.TokenRevocationStore, apiKeys domain.ApiKeyRepository) {
+	c, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	key, err := apiKeys.FetchByHash(c, domain.HashApiKey(clientKey))
+	now := time.Now()
+	if err != nil || !key.Active(now) {
+		abortInvalidToken(ctx, "api key is invalid, expired or revoked")
+		return
+	}
+	if key.Kind == domain.ApiKeyPersonal {
+		generation, err := revocations.Generation(c, key.UserID)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+		if key.Generation < generation {
+			abortInvalidToken(ctx, "api key is invalid, expired or revoked")
+			return
+		}
+	}
+	if !key.Allows(ctx.Request.Method) {
+		abortInsufficientScope(ctx, "this api key does not have the write scope")
+		return
+	}
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval {
+		if err := apiKeys.Touch(c, key.KeyID, now); err != nil {
+			log.Println("error recording api key use:", err)
+		}
+	}
+
+	ctx.Set("username", key.Username)
+	ctx.Set("user_id", key.UserID)
+	ctx.Set("user_type", key.UserType)
+	ctx.Set("api_key", key)
+	ctx.Next()
+}
+
+func bearerToken(ctx *gin.Context) string {
+	scheme, token, ok := strings.Cut(ctx.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func abortInvalidToken(ctx *gin.Context, description string) {
+	ctx.Header("WWW-Authenticate", challenge("invalid_token", description))
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, domain.ErrorResponse{Message: description})
+}
+
+func abortInsufficientScope(ctx *gin.Context, description string) {
+	ctx.Header("WWW-Authenticate", challenge("insufficient_scope", description))
+	ctx.AbortWithStatusJSON(http.StatusForbidden, domain.ErrorResponse{Message: description})
+}
+
+// challenge builds a Bearer challenge; quotes and backslashes cannot appear
+// in the description (RFC 6750 section 3).
+func challenge(code string, description string) string {
+	description = strings.NewReplacer(`"`, "'", `\`, "/").Replace(description)
+	return fmt.Sprintf(`Bearer realm="%s", error="%s", error_description="%s"`, authRealm, code, description)
+}
+
+func tokenRevoked(ctx context.Context, revocations domain.TokenRevocationStore, claims *domain.SignedDetails) (bool, error) {
+	c, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if claims.Id != "" {
+		revoked, err := revocations.IsTokenRevoked(c, claims.Id)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	generation, err := revocations.Generation(c, claims.Uid)
+	if err != nil {
+		return false, err
+	}
+	return claims.Generation < generation, nil
+}
+
+// sessionActive also records the request as activity of the session.
+func sessionActive(ctx *gin.Context, sessions domain.SessionRepository, sessionID string) bool {
+	c, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	session, err := sessions.FetchById(c, sessionID)
+	now := time.Now()
+	if err != nil || !session.Active(now) {
+		return false
+	}
+	if now.Sub(session.LastSeenAt) > touchInterval {
+		if err := sessions.Touch(c, sessionID, ctx.ClientIP(), now, time.Time{}); err != nil {
+			log.Println("error recording session activity:", err)
+		}
+	}
+	return true
+}
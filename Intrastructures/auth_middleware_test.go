@@ -0,0 +1,72 @@
+package Intrastructures
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// fakeApiKeys only implements what authentication uses.
+type fakeApiKeys struct {
+	domain.ApiKeyRepository
+	keys map[string]*domain.ApiKey
+}
+
+func (r *fakeApiKeys) FetchByHash(ctx context.Context, hash string) (*domain.ApiKey, error) {
+	key, ok := r.keys[hash]
+	if !ok {
+		return nil, errors.New("no api key found")
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (r *fakeApiKeys) Touch(ctx context.Context, keyID string, usedAt time.Time) error {
+	return nil
+}
+
+func TestPersonalApiKeyEndsWithTheGeneration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secret = domain.ApiKeyPrefix + "personal-admin-key"
+	revocations := NewMemoryTokenRevocation()
+	apiKeys := &fakeApiKeys{keys: map[string]*domain.ApiKey{
+		domain.HashApiKey(secret): {
+			KeyID:     "k1",
+			Kind:      domain.ApiKeyPersonal,
+			Scopes:    []string{domain.ScopeRead},
+			UserID:    "u1",
+			Username:  "alice",
+			UserType:  "ADMIN",
+			ExpiresAt: time.Now().Add(365 * 24 * time.Hour),
+		},
+	}}
+
+	router := gin.New()
+	router.GET("/admin", Authentication(nil, revocations, nil, apiKeys), RequireUserType("ADMIN"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	call := func() int {
+		request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		request.Header.Set("Authorization", "Bearer "+secret)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	if code := call(); code != http.StatusOK {
+		t.Fatalf("status = %d before the role changed, want 200", code)
+	}
+	// a demotion bumps the generation, the key still says ADMIN
+	if _, err := revocations.BumpGeneration(context.Background(), "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if code := call(); code != http.StatusUnauthorized {
+		t.Errorf("status = %d after the demotion, want 401", code)
+	}
+}
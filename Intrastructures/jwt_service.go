@@ -8,48 +8,89 @@ import (
 	domain "github.com/segnig/task-manager/Domains"
 )
 
+// UserToken signs tokens with the key ring's newest key and names it in the
+// kid header, so tokens stay verifiable after the key is rotated out. Only
+// tokens with the configured issuer and audience are accepted.
 type UserToken struct {
-	SECTRET_KEY string
+	KeyRing  domain.KeyRing
+	Issuer   string
+	Audience string
 }
 
-func NeWUserToken(secretKey string) domain.IUserToken {
+func NeWUserToken(keyRing domain.KeyRing, issuer string, audience string) domain.IUserToken {
 	return &UserToken{
-		SECTRET_KEY: secretKey,
+		KeyRing:  keyRing,
+		Issuer:   issuer,
+		Audience: audience,
 	}
 }
 
-func (ut *UserToken) GenerateAllTokens(username, uid, userType string) (signedToken, signedRefreshToken string, err error) {
+func (ut *UserToken) GenerateAllTokens(subject *domain.TokenSubject) (signedToken, signedRefreshToken string, err error) {
+	now := time.Now()
 	claims := &domain.SignedDetails{
-		Username: username,
-		Uid:      uid,
-		UserType: userType,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
-		},
+		Username:       subject.Username,
+		UserType:       subject.UserType,
+		Family:         subject.FamilyID,
+		Generation:     subject.Generation,
+		StandardClaims: ut.standardClaims(subject.Uid, subject.AccessID, now, domain.AccessTokenLifetime),
 	}
 
 	refreshClaims := &domain.SignedDetails{
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(200 * time.Hour).Unix(),
-		},
+		Username:       subject.Username,
+		UserType:       subject.UserType,
+		Family:         subject.FamilyID,
+		Generation:     subject.Generation,
+		Refresh:        true,
+		StandardClaims: ut.standardClaims(subject.Uid, subject.RefreshID, now, domain.RefreshTokenLifetime),
 	}
-	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ut.SECTRET_KEY))
+	token, err := ut.sign(claims)
 	if err != nil {
 		return "", "", err
 	}
-	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).SignedString([]byte(ut.SECTRET_KEY))
+	refreshToken, err := ut.sign(refreshClaims)
 	if err != nil {
 		return "", "", err
 	}
 	return token, refreshToken, nil
 }
 
+func (ut *UserToken) standardClaims(uid string, tokenID string, now time.Time, lifetime time.Duration) jwt.StandardClaims {
+	return jwt.StandardClaims{
+		Subject:   uid,
+		Issuer:    ut.Issuer,
+		Audience:  ut.Audience,
+		Id:        tokenID,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(lifetime).Unix(),
+	}
+}
+
+func (ut *UserToken) sign(claims *domain.SignedDetails) (string, error) {
+	keyID, method, key, err := ut.KeyRing.SigningKey()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = keyID
+	return token.SignedString(key)
+}
+
 func (ut *UserToken) ValidateToken(signedToken string) (claims *domain.SignedDetails, err error) {
 	token, err := jwt.ParseWithClaims(
 		signedToken,
 		&domain.SignedDetails{},
 		func(t *jwt.Token) (interface{}, error) {
-			return []byte(ut.SECTRET_KEY), nil
+			keyID, _ := t.Header["kid"].(string)
+			method, key, err := ut.KeyRing.VerificationKey(keyID)
+			if err != nil {
+				return nil, err
+			}
+			// the algorithm comes from the key, never from the token
+			if t.Method.Alg() != method.Alg() {
+				return nil, fmt.Errorf("token is not signed with %s", method.Alg())
+			}
+			return key, nil
 		},
 	)
 	if err != nil {
@@ -61,8 +102,10 @@ func (ut *UserToken) ValidateToken(signedToken string) (claims *domain.SignedDet
 		return nil, fmt.Errorf("the token is in valid")
 	}
 
-	if claims.ExpiresAt < time.Now().Unix() {
-		return nil, fmt.Errorf("token is expired")
+	// the time claims were checked by SignedDetails.Valid while parsing
+	if !claims.VerifyIssuer(ut.Issuer, true) || !claims.VerifyAudience(ut.Audience, true) {
+		return nil, fmt.Errorf("token was not issued for this api")
 	}
+	claims.Uid = claims.Subject
 	return claims, err
 }
@@ -0,0 +1,230 @@
+package Intrastructures
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	rsaKeyBits = 2048
+	// keyGracePeriod covers tokens signed by a key after it was due for
+	// rotation but before the next rotation check ran.
+	keyGracePeriod = time.Hour
+	// minReloadInterval limits reloads triggered by unknown key IDs.
+	minReloadInterval = 30 * time.Second
+)
+
+type ringKey struct {
+	record  *domain.SigningKey
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// KeyRing signs tokens with its newest key and verifies them with any key
+// that has not expired. The keys live in a SigningKeyRepository so that
+// every instance signs and verifies with the same ring.
+type KeyRing struct {
+	mu         sync.RWMutex
+	repository domain.SigningKeyRepository
+	algorithm  string
+	rotation   time.Duration
+	keys       map[string]*ringKey
+	ordered    []*ringKey
+	newest     *ringKey
+	lastReload time.Time
+}
+
+func (kr *KeyRing) SigningKey() (string, jwt.SigningMethod, crypto.PrivateKey, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	if kr.newest == nil {
+		return "", nil, nil, fmt.Errorf("no signing key is available")
+	}
+	return kr.newest.record.KeyID, kr.newest.method, kr.newest.private, nil
+}
+
+func (kr *KeyRing) VerificationKey(keyID string) (jwt.SigningMethod, crypto.PublicKey, error) {
+	kr.mu.RLock()
+	key, ok := kr.keys[keyID]
+	stale := time.Since(kr.lastReload) > minReloadInterval
+	kr.mu.RUnlock()
+
+	// another instance may have rotated since the last reload
+	if !ok && stale {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := kr.reload(ctx); err != nil {
+			return nil, nil, err
+		}
+		kr.mu.RLock()
+		key, ok = kr.keys[keyID]
+		kr.mu.RUnlock()
+	}
+	if !ok || !key.record.ExpiresAt.After(time.Now()) {
+		return nil, nil, fmt.Errorf("unknown signing key '%s'", keyID)
+	}
+	return key.method, key.public, nil
+}
+
+func (kr *KeyRing) Rotate(ctx context.Context) error {
+	if err := kr.reload(ctx); err != nil {
+		return err
+	}
+	kr.mu.RLock()
+	due := kr.newest == nil || !kr.newest.record.ActiveUntil.After(time.Now())
+	kr.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	record, err := generateSigningKey(kr.algorithm, kr.rotation)
+	if err != nil {
+		return err
+	}
+	if err := kr.repository.Create(ctx, record); err != nil {
+		return err
+	}
+	return kr.reload(ctx)
+}
+
+func (kr *KeyRing) JWKS() *domain.JSONWebKeySet {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	set := &domain.JSONWebKeySet{Keys: []domain.JSONWebKey{}}
+	for _, key := range kr.ordered {
+		jwk := domain.JSONWebKey{Use: "sig", Alg: key.record.Algorithm, Kid: key.record.KeyID}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// reload replaces the ring with the stored keys. Keys that cannot be parsed
+// are an error rather than skipped, since tokens signed with them would
+// suddenly fail.
+func (kr *KeyRing) reload(ctx context.Context) error {
+	records, err := kr.repository.FetchValid(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*ringKey, len(records))
+	ordered := make([]*ringKey, 0, len(records))
+	var newest *ringKey
+	for _, record := range records {
+		key, err := parseSigningKey(record)
+		if err != nil {
+			return err
+		}
+		keys[record.KeyID] = key
+		ordered = append(ordered, key)
+		// records come newest first; only keys of the configured algorithm sign
+		if newest == nil && record.Algorithm == kr.algorithm {
+			newest = key
+		}
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+	kr.keys = keys
+	kr.ordered = ordered
+	kr.newest = newest
+	kr.lastReload = time.Now()
+	return nil
+}
+
+func generateSigningKey(algorithm string, rotation time.Duration) (*domain.SigningKey, error) {
+	var private crypto.PrivateKey
+	var err error
+	switch algorithm {
+	case domain.AlgorithmRS256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case domain.AlgorithmEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("'%s' is not a supported signing algorithm", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	activeUntil := now.Add(rotation)
+	return &domain.SigningKey{
+		KeyID:       primitive.NewObjectID().Hex(),
+		Algorithm:   algorithm,
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		CreatedAt:   now,
+		ActiveUntil: activeUntil,
+		ExpiresAt:   activeUntil.Add(keyGracePeriod + domain.RefreshTokenLifetime),
+	}, nil
+}
+
+func parseSigningKey(record *domain.SigningKey) (*ringKey, error) {
+	block, _ := pem.Decode([]byte(record.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("signing key '%s' is not PEM encoded", record.KeyID)
+	}
+	private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing key '%s': %v", record.KeyID, err)
+	}
+
+	key := &ringKey{record: record, private: private}
+	switch private := private.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.public = jwt.SigningMethodRS256, &private.PublicKey
+	case ed25519.PrivateKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, private.Public()
+	default:
+		return nil, fmt.Errorf("signing key '%s' has an unsupported type", record.KeyID)
+	}
+	if key.method.Alg() != record.Algorithm {
+		return nil, fmt.Errorf("signing key '%s' is not an %s key", record.KeyID, record.Algorithm)
+	}
+	return key, nil
+}
+
+// NewKeyRing returns a ring that signs with algorithm (RS256 or EdDSA) and
+// replaces its signing key every rotation. Call Rotate to load the keys.
+func NewKeyRing(repository domain.SigningKeyRepository, algorithm string, rotation time.Duration) (*KeyRing, error) {
+	if algorithm != domain.AlgorithmRS256 && algorithm != domain.AlgorithmEdDSA {
+		return nil, fmt.Errorf("'%s' is not a supported signing algorithm, use RS256 or EdDSA", algorithm)
+	}
+	return &KeyRing{
+		repository: repository,
+		algorithm:  algorithm,
+		rotation:   rotation,
+		keys:       map[string]*ringKey{},
+	}, nil
+}
@@ -0,0 +1,132 @@
+package Intrastructures
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// MemoryLoginAttempts keeps the failed login counters in the process. It
+// suits a single instance; the counters are lost on restart.
+type MemoryLoginAttempts struct {
+	mu       sync.Mutex
+	attempts map[string]*domain.LoginAttempt
+}
+
+func (m *MemoryLoginAttempts) Fetch(ctx context.Context, scope string, value string) (*domain.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[scope+":"+value]
+	if !ok {
+		return nil, nil
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (m *MemoryLoginAttempts) Fail(ctx context.Context, scope string, value string, now time.Time, window time.Duration) (*domain.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, attempt := range m.attempts {
+		if !attempt.ExpiresAt.After(now) {
+			delete(m.attempts, key)
+		}
+	}
+	key := scope + ":" + value
+	attempt, ok := m.attempts[key]
+	if ok && !attempt.LastFailureAt.After(now.Add(-window)) && !attempt.RetryAt.After(now) {
+		ok = false
+	}
+	if !ok {
+		attempt = &domain.LoginAttempt{Scope: scope, Value: value, FirstFailureAt: now, RetryAt: now}
+		m.attempts[key] = attempt
+	}
+	attempt.Failures++
+	attempt.LastFailureAt = now
+	if expiresAt := now.Add(window); expiresAt.After(attempt.ExpiresAt) {
+		attempt.ExpiresAt = expiresAt
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (m *MemoryLoginAttempts) Refund(ctx context.Context, scope string, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if attempt, ok := m.attempts[scope+":"+value]; ok && attempt.Failures > 0 {
+		attempt.Failures--
+	}
+	return nil
+}
+
+func (m *MemoryLoginAttempts) Block(ctx context.Context, scope string, value string, until time.Time, locked bool, window time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[scope+":"+value]
+	if !ok {
+		return nil
+	}
+	if until.After(attempt.RetryAt) {
+		attempt.RetryAt = until
+	}
+	if expiresAt := until.Add(window); expiresAt.After(attempt.ExpiresAt) {
+		attempt.ExpiresAt = expiresAt
+	}
+	if locked && (attempt.LockedUntil == nil || until.After(*attempt.LockedUntil)) {
+		attempt.LockedUntil = &until
+	}
+	return nil
+}
+
+func (m *MemoryLoginAttempts) Reserve(ctx context.Context, scope string, value string, now time.Time, until time.Time, window time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	attempt, ok := m.attempts[scope+":"+value]
+	if !ok || attempt.RetryAt.After(now) {
+		return false, nil
+	}
+	attempt.RetryAt = until
+	if expiresAt := until.Add(window); expiresAt.After(attempt.ExpiresAt) {
+		attempt.ExpiresAt = expiresAt
+	}
+	return true, nil
+}
+
+func (m *MemoryLoginAttempts) FetchLocked(ctx context.Context, now time.Time) ([]*domain.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	locked := []*domain.LoginAttempt{}
+	for _, attempt := range m.attempts {
+		if attempt.Locked(now) {
+			copied := *attempt
+			locked = append(locked, &copied)
+		}
+	}
+	sort.Slice(locked, func(i, j int) bool {
+		return locked[i].LockedUntil.After(*locked[j].LockedUntil)
+	})
+	return locked, nil
+}
+
+func (m *MemoryLoginAttempts) Reset(ctx context.Context, scope string, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := scope + ":" + value
+	_, ok := m.attempts[key]
+	delete(m.attempts, key)
+	return ok, nil
+}
+
+func NewMemoryLoginAttempts() domain.LoginAttemptStore {
+	return &MemoryLoginAttempts{attempts: map[string]*domain.LoginAttempt{}}
+}
@@ -0,0 +1,59 @@
+package Intrastructures
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// MemoryTokenRevocation keeps revoked tokens and token generations in the
+// process. It suits a single instance; revocations are lost on restart.
+type MemoryTokenRevocation struct {
+	mu          sync.Mutex
+	revoked     map[string]time.Time
+	generations map[string]int
+}
+
+func (m *MemoryTokenRevocation) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, expiry := range m.revoked {
+		if !expiry.After(now) {
+			delete(m.revoked, id)
+		}
+	}
+	m.revoked[tokenID] = expiresAt
+	return nil
+}
+
+func (m *MemoryTokenRevocation) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expiry, ok := m.revoked[tokenID]
+	return ok && expiry.After(time.Now()), nil
+}
+
+func (m *MemoryTokenRevocation) Generation(ctx context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.generations[userID], nil
+}
+
+func (m *MemoryTokenRevocation) BumpGeneration(ctx context.Context, userID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.generations[userID]++
+	return m.generations[userID], nil
+}
+
+func NewMemoryTokenRevocation() domain.TokenRevocationStore {
+	return &MemoryTokenRevocation{
+		revoked:     map[string]time.Time{},
+		generations: map[string]int{},
+	}
+}
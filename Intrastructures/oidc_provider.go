@@ -0,0 +1,456 @@
+package Intrastructures
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// OIDCProvider is a client of one OpenID Connect provider. The discovery
+// document is fetched on first use and the provider's keys whenever an ID
+// token names a key that is not known yet.
+type OIDCProvider struct {
+	config *domain.OIDCProviderConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+func (op *OIDCProvider) Config() *domain.OIDCProviderConfig {
+	return op.config
+}
+
+func (op *OIDCProvider) AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	discovery, err := op.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", op.config.ClientID)
+	query.Set("redirect_uri", op.config.RedirectURL)
+	query.Set("scope", strings.Join(op.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String(), nil
+}
+
+func (op *OIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OIDCIdentity, error) {
+	discovery, err := op.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {op.config.RedirectURL},
+		"client_id":     {op.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	// public clients have no secret and rely on PKCE alone
+	if op.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(op.config.ClientID), url.QueryEscape(op.config.ClientSecret))
+	}
+	var tokens oidcTokenResponse
+	status, err := op.do(request, &tokens)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK || tokens.IDToken == "" {
+		if tokens.Error != "" {
+			return nil, fmt.Errorf("identity provider refused the code: %s %s", tokens.Error, tokens.ErrorDescription)
+		}
+		return nil, fmt.Errorf("identity provider answered the code exchange with status %d", status)
+	}
+
+	claims, err := op.verifyIDToken(ctx, tokens.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+	identity := claims.identity(op.config.GroupsClaim)
+	identity.Issuer = op.config.Issuer
+
+	// some providers only put the profile or groups in the userinfo response
+	if (identity.Email == "" || !claims.has(op.config.GroupsClaim)) && discovery.UserinfoEndpoint != "" && tokens.AccessToken != "" {
+		if err := op.userinfo(ctx, discovery.UserinfoEndpoint, tokens.AccessToken, identity); err != nil {
+			return nil, err
+		}
+	}
+	return identity, nil
+}
+
+// verifyIDToken checks the signature with the provider's keys, then the
+// claims: issuer, audience, expiry and the nonce of this login.
+func (op *OIDCProvider) verifyIDToken(ctx context.Context, idToken string, nonce string) (*idTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(idToken, &idTokenClaims{}, func(t *jwt.Token) (interface{}, error) {
+		keyID, _ := t.Header["kid"].(string)
+		key, err := op.verificationKey(ctx, keyID)
+		if err != nil {
+			return nil, err
+		}
+		// the algorithm must fit the key; HS256 with a public key as the
+		// secret must never verify
+		switch key.(type) {
+		case *rsa.PublicKey:
+			_, ok := t.Method.(*jwt.SigningMethodRSA)
+			_, pss := t.Method.(*jwt.SigningMethodRSAPSS)
+			if !ok && !pss {
+				return nil, fmt.Errorf("id token algorithm %s does not fit an RSA key", t.Method.Alg())
+			}
+		case *ecdsa.PublicKey:
+			if _, ok := t.Method.(*jwt.SigningMethodECDSA); !ok {
+				return nil, fmt.Errorf("id token algorithm %s does not fit an EC key", t.Method.Alg())
+			}
+		case ed25519.PublicKey:
+			if t.Method != jwt.SigningMethodEdDSA {
+				return nil, fmt.Errorf("id token algorithm %s does not fit an Ed25519 key", t.Method.Alg())
+			}
+		}
+		return key, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("id token is invalid: %v", err)
+	}
+	claims := token.Claims.(*idTokenClaims)
+
+	switch {
+	case claims.Issuer != op.config.Issuer:
+		return nil, fmt.Errorf("id token was issued by '%s', not '%s'", claims.Issuer, op.config.Issuer)
+	case !slices.Contains(claims.Audience, op.config.ClientID):
+		return nil, fmt.Errorf("id token is not meant for this client")
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != op.config.ClientID:
+		return nil, fmt.Errorf("id token was not issued to this client")
+	case claims.Nonce == "" || claims.Nonce != nonce:
+		return nil, fmt.Errorf("id token does not belong to this login")
+	case claims.Subject == "":
+		return nil, fmt.Errorf("id token has no subject")
+	}
+	return claims, nil
+}
+
+func (op *OIDCProvider) userinfo(ctx context.Context, endpoint string, accessToken string, identity *domain.OIDCIdentity) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	request.Header.Set("Accept", "application/json")
+
+	var info idTokenClaims
+	status, err := op.do(request, &info)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("identity provider answered userinfo with status %d", status)
+	}
+	// a response about another user must not be merged
+	if info.Subject != identity.Subject {
+		return fmt.Errorf("userinfo does not belong to the id token's subject")
+	}
+	extra := info.identity(op.config.GroupsClaim)
+	if identity.Email == "" {
+		identity.Email, identity.EmailVerified = extra.Email, extra.EmailVerified
+	}
+	if identity.Groups == nil {
+		identity.Groups = extra.Groups
+	}
+	return nil
+}
+
+func (op *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	if op.discovery != nil {
+		return op.discovery, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(op.config.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var discovery oidcDiscovery
+	status, err := op.do(request, &discovery)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("identity provider '%s' answered discovery with status %d", op.config.Name, status)
+	}
+	if discovery.Issuer != op.config.Issuer {
+		return nil, fmt.Errorf("identity provider '%s' calls itself '%s', not '%s'", op.config.Name, discovery.Issuer, op.config.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("identity provider '%s' does not publish the endpoints of the code flow", op.config.Name)
+	}
+	op.discovery = &discovery
+	return op.discovery, nil
+}
+
+// verificationKey refetches the provider's keys when the key is unknown,
+// since providers rotate keys without notice.
+func (op *OIDCProvider) verificationKey(ctx context.Context, keyID string) (crypto.PublicKey, error) {
+	discovery, err := op.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+	key, ok := op.lookupKey(keyID)
+	if !ok && time.Since(op.keysFetchedAt) > minReloadInterval {
+		keys, err := op.fetchKeys(ctx, discovery.JWKSURI)
+		if err != nil {
+			return nil, err
+		}
+		op.keys = keys
+		op.keysFetchedAt = time.Now()
+		key, ok = op.lookupKey(keyID)
+	}
+	if !ok {
+		return nil, fmt.Errorf("identity provider has no key '%s'", keyID)
+	}
+	return key, nil
+}
+
+// lookupKey accepts a token without a kid when the provider has one key.
+func (op *OIDCProvider) lookupKey(keyID string) (crypto.PublicKey, bool) {
+	if keyID == "" && len(op.keys) == 1 {
+		for _, key := range op.keys {
+			return key, true
+		}
+	}
+	key, ok := op.keys[keyID]
+	return key, ok
+}
+
+func (op *OIDCProvider) fetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set domain.JSONWebKeySet
+	status, err := op.do(request, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("identity provider answered the key request with status %d", status)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// keys of unknown types are skipped so one odd key does not
+		// break the login
+		if key, err := publicKey(jwk); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (op *OIDCProvider) do(request *http.Request, into interface{}) (int, error) {
+	response, err := op.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	if err := json.NewDecoder(response.Body).Decode(into); err != nil && response.StatusCode == http.StatusOK {
+		return response.StatusCode, fmt.Errorf("identity provider sent an unreadable response: %v", err)
+	}
+	return response.StatusCode, nil
+}
+
+func publicKey(jwk domain.JSONWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || jwk.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("unsupported OKP key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type '%s'", jwk.Kty)
+}
+
+// idTokenClaims keeps every claim, since the claim with the groups is
+// configurable.
+type idTokenClaims struct {
+	Issuer          string
+	Subject         string
+	Audience        []string
+	AuthorizedParty string
+	Nonce           string
+	ExpiresAt       int64
+	IssuedAt        int64
+	NotBefore       int64
+	raw             map[string]interface{}
+}
+
+func (c *idTokenClaims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.raw); err != nil {
+		return err
+	}
+	c.Issuer = c.text("iss")
+	c.Subject = c.text("sub")
+	c.AuthorizedParty = c.text("azp")
+	c.Nonce = c.text("nonce")
+	c.Audience = c.list("aud")
+	c.ExpiresAt = c.number("exp")
+	c.IssuedAt = c.number("iat")
+	c.NotBefore = c.number("nbf")
+	return nil
+}
+
+// Valid checks the time claims with the same leeway as our own tokens.
+func (c *idTokenClaims) Valid() error {
+	now := time.Now()
+	switch {
+	case c.ExpiresAt == 0 || now.Add(-domain.TokenClockSkew).Unix() > c.ExpiresAt:
+		return fmt.Errorf("token is expired")
+	case now.Add(domain.TokenClockSkew).Unix() < c.IssuedAt:
+		return fmt.Errorf("token was issued in the future")
+	case now.Add(domain.TokenClockSkew).Unix() < c.NotBefore:
+		return fmt.Errorf("token is not valid yet")
+	}
+	return nil
+}
+
+func (c *idTokenClaims) identity(groupsClaim string) *domain.OIDCIdentity {
+	verified, _ := c.raw["email_verified"].(bool)
+	identity := &domain.OIDCIdentity{
+		Subject:           c.Subject,
+		Email:             c.text("email"),
+		EmailVerified:     verified || c.text("email_verified") == "true",
+		PreferredUsername: c.text("preferred_username"),
+		GivenName:         c.text("given_name"),
+		FamilyName:        c.text("family_name"),
+		Name:              c.text("name"),
+	}
+	if c.has(groupsClaim) {
+		identity.Groups = c.list(groupsClaim)
+	}
+	return identity
+}
+
+func (c *idTokenClaims) has(claim string) bool {
+	_, ok := c.raw[claim]
+	return ok
+}
+
+func (c *idTokenClaims) text(claim string) string {
+	value, _ := c.raw[claim].(string)
+	return value
+}
+
+func (c *idTokenClaims) number(claim string) int64 {
+	value, _ := c.raw[claim].(float64)
+	return int64(value)
+}
+
+// list reads a claim that may be a single string or an array of strings.
+func (c *idTokenClaims) list(claim string) []string {
+	switch value := c.raw[claim].(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+	return []string{}
+}
+
+func NewOIDCProvider(config *domain.OIDCProviderConfig, timeout time.Duration) domain.OIDCProvider {
+	return &OIDCProvider{
+		config: config,
+		client: &http.Client{Timeout: timeout},
+		keys:   map[string]crypto.PublicKey{},
+	}
+}
@@ -0,0 +1,384 @@
+package Intrastructures
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const (
+	testClientID     = "task-manager"
+	testClientSecret = "s3cret"
+	testRedirectURL  = "https://tasks.example.com/api/users/oidc/corp/callback"
+	testKeyID        = "key-1"
+)
+
+// fakeIdentityProvider is a minimal OpenID Connect provider. It hands out
+// one code, checks the PKCE verifier against the challenge it was given and
+// answers with whatever ID token the test put in.
+type fakeIdentityProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu        sync.Mutex
+	challenge string
+	idToken   string
+	userinfo  map[string]interface{}
+	tokenForm url.Values
+	basicAuth [2]string
+}
+
+func newFakeIdentityProvider(t *testing.T) *fakeIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeIdentityProvider{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 fake.server.URL,
+			"authorization_endpoint": fake.server.URL + "/authorize",
+			"token_endpoint":         fake.server.URL + "/token",
+			"userinfo_endpoint":      fake.server.URL + "/userinfo",
+			"jwks_uri":               fake.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(domain.JSONWebKeySet{Keys: []domain.JSONWebKey{{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: "RS256",
+			Kid: testKeyID,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		r.ParseForm()
+		fake.tokenForm = r.PostForm
+		user, password, _ := r.BasicAuth()
+		fake.basicAuth = [2]string{user, password}
+
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != fake.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "code or verifier is wrong"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": fake.idToken, "access_token": "the-access-token"})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer the-access-token" || fake.userinfo == nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(fake.userinfo)
+	})
+	fake.server = httptest.NewServer(mux)
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeIdentityProvider) config() *domain.OIDCProviderConfig {
+	return &domain.OIDCProviderConfig{
+		Name:         "corp",
+		Issuer:       f.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+		GroupsClaim:  "groups",
+		GroupRoles:   map[string]string{},
+		DefaultRole:  "USER",
+	}
+}
+
+// claims are the claims of a valid ID token for nonce.
+func (f *fakeIdentityProvider) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            f.server.URL,
+		"sub":            "subject-1",
+		"aud":            testClientID,
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"groups":         []string{"staff"},
+	}
+}
+
+func (f *fakeIdentityProvider) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(f.key)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return signed
+}
+
+// begin starts a login like the usecase does and returns the verifier.
+func (f *fakeIdentityProvider) begin(provider domain.OIDCProvider, nonce string) string {
+	verifier := "verifier-" + nonce
+	sum := sha256.Sum256([]byte(verifier))
+	authorizationURL, err := provider.AuthorizationURL(context.Background(), "the-state", nonce, base64.RawURLEncoding.EncodeToString(sum[:]))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.mu.Lock()
+	f.challenge = parsed.Query().Get("code_challenge")
+	f.mu.Unlock()
+	return verifier
+}
+
+func (f *fakeIdentityProvider) issue(claims jwt.MapClaims) {
+	f.mu.Lock()
+	f.idToken = f.sign(claims)
+	f.mu.Unlock()
+}
+
+func TestOIDCProviderAuthorizationURL(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+
+	authorizationURL, err := provider.AuthorizationURL(context.Background(), "the-state", "the-nonce", "the-challenge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(authorizationURL, fake.server.URL+"/authorize?") {
+		t.Errorf("authorization url %s does not use the discovered endpoint", authorizationURL)
+	}
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"scope":                 "openid profile email",
+		"state":                 "the-state",
+		"nonce":                 "the-nonce",
+		"code_challenge":        "the-challenge",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := parsed.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestOIDCProviderDiscoveryIssuerMismatch(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	config := fake.config()
+	config.Issuer = fake.server.URL + "/"
+	provider := NewOIDCProvider(config, 5*time.Second)
+
+	_, err := provider.AuthorizationURL(context.Background(), "the-state", "the-nonce", "the-challenge")
+	if err == nil || !strings.Contains(err.Error(), "calls itself") {
+		t.Fatalf("err = %v, want an issuer mismatch", err)
+	}
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+	verifier := fake.begin(provider, "the-nonce")
+	claims := fake.claims("the-nonce")
+	claims["preferred_username"] = "alice"
+	claims["given_name"] = "Alice"
+	claims["family_name"] = "Liddell"
+	fake.issue(claims)
+
+	identity, err := provider.Exchange(context.Background(), "the-code", verifier, "the-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Issuer != fake.server.URL || identity.Subject != "subject-1" {
+		t.Errorf("identity = %s %s, want %s subject-1", identity.Issuer, identity.Subject, fake.server.URL)
+	}
+	if identity.Email != "alice@example.com" || !identity.EmailVerified {
+		t.Errorf("email = %q verified %v, want a verified alice@example.com", identity.Email, identity.EmailVerified)
+	}
+	if identity.PreferredUsername != "alice" || identity.GivenName != "Alice" || identity.FamilyName != "Liddell" {
+		t.Errorf("profile = %+v", identity)
+	}
+	if !slices.Equal(identity.Groups, []string{"staff"}) {
+		t.Errorf("groups = %v, want [staff]", identity.Groups)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.tokenForm.Get("grant_type") != "authorization_code" || fake.tokenForm.Get("redirect_uri") != testRedirectURL {
+		t.Errorf("token request = %v", fake.tokenForm)
+	}
+	if fake.basicAuth != [2]string{testClientID, testClientSecret} {
+		t.Errorf("client authentication = %v", fake.basicAuth)
+	}
+}
+
+func TestOIDCProviderExchangeWrongVerifier(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+	fake.begin(provider, "the-nonce")
+	fake.issue(fake.claims("the-nonce"))
+
+	_, err := provider.Exchange(context.Background(), "the-code", "someone-elses-verifier", "the-nonce")
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("err = %v, want the provider's invalid_grant", err)
+	}
+}
+
+func TestOIDCProviderRejectsIDTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(claims jwt.MapClaims)
+		want   string
+	}{
+		{"wrong nonce", func(claims jwt.MapClaims) { claims["nonce"] = "another-login" }, "does not belong to this login"},
+		{"no nonce", func(claims jwt.MapClaims) { delete(claims, "nonce") }, "does not belong to this login"},
+		{"other audience", func(claims jwt.MapClaims) { claims["aud"] = "another-app" }, "not meant for this client"},
+		{"several audiences without azp", func(claims jwt.MapClaims) { claims["aud"] = []string{testClientID, "another-app"} }, "not issued to this client"},
+		{"several audiences with other azp", func(claims jwt.MapClaims) {
+			claims["aud"] = []string{testClientID, "another-app"}
+			claims["azp"] = "another-app"
+		}, "not issued to this client"},
+		{"other issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }, "was issued by"},
+		{"expired", func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }, "expired"},
+		{"no subject", func(claims jwt.MapClaims) { delete(claims, "sub") }, "no subject"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := newFakeIdentityProvider(t)
+			provider := NewOIDCProvider(fake.config(), 5*time.Second)
+			verifier := fake.begin(provider, "the-nonce")
+			claims := fake.claims("the-nonce")
+			test.change(claims)
+			fake.issue(claims)
+
+			_, err := provider.Exchange(context.Background(), "the-code", verifier, "the-nonce")
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("err = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestOIDCProviderAcceptsAuthorizedParty(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+	verifier := fake.begin(provider, "the-nonce")
+	claims := fake.claims("the-nonce")
+	claims["aud"] = []string{testClientID, "another-app"}
+	claims["azp"] = testClientID
+	fake.issue(claims)
+
+	if _, err := provider.Exchange(context.Background(), "the-code", verifier, "the-nonce"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOIDCProviderRejectsForeignSignatures(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+	verifier := fake.begin(provider, "the-nonce")
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, fake.claims("the-nonce"))
+	token.Header["kid"] = testKeyID
+	forged, err := token.SignedString(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// HS256 with the provider's public key as the secret
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, fake.claims("the-nonce"))
+	confused.Header["kid"] = testKeyID
+	hmacForged, err := confused.SignedString(fake.key.PublicKey.N.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, idToken := range map[string]string{"other key": forged, "HS256": hmacForged} {
+		fake.mu.Lock()
+		fake.idToken = idToken
+		fake.mu.Unlock()
+		if _, err := provider.Exchange(context.Background(), "the-code", verifier, "the-nonce"); err == nil {
+			t.Errorf("%s: forged id token was accepted", name)
+		}
+	}
+}
+
+func TestOIDCProviderUserinfo(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+	verifier := fake.begin(provider, "the-nonce")
+	claims := fake.claims("the-nonce")
+	delete(claims, "email")
+	delete(claims, "email_verified")
+	delete(claims, "groups")
+	fake.issue(claims)
+	fake.userinfo = map[string]interface{}{
+		"sub":            "subject-1",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"groups":         []string{"task-admins"},
+	}
+
+	identity, err := provider.Exchange(context.Background(), "the-code", verifier, "the-nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Email != "alice@example.com" || !identity.EmailVerified {
+		t.Errorf("email = %q verified %v, want it from userinfo", identity.Email, identity.EmailVerified)
+	}
+	if !slices.Equal(identity.Groups, []string{"task-admins"}) {
+		t.Errorf("groups = %v, want them from userinfo", identity.Groups)
+	}
+}
+
+func TestOIDCProviderUserinfoSubjectMismatch(t *testing.T) {
+	fake := newFakeIdentityProvider(t)
+	provider := NewOIDCProvider(fake.config(), 5*time.Second)
+	verifier := fake.begin(provider, "the-nonce")
+	claims := fake.claims("the-nonce")
+	delete(claims, "email")
+	fake.issue(claims)
+	fake.userinfo = map[string]interface{}{"sub": "subject-2", "email": "mallory@example.com", "email_verified": true}
+
+	_, err := provider.Exchange(context.Background(), "the-code", verifier, "the-nonce")
+	if err == nil || !strings.Contains(err.Error(), "does not belong to the id token's subject") {
+		t.Fatalf("err = %v, want a subject mismatch", err)
+	}
+}
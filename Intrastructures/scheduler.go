@@ -0,0 +1,25 @@
+package Intrastructures
+
+import (
+	"log"
+	"time"
+)
+
+// RunPeriodically calls job every interval in the background. A panicking
+// job is logged and does not stop later runs.
+func RunPeriodically(interval time.Duration, job func()) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						log.Println("periodic job failed:", r)
+					}
+				}()
+				job()
+			}()
+		}
+	}()
+}
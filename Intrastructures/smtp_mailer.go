@@ -0,0 +1,124 @@
+package Intrastructures
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// SMTPMailer sends multipart text and HTML emails through an SMTP relay.
+// Authentication is skipped when no username is configured, which is what
+// local SMTP stand-ins expect.
+type SMTPMailer struct {
+	address  string
+	host     string
+	username string
+	password string
+	from     string
+	sender   string
+}
+
+func (sm *SMTPMailer) Send(message *domain.MailMessage) error {
+	if len(message.To) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+	var auth smtp.Auth
+	if sm.username != "" {
+		auth = smtp.PlainAuth("", sm.username, sm.password, sm.host)
+	}
+	body, err := sm.compose(message)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(sm.address, auth, sm.sender, message.To, body)
+}
+
+func (sm *SMTPMailer) compose(message *domain.MailMessage) ([]byte, error) {
+	for _, value := range append([]string{sm.from, message.Subject}, message.To...) {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("email headers cannot contain line breaks")
+		}
+	}
+	token := make([]byte, 12)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	boundary := "alternative-" + hex.EncodeToString(token)
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "From: %s\r\n", sm.from)
+	fmt.Fprintf(&buffer, "To: %s\r\n", strings.Join(message.To, ", "))
+	fmt.Fprintf(&buffer, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buffer, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buffer.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buffer, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain", message.TextBody},
+		{"text/html", message.HTMLBody},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&buffer, "--%s\r\n", boundary)
+		fmt.Fprintf(&buffer, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		buffer.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writer := quotedprintable.NewWriter(&buffer)
+		if _, err := writer.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		buffer.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buffer, "--%s--\r\n", boundary)
+	return buffer.Bytes(), nil
+}
+
+// LogMailer logs the recipients and subject of emails instead of sending
+// them. It is used when no SMTP host is configured. The body is left out
+// because it can hold password reset and verification tokens.
+type LogMailer struct{}
+
+func (lm *LogMailer) Send(message *domain.MailMessage) error {
+	log.Printf("email to %s not sent, no SMTP_HOST: %s", strings.Join(message.To, ", "), message.Subject)
+	return nil
+}
+
+// NewMailer reads SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and
+// SMTP_FROM. Without SMTP_HOST emails are only logged, without their body.
+func NewMailer() domain.MailSender {
+	host := GetFromEnv("SMTP_HOST")
+	if host == "" {
+		return &LogMailer{}
+	}
+	port := GetFromEnv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from, err := mail.ParseAddress(GetFromEnv("SMTP_FROM"))
+	if err != nil {
+		log.Fatal("SMTP_FROM must be an email address when SMTP_HOST is set")
+	}
+	return &SMTPMailer{
+		address:  net.JoinHostPort(host, port),
+		host:     host,
+		username: GetFromEnv("SMTP_USERNAME"),
+		password: GetFromEnv("SMTP_PASSWORD"),
+		from:     from.String(),
+		sender:   from.Address,
+	}
+}
@@ -0,0 +1,266 @@
+package Intrastructures
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// smtpDelivery is what the fake SMTP server received.
+type smtpDelivery struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// fakeSMTPServer accepts SMTP connections without TLS or authentication and
+// reports every delivery on the returned channel.
+func fakeSMTPServer(t *testing.T) (string, <-chan smtpDelivery) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	deliveries := make(chan smtpDelivery, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, deliveries)
+		}
+	}()
+	return listener.Addr().String(), deliveries
+}
+
+func serveSMTP(conn net.Conn, deliveries chan<- smtpDelivery) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost fake SMTP")
+
+	var delivery smtpDelivery
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			text.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			delivery = smtpDelivery{from: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			delivery.recipients = append(delivery.recipients, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 go ahead")
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			delivery.data = string(data)
+			deliveries <- delivery
+			text.PrintfLine("250 queued")
+		case command == "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+func receiveEmail(t *testing.T, deliveries <-chan smtpDelivery) smtpDelivery {
+	t.Helper()
+	select {
+	case delivery := <-deliveries:
+		return delivery
+	case <-time.After(5 * time.Second):
+		t.Fatal("the SMTP server got no email")
+	}
+	return smtpDelivery{}
+}
+
+func testMailer(address string) *SMTPMailer {
+	host, _, _ := net.SplitHostPort(address)
+	return &SMTPMailer{
+		address: address,
+		host:    host,
+		from:    `"Task Manager" <noreply@example.com>`,
+		sender:  "noreply@example.com",
+	}
+}
+
+func TestSMTPMailerSendsMultipartQuotedPrintable(t *testing.T) {
+	address, deliveries := fakeSMTPServer(t)
+	mailer := testMailer(address)
+
+	longLine := strings.Repeat("lorem ipsum ", 20)
+	message := &domain.MailMessage{
+		To:       []string{"alice@example.com", "bob@example.com"},
+		Subject:  "Tâche assignée: résumé",
+		TextBody: "Hi Alice,\n\nÜberprüfe die Aufgabe = bitte.\n" + longLine,
+		HTMLBody: "<p>Hi Alice,</p><p>Überprüfe <strong>die Aufgabe</strong></p>",
+	}
+	if err := mailer.Send(message); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := receiveEmail(t, deliveries)
+	if delivery.from != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %s, want the bare sender address", delivery.from)
+	}
+	if strings.Join(delivery.recipients, ",") != "alice@example.com,bob@example.com" {
+		t.Errorf("RCPT TO = %v", delivery.recipients)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(delivery.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != message.Subject {
+		t.Errorf("subject = %q (%v), want %q", subject, err, message.Subject)
+	}
+	if parsed.Header.Get("From") != mailer.from || parsed.Header.Get("To") != "alice@example.com, bob@example.com" {
+		t.Errorf("from %q to %q", parsed.Header.Get("From"), parsed.Header.Get("To"))
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %s (%v), want multipart/alternative", mediaType, err)
+	}
+
+	// the body lines stay short and ASCII, whatever the body holds; the
+	// reader of DATA has turned CRLF into LF
+	_, body, _ := strings.Cut(delivery.data, "\n\n")
+	for _, line := range strings.Split(body, "\n") {
+		if len(line) > 76 {
+			t.Errorf("line longer than 76 characters: %q", line)
+		}
+		for _, r := range line {
+			if r > 127 {
+				t.Errorf("non-ASCII on the wire: %q", line)
+				break
+			}
+		}
+	}
+
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	want := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", message.TextBody},
+		{"text/html; charset=utf-8", message.HTMLBody},
+	}
+	for _, part := range want {
+		next, err := reader.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next.Header.Get("Content-Type") != part.contentType {
+			t.Errorf("part content type = %s, want %s", next.Header.Get("Content-Type"), part.contentType)
+		}
+		// NextPart decodes quoted-printable and drops the header
+		body, err := io.ReadAll(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != part.body {
+			t.Errorf("%s body = %q, want %q", part.contentType, body, part.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("want two parts, got more (%v)", err)
+	}
+}
+
+func TestSMTPMailerSkipsEmptyParts(t *testing.T) {
+	address, deliveries := fakeSMTPServer(t)
+	if err := testMailer(address).Send(&domain.MailMessage{To: []string{"alice@example.com"}, Subject: "Text only", TextBody: "plain"}); err != nil {
+		t.Fatal(err)
+	}
+	delivery := receiveEmail(t, deliveries)
+	if strings.Contains(delivery.data, "text/html") {
+		t.Error("an empty HTML body was sent")
+	}
+}
+
+func TestSMTPMailerRejectsHeaderInjection(t *testing.T) {
+	address, deliveries := fakeSMTPServer(t)
+	mailer := testMailer(address)
+
+	messages := map[string]*domain.MailMessage{
+		"subject":   {To: []string{"alice@example.com"}, Subject: "Hello\r\nBcc: mallory@example.com", TextBody: "hi"},
+		"recipient": {To: []string{"alice@example.com\nBcc: mallory@example.com"}, Subject: "Hello", TextBody: "hi"},
+	}
+	for name, message := range messages {
+		err := mailer.Send(message)
+		if err == nil || !strings.Contains(err.Error(), "line breaks") {
+			t.Errorf("%s: err = %v, want the line breaks to be refused", name, err)
+		}
+	}
+	if err := mailer.Send(&domain.MailMessage{Subject: "Hello", TextBody: "hi"}); err == nil {
+		t.Error("an email without recipients was sent")
+	}
+
+	select {
+	case delivery := <-deliveries:
+		t.Errorf("the SMTP server got an email: %q", delivery.data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSMTPMailerSurfacesServerErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		writer := bufio.NewWriter(conn)
+		writer.WriteString("554 no service\r\n")
+		writer.Flush()
+	}()
+
+	err = testMailer(listener.Addr().String()).Send(&domain.MailMessage{To: []string{"alice@example.com"}, Subject: "Hello", TextBody: "hi"})
+	if err == nil {
+		t.Fatal("a refused connection was reported as sent")
+	}
+}
+
+func TestLogMailerLeavesOutTheBody(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	message := &domain.MailMessage{To: []string{"alice@example.com"}, Subject: "Reset your password", TextBody: "token: secret-reset-token"}
+	if err := (&LogMailer{}).Send(message); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(output.String(), "alice@example.com") || !strings.Contains(output.String(), message.Subject) {
+		t.Errorf("log = %q, want the recipient and subject", output.String())
+	}
+	if strings.Contains(output.String(), "secret-reset-token") {
+		t.Errorf("log = %q, the token must not be logged", output.String())
+	}
+}
@@ -0,0 +1,29 @@
+package Intrastructures
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// CallerLocation stores the time zone responses are rendered in as
+// "location". The tz query parameter or the X-Timezone header pick one
+// explicitly; otherwise the caller's calendar decides.
+func CallerLocation(calendarUsecase domain.CalendarUsecase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		override := ctx.Query("tz")
+		if override == "" {
+			override = ctx.GetHeader("X-Timezone")
+		}
+
+		location, err := calendarUsecase.Location(ctx, ctx.GetString("user_id"), override)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			ctx.Abort()
+			return
+		}
+		ctx.Set("location", location)
+		ctx.Next()
+	}
+}
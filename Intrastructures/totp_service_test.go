@@ -0,0 +1,69 @@
+package Intrastructures
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of RFC 6238 Appendix B, "12345678901234567890".
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// Appendix B lists 8 digit codes; the 6 digit code is the last six
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, test := range tests {
+		want := test.code[2:]
+		if got := totpCode([]byte("12345678901234567890"), test.unix/totpPeriod); got != want {
+			t.Errorf("totpCode at %d = %s, want %s", test.unix, got, want)
+		}
+		step, ok := NewTOTPProvider().Validate(rfc6238Secret, want, time.Unix(test.unix, 0))
+		if !ok || step != test.unix/totpPeriod {
+			t.Errorf("Validate at %d = %d %v, want step %d", test.unix, step, ok, test.unix/totpPeriod)
+		}
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	key := []byte("12345678901234567890")
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		step   int64
+		ok     bool
+	}{
+		{"current step", rfc6238Secret, totpCode(key, current), current, true},
+		{"previous step", rfc6238Secret, totpCode(key, current-1), current - 1, true},
+		{"next step", rfc6238Secret, totpCode(key, current+1), current + 1, true},
+		{"two steps ago", rfc6238Secret, totpCode(key, current-2), 0, false},
+		{"two steps ahead", rfc6238Secret, totpCode(key, current+2), 0, false},
+		{"spaces", rfc6238Secret, "050 471", current, true},
+		{"lower case secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "050471", current, true},
+		{"too short", rfc6238Secret, "50471", 0, false},
+		{"too long", rfc6238Secret, "14050471", 0, false},
+		{"wrong code", rfc6238Secret, "000000", 0, false},
+		{"bad secret", "not base32!", "050471", 0, false},
+	}
+	provider := NewTOTPProvider()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			step, ok := provider.Validate(test.secret, test.code, now)
+			if ok != test.ok || step != test.step {
+				t.Errorf("Validate(%q) = %d %v, want %d %v", test.code, step, ok, test.step, test.ok)
+			}
+		})
+	}
+}
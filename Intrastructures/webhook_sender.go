@@ -0,0 +1,116 @@
+package Intrastructures
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// blockedWebhookRanges are ranges the standard library does not classify but
+// that still do not belong to the public internet.
+var blockedWebhookRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// HTTPWebhookSender only talks to public addresses, so rules cannot be used
+// to reach the app's own network or a cloud metadata endpoint.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+func (ws *HTTPWebhookSender) Send(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := ws.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// CheckURL resolves the host now. Send checks the address again when it
+// connects, because the name may resolve differently by then.
+func (ws *HTTPWebhookSender) CheckURL(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return fmt.Errorf("webhook url must be an absolute http(s) url")
+	}
+	addresses, err := net.DefaultResolver.LookupNetIP(ctx, "ip", target.Hostname())
+	if err != nil || len(addresses) == 0 {
+		return fmt.Errorf("webhook host '%s' cannot be resolved", target.Hostname())
+	}
+	for _, address := range addresses {
+		if !publicAddress(address) {
+			return fmt.Errorf("webhook host '%s' is not a public address", target.Hostname())
+		}
+	}
+	return nil
+}
+
+// refusePrivateAddresses runs for every connection, after the name was
+// resolved and also for redirects.
+func refusePrivateAddresses(network string, address string, _ syscall.RawConn) error {
+	hostPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(hostPort.Addr()) {
+		return fmt.Errorf("webhook address %s is not public", hostPort.Addr())
+	}
+	return nil
+}
+
+func publicAddress(address netip.Addr) bool {
+	address = address.Unmap()
+	if address.IsLoopback() || address.IsPrivate() || address.IsUnspecified() ||
+		address.IsLinkLocalUnicast() || address.IsLinkLocalMulticast() ||
+		address.IsInterfaceLocalMulticast() || address.IsMulticast() {
+		return false
+	}
+	for _, prefix := range blockedWebhookRanges {
+		if prefix.Contains(address) {
+			return false
+		}
+	}
+	return true
+}
+
+func NewWebhookSender(timeout time.Duration) domain.WebhookSender {
+	dialer := &net.Dialer{Timeout: timeout, Control: refusePrivateAddresses}
+	return &HTTPWebhookSender{
+		client: &http.Client{
+			Timeout: timeout,
+			// no proxy, so the check sees the address that is really dialled
+			Transport: &http.Transport{
+				Proxy:               nil,
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: timeout,
+			},
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type apiKeyRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.ApiKeyRepository.
+func (ar *apiKeyRepository) Create(ctx context.Context, key *domain.ApiKey) error {
+	collection := ar.database.Collection(ar.collection)
+	_, err := collection.InsertOne(ctx, key)
+	return err
+}
+
+// FetchByHash implements domains.ApiKeyRepository.
+func (ar *apiKeyRepository) FetchByHash(ctx context.Context, hash string) (*domain.ApiKey, error) {
+	collection := ar.database.Collection(ar.collection)
+
+	var key *domain.ApiKey
+	err := collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("no api key found")
+	}
+	return key, err
+}
+
+// FetchActive implements domains.ApiKeyRepository. The newest key comes
+// first.
+func (ar *apiKeyRepository) FetchActive(ctx context.Context, kind string, userID string) ([]*domain.ApiKey, error) {
+	collection := ar.database.Collection(ar.collection)
+
+	filter := bson.M{"kind": kind, "revoked_at": nil, "expires_at": bson.M{"$gt": time.Now()}}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []*domain.ApiKey{}
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke implements domains.ApiKeyRepository. Users can only revoke their
+// own personal keys.
+func (ar *apiKeyRepository) Revoke(ctx context.Context, kind string, userID string, keyID string) error {
+	collection := ar.database.Collection(ar.collection)
+
+	filter := bson.M{"key_id": keyID, "kind": kind, "revoked_at": nil}
+	if userID != "" {
+		filter["user_id"] = userID
+	}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"revoked_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no api key found with id '%s'", keyID)
+	}
+	return nil
+}
+
+// Touch implements domains.ApiKeyRepository.
+func (ar *apiKeyRepository) Touch(ctx context.Context, keyID string, usedAt time.Time) error {
+	collection := ar.database.Collection(ar.collection)
+	_, err := collection.UpdateOne(ctx, bson.M{"key_id": keyID}, bson.M{"$set": bson.M{"last_used_at": usedAt}})
+	return err
+}
+
+func (ar *apiKeyRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := ar.database.Collection(ar.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "kind", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Println("error creating api key indexes:", err)
+	}
+}
+
+func NewApiKeyRepository(db mongo.Database, collection string) domain.ApiKeyRepository {
+	repository := &apiKeyRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type auditRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.AuditRepository.
+func (ar *auditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	collection := ar.database.Collection(ar.collection)
+	_, err := collection.InsertOne(ctx, event)
+	return err
+}
+
+// FetchPage implements domains.AuditRepository.
+func (ar *auditRepository) FetchPage(ctx context.Context, eventType string, page int64, limit int64) (*domain.AuditPage, error) {
+	collection := ar.database.Collection(ar.collection)
+
+	filter := bson.M{}
+	if eventType != "" {
+		filter["type"] = eventType
+	}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "occurred_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	events := []*domain.AuditEvent{}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return &domain.AuditPage{
+		Events: events,
+		Page:   page,
+		Limit:  limit,
+		Total:  total,
+	}, nil
+}
+
+func (ar *auditRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := ar.database.Collection(ar.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "occurred_at", Value: -1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "occurred_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Println("error creating audit event indexes:", err)
+	}
+}
+
+func NewAuditRepository(db mongo.Database, collection string) domain.AuditRepository {
+	repository := &auditRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type boardRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.BoardRepository.
+func (br *boardRepository) Create(ctx context.Context, board *domain.Board) error {
+	collection := br.database.Collection(br.collection)
+	_, err := collection.InsertOne(ctx, board)
+	return err
+}
+
+// FetchAll implements domains.BoardRepository.
+func (br *boardRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.Board, error) {
+	collection := br.database.Collection(br.collection)
+
+	filter := bson.M{}
+	if projectID != "" {
+		filter["project_id"] = projectID
+	}
+
+	boards := []*domain.Board{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &boards); err != nil {
+		return nil, err
+	}
+	return boards, nil
+}
+
+// FetchById implements domains.BoardRepository.
+func (br *boardRepository) FetchById(ctx context.Context, boardID string) (*domain.Board, error) {
+	collection := br.database.Collection(br.collection)
+
+	var board *domain.Board
+	if err := collection.FindOne(ctx, bson.M{"board_id": boardID}).Decode(&board); err != nil {
+		return nil, fmt.Errorf("no board found with id '%s'", boardID)
+	}
+	return board, nil
+}
+
+// UpdateById implements domains.BoardRepository.
+func (br *boardRepository) UpdateById(ctx context.Context, boardID string, board *domain.Board) error {
+	collection := br.database.Collection(br.collection)
+
+	settingStage := bson.M{"$set": bson.M{
+		"name":       board.Name,
+		"project_id": board.ProjectID,
+		"columns":    board.Columns,
+		"updated_at": board.UpdatedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, bson.M{"board_id": boardID}, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no board found with id '%s'", boardID)
+	}
+	return nil
+}
+
+// DeleteById implements domains.BoardRepository.
+func (br *boardRepository) DeleteById(ctx context.Context, boardID string) error {
+	collection := br.database.Collection(br.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"board_id": boardID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no board found with id '%s'", boardID)
+	}
+	return nil
+}
+
+// Lock implements domains.BoardRepository.
+func (br *boardRepository) Lock(ctx context.Context, boardID string) error {
+	collection := br.database.Collection(br.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"board_id": boardID}, bson.M{"$inc": bson.M{"move_version": 1}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no board found with id '%s'", boardID)
+	}
+	return nil
+}
+
+func NewBoardRepository(db mongo.Database, collection string) domain.BoardRepository {
+	return &boardRepository{
+		database:   db,
+		collection: collection,
+	}
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type calendarRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// FetchMany implements domains.CalendarRepository. Owners without a stored
+// calendar are missing from the result.
+func (cr *calendarRepository) FetchMany(ctx context.Context, ownerType string, ownerIDs []string) (map[string]*domain.WorkingCalendar, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	cursor, err := collection.Find(ctx, bson.M{"owner_type": ownerType, "owner_id": bson.M{"$in": ownerIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var found []*domain.WorkingCalendar
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	calendars := make(map[string]*domain.WorkingCalendar, len(found))
+	for _, calendar := range found {
+		calendars[calendar.OwnerID] = calendar
+	}
+	return calendars, nil
+}
+
+// Upsert implements domains.CalendarRepository.
+func (cr *calendarRepository) Upsert(ctx context.Context, calendar *domain.WorkingCalendar) error {
+	collection := cr.database.Collection(cr.collection)
+
+	filter := bson.M{"owner_type": calendar.OwnerType, "owner_id": calendar.OwnerID}
+	_, err := collection.ReplaceOne(ctx, filter, calendar, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (cr *calendarRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := cr.database.Collection(cr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner_type", Value: 1}, {Key: "owner_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating calendar index:", err)
+	}
+}
+
+func NewCalendarRepository(db mongo.Database, collection string) domain.CalendarRepository {
+	repository := &calendarRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type capacityRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Fetch implements domains.CapacityRepository. Users who never declared a
+// capacity get the default one.
+func (cr *capacityRepository) Fetch(ctx context.Context, userID string) (*domain.Capacity, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	var capacity *domain.Capacity
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&capacity)
+	if err == mongo.ErrNoDocuments {
+		return defaultCapacity(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return capacity, nil
+}
+
+// FetchMany implements domains.CapacityRepository. Every requested user is in
+// the result, with the default capacity when none was declared.
+func (cr *capacityRepository) FetchMany(ctx context.Context, userIDs []string) (map[string]*domain.Capacity, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var found []*domain.Capacity
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	capacities := make(map[string]*domain.Capacity, len(userIDs))
+	for _, userID := range userIDs {
+		capacities[userID] = defaultCapacity(userID)
+	}
+	for _, capacity := range found {
+		capacities[capacity.UserID] = capacity
+	}
+	return capacities, nil
+}
+
+// SetWeeklyMinutes implements domains.CapacityRepository.
+func (cr *capacityRepository) SetWeeklyMinutes(ctx context.Context, userID string, minutes int) error {
+	collection := cr.database.Collection(cr.collection)
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$set":         bson.M{"weekly_minutes": minutes, "updated_at": time.Now()},
+			"$setOnInsert": bson.M{"time_off": []domain.TimeOff{}},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// AddTimeOff implements domains.CapacityRepository.
+func (cr *capacityRepository) AddTimeOff(ctx context.Context, userID string, timeOff *domain.TimeOff) error {
+	collection := cr.database.Collection(cr.collection)
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"user_id": userID},
+		bson.M{
+			"$push":        bson.M{"time_off": bson.M{"$each": bson.A{timeOff}, "$sort": bson.M{"from": 1}}},
+			"$set":         bson.M{"updated_at": time.Now()},
+			"$setOnInsert": bson.M{"weekly_minutes": domain.DefaultWeeklyCapacityMinutes},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// RemoveTimeOff implements domains.CapacityRepository.
+func (cr *capacityRepository) RemoveTimeOff(ctx context.Context, userID string, timeOffID string) error {
+	collection := cr.database.Collection(cr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "time_off.time_off_id": timeOffID},
+		bson.M{
+			"$pull": bson.M{"time_off": bson.M{"time_off_id": timeOffID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no time off found with id '%s'", timeOffID)
+	}
+	return nil
+}
+
+func (cr *capacityRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := cr.database.Collection(cr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating capacity index:", err)
+	}
+}
+
+func defaultCapacity(userID string) *domain.Capacity {
+	return &domain.Capacity{UserID: userID, WeeklyMinutes: domain.DefaultWeeklyCapacityMinutes, TimeOff: []domain.TimeOff{}}
+}
+
+func NewCapacityRepository(db mongo.Database, collection string) domain.CapacityRepository {
+	repository := &capacityRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
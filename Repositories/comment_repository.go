@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type commentRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.CommentRepository.
+func (cr *commentRepository) Create(ctx context.Context, comment *domain.Comment) error {
+	collection := cr.database.Collection(cr.collection)
+	_, err := collection.InsertOne(ctx, comment)
+	return err
+}
+
+// FetchByTask implements domains.CommentRepository. Comments are returned oldest first.
+func (cr *commentRepository) FetchByTask(ctx context.Context, taskID string) ([]*domain.Comment, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	comments := []*domain.Comment{}
+	cursor, err := collection.Find(ctx, bson.M{"task_id": taskID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// FetchById implements domains.CommentRepository.
+func (cr *commentRepository) FetchById(ctx context.Context, commentID string) (*domain.Comment, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	var comment *domain.Comment
+	if err := collection.FindOne(ctx, bson.M{"comment_id": commentID}).Decode(&comment); err != nil {
+		return nil, fmt.Errorf("no comment found with id '%s'", commentID)
+	}
+	return comment, nil
+}
+
+// DeleteById implements domains.CommentRepository.
+func (cr *commentRepository) DeleteById(ctx context.Context, commentID string) error {
+	collection := cr.database.Collection(cr.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"comment_id": commentID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no comment found with id '%s'", commentID)
+	}
+	return nil
+}
+
+func NewCommentRepository(db mongo.Database, collection string) domain.CommentRepository {
+	return &commentRepository{
+		database:   db,
+		collection: collection,
+	}
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type customFieldRepository struct {
+	database       mongo.Database
+	collection     string
+	taskCollection string
+}
+
+// Create implements domains.CustomFieldRepository.
+func (cr *customFieldRepository) Create(ctx context.Context, field *domain.CustomField) error {
+	collection := cr.database.Collection(cr.collection)
+
+	_, err := collection.InsertOne(ctx, field)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("project '%s' already has a field '%s'", field.ProjectID, field.Key)
+	}
+	return err
+}
+
+// FetchAll implements domains.CustomFieldRepository.
+func (cr *customFieldRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.CustomField, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	filter := bson.M{}
+	if projectID != "" {
+		filter["project_id"] = projectID
+	}
+
+	fields := []*domain.CustomField{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "project_id", Value: 1}, {Key: "key", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// FetchById implements domains.CustomFieldRepository.
+func (cr *customFieldRepository) FetchById(ctx context.Context, fieldID string) (*domain.CustomField, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	var field *domain.CustomField
+	if err := collection.FindOne(ctx, bson.M{"field_id": fieldID}).Decode(&field); err != nil {
+		return nil, fmt.Errorf("no custom field found with id '%s'", fieldID)
+	}
+	return field, nil
+}
+
+// UpdateById implements domains.CustomFieldRepository. The key, type and
+// project stay as they were created.
+func (cr *customFieldRepository) UpdateById(ctx context.Context, fieldID string, field *domain.CustomField) error {
+	collection := cr.database.Collection(cr.collection)
+
+	settingStage := bson.M{"$set": bson.M{
+		"name":       field.Name,
+		"options":    field.Options,
+		"updated_at": field.UpdatedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, bson.M{"field_id": fieldID}, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no custom field found with id '%s'", fieldID)
+	}
+	return nil
+}
+
+// DeleteById implements domains.CustomFieldRepository. The values stored on
+// the project's tasks are removed in the same transaction.
+func (cr *customFieldRepository) DeleteById(ctx context.Context, fieldID string) error {
+	field, err := cr.FetchById(ctx, fieldID)
+	if err != nil {
+		return err
+	}
+
+	return withTransaction(ctx, cr.database, func(sc mongo.SessionContext) error {
+		if _, err := cr.database.Collection(cr.collection).DeleteOne(sc, bson.M{"field_id": fieldID}); err != nil {
+			return err
+		}
+		path := "custom_fields." + field.Key
+		_, err := cr.database.Collection(cr.taskCollection).UpdateMany(sc,
+			bson.M{"project_id": field.ProjectID, path: bson.M{"$exists": true}},
+			bson.M{"$unset": bson.M{path: ""}},
+		)
+		return err
+	})
+}
+
+func (cr *customFieldRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := cr.database.Collection(cr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "project_id", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating custom field index:", err)
+	}
+}
+
+func NewCustomFieldRepository(db mongo.Database, collection string, taskCollection string) domain.CustomFieldRepository {
+	repository := &customFieldRepository{
+		database:       db,
+		collection:     collection,
+		taskCollection: taskCollection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
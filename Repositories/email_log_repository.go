@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type emailLogRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// MarkSent implements domains.EmailLogRepository. The unique index makes the
+// check and the record one step, so two instances never send the same email.
+func (er *emailLogRepository) MarkSent(ctx context.Context, kind string, userID string, key string) (bool, error) {
+	collection := er.database.Collection(er.collection)
+
+	_, err := collection.InsertOne(ctx, bson.M{
+		"kind":    kind,
+		"user_id": userID,
+		"key":     key,
+		"sent_at": time.Now(),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (er *emailLogRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := er.database.Collection(er.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "user_id", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating email log index:", err)
+	}
+}
+
+func NewEmailLogRepository(db mongo.Database, collection string) domain.EmailLogRepository {
+	repository := &emailLogRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
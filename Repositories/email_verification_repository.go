@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type emailVerificationRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.EmailVerificationRepository.
+func (er *emailVerificationRepository) Create(ctx context.Context, verification *domain.EmailVerification) error {
+	collection := er.database.Collection(er.collection)
+	_, err := collection.InsertOne(ctx, verification)
+	return err
+}
+
+// Consume implements domains.EmailVerificationRepository. Only the request
+// that sets used_at gets the token back.
+func (er *emailVerificationRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.EmailVerification, error) {
+	collection := er.database.Collection(er.collection)
+
+	var verification *domain.EmailVerification
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"token_hash": tokenHash, "used_at": nil, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"used_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&verification)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return verification, err
+}
+
+// RevokeUser implements domains.EmailVerificationRepository.
+func (er *emailVerificationRepository) RevokeUser(ctx context.Context, userID string, now time.Time) error {
+	collection := er.database.Collection(er.collection)
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	return err
+}
+
+// CountSince implements domains.EmailVerificationRepository.
+func (er *emailVerificationRepository) CountSince(ctx context.Context, userID string, since time.Time) (int64, error) {
+	collection := er.database.Collection(er.collection)
+	return collection.CountDocuments(ctx, bson.M{"user_id": userID, "created_at": bson.M{"$gte": since}})
+}
+
+func (er *emailVerificationRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := er.database.Collection(er.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// expired tokens are dropped by mongo
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating email verification indexes:", err)
+	}
+}
+
+func NewEmailVerificationRepository(db mongo.Database, collection string) domain.EmailVerificationRepository {
+	repository := &emailVerificationRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type externalIdentityRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.ExternalIdentityRepository.
+func (er *externalIdentityRepository) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	collection := er.database.Collection(er.collection)
+	_, err := collection.InsertOne(ctx, identity)
+	return err
+}
+
+// FetchBySubject implements domains.ExternalIdentityRepository.
+func (er *externalIdentityRepository) FetchBySubject(ctx context.Context, issuer string, subject string) (*domain.ExternalIdentity, error) {
+	collection := er.database.Collection(er.collection)
+
+	var identity *domain.ExternalIdentity
+	err := collection.FindOne(ctx, bson.M{"issuer": issuer, "subject": subject}).Decode(&identity)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return identity, err
+}
+
+// Touch implements domains.ExternalIdentityRepository.
+func (er *externalIdentityRepository) Touch(ctx context.Context, issuer string, subject string, email string, loginAt time.Time) error {
+	collection := er.database.Collection(er.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"issuer": issuer, "subject": subject},
+		bson.M{"$set": bson.M{"email": email, "last_login_at": loginAt}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no identity found for '%s' at '%s'", subject, issuer)
+	}
+	return nil
+}
+
+func (er *externalIdentityRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := er.database.Collection(er.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "issuer", Value: 1}, {Key: "subject", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("error creating external identity indexes:", err)
+	}
+}
+
+func NewExternalIdentityRepository(db mongo.Database, collection string) domain.ExternalIdentityRepository {
+	repository := &externalIdentityRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
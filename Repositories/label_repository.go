@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type labelRepository struct {
+	database       mongo.Database
+	collection     string
+	taskCollection string
+}
+
+// Create implements domains.LabelRepository.
+func (lr *labelRepository) Create(ctx context.Context, label *domain.Label) error {
+	collection := lr.database.Collection(lr.collection)
+
+	filter := bson.M{"name": label.Name}
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("label '%s' already exists", label.Name)
+	}
+	_, err = collection.InsertOne(ctx, label)
+	return err
+}
+
+// FetchAll implements domains.LabelRepository.
+func (lr *labelRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.Label, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	filter := bson.M{}
+	if projectID != "" {
+		filter = bson.M{"$or": bson.A{
+			bson.M{"scope": domain.LabelScopeGlobal},
+			bson.M{"scope": domain.LabelScopeProject, "project_id": projectID},
+		}}
+	}
+
+	labels := []*domain.Label{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &labels); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// FetchById implements domains.LabelRepository.
+func (lr *labelRepository) FetchById(ctx context.Context, labelID string) (*domain.Label, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	var label *domain.Label
+	if err := collection.FindOne(ctx, bson.M{"label_id": labelID}).Decode(&label); err != nil {
+		return nil, fmt.Errorf("no label found with id '%s'", labelID)
+	}
+	return label, nil
+}
+
+// FetchByName implements domains.LabelRepository.
+func (lr *labelRepository) FetchByName(ctx context.Context, name string) (*domain.Label, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	var label *domain.Label
+	if err := collection.FindOne(ctx, bson.M{"name": name}).Decode(&label); err != nil {
+		return nil, fmt.Errorf("no label found with name '%s'", name)
+	}
+	return label, nil
+}
+
+// UpdateById implements domains.LabelRepository. A rename is applied to the
+// label and to every task carrying it inside one transaction.
+func (lr *labelRepository) UpdateById(ctx context.Context, labelID string, label *domain.Label) error {
+	foundLabel, err := lr.FetchById(ctx, labelID)
+	if err != nil {
+		return err
+	}
+	if label.Name != foundLabel.Name {
+		if _, err := lr.FetchByName(ctx, label.Name); err == nil {
+			return fmt.Errorf("label '%s' already exists", label.Name)
+		}
+	}
+
+	return withTransaction(ctx, lr.database, func(sc mongo.SessionContext) error {
+		settingStage := bson.M{"$set": bson.M{
+			"name":       label.Name,
+			"color":      label.Color,
+			"scope":      label.Scope,
+			"project_id": label.ProjectID,
+			"updated_at": label.UpdatedAt,
+		}}
+		result, err := lr.database.Collection(lr.collection).UpdateOne(sc, bson.M{"label_id": labelID}, settingStage)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return fmt.Errorf("no label found with id '%s'", labelID)
+		}
+		if label.Name == foundLabel.Name {
+			return nil
+		}
+
+		_, err = lr.database.Collection(lr.taskCollection).UpdateMany(sc,
+			bson.M{"labels": foundLabel.Name},
+			bson.M{"$set": bson.M{"labels.$[label]": label.Name}},
+			options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"label": foundLabel.Name}},
+			}),
+		)
+		return err
+	})
+}
+
+// DeleteById implements domains.LabelRepository.
+func (lr *labelRepository) DeleteById(ctx context.Context, labelID string) error {
+	foundLabel, err := lr.FetchById(ctx, labelID)
+	if err != nil {
+		return err
+	}
+
+	return withTransaction(ctx, lr.database, func(sc mongo.SessionContext) error {
+		if _, err := lr.database.Collection(lr.collection).DeleteOne(sc, bson.M{"label_id": labelID}); err != nil {
+			return err
+		}
+		_, err := lr.database.Collection(lr.taskCollection).UpdateMany(sc,
+			bson.M{"labels": foundLabel.Name},
+			bson.M{"$pull": bson.M{"labels": foundLabel.Name}},
+		)
+		return err
+	})
+}
+
+// UsageCounts implements domains.LabelRepository.
+func (lr *labelRepository) UsageCounts(ctx context.Context) (map[string]int64, error) {
+	collection := lr.database.Collection(lr.taskCollection)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$unwind", Value: "$labels"}},
+		{{Key: "$group", Value: bson.M{"_id": "$labels", "count": bson.M{"$sum": 1}}}},
+	}
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Name  string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(results))
+	for _, result := range results {
+		counts[result.Name] = result.Count
+	}
+	return counts, nil
+}
+
+// Merge implements domains.LabelRepository. Tasks tagged with the source label
+// are re-tagged with the target and the source label is removed.
+func (lr *labelRepository) Merge(ctx context.Context, sourceID string, targetID string) error {
+	source, err := lr.FetchById(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	target, err := lr.FetchById(ctx, targetID)
+	if err != nil {
+		return err
+	}
+
+	return withTransaction(ctx, lr.database, func(sc mongo.SessionContext) error {
+		tasks := lr.database.Collection(lr.taskCollection)
+		filter := bson.M{"labels": source.Name}
+
+		if _, err := tasks.UpdateMany(sc, filter, bson.M{"$addToSet": bson.M{"labels": target.Name}}); err != nil {
+			return err
+		}
+		if _, err := tasks.UpdateMany(sc, filter, bson.M{"$pull": bson.M{"labels": source.Name}}); err != nil {
+			return err
+		}
+		_, err := lr.database.Collection(lr.collection).DeleteOne(sc, bson.M{"label_id": sourceID})
+		return err
+	})
+}
+
+func NewLabelRepository(db mongo.Database, collection string, taskCollection string) domain.LabelRepository {
+	return &labelRepository{
+		database:       db,
+		collection:     collection,
+		taskCollection: taskCollection,
+	}
+}
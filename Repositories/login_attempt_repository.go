@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type loginAttemptRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Fetch implements domains.LoginAttemptStore.
+func (lr *loginAttemptRepository) Fetch(ctx context.Context, scope string, value string) (*domain.LoginAttempt, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	var attempt *domain.LoginAttempt
+	err := collection.FindOne(ctx, bson.M{"scope": scope, "value": value}).Decode(&attempt)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return attempt, err
+}
+
+// Fail implements domains.LoginAttemptStore. The counter is incremented in
+// place, so concurrent guesses cannot overwrite each other's failures.
+func (lr *loginAttemptRepository) Fail(ctx context.Context, scope string, value string, now time.Time, window time.Duration) (*domain.LoginAttempt, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	_, err := collection.DeleteOne(ctx, bson.M{
+		"scope":           scope,
+		"value":           value,
+		"last_failure_at": bson.M{"$lte": now.Add(-window)},
+		"retry_at":        bson.M{"$lte": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var attempt *domain.LoginAttempt
+	err = collection.FindOneAndUpdate(ctx,
+		bson.M{"scope": scope, "value": value},
+		bson.M{
+			"$inc":         bson.M{"failures": 1},
+			"$set":         bson.M{"last_failure_at": now},
+			"$max":         bson.M{"expires_at": now.Add(window)},
+			"$setOnInsert": bson.M{"first_failure_at": now, "retry_at": now, "locked_until": nil},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&attempt)
+	return attempt, err
+}
+
+// Refund implements domains.LoginAttemptStore.
+func (lr *loginAttemptRepository) Refund(ctx context.Context, scope string, value string) error {
+	collection := lr.database.Collection(lr.collection)
+
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"scope": scope, "value": value, "failures": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"failures": -1}},
+	)
+	return err
+}
+
+// Block implements domains.LoginAttemptStore. $max keeps a longer block that
+// a concurrent request already set.
+func (lr *loginAttemptRepository) Block(ctx context.Context, scope string, value string, until time.Time, locked bool, window time.Duration) error {
+	collection := lr.database.Collection(lr.collection)
+
+	update := bson.M{"retry_at": until, "expires_at": until.Add(window)}
+	if locked {
+		update["locked_until"] = until
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"scope": scope, "value": value}, bson.M{"$max": update})
+	return err
+}
+
+// Reserve implements domains.LoginAttemptStore. The filter on retry_at
+// makes the check and the move one write.
+func (lr *loginAttemptRepository) Reserve(ctx context.Context, scope string, value string, now time.Time, until time.Time, window time.Duration) (bool, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"scope": scope, "value": value, "retry_at": bson.M{"$lte": now}},
+		bson.M{"$set": bson.M{"retry_at": until}, "$max": bson.M{"expires_at": until.Add(window)}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// FetchLocked implements domains.LoginAttemptStore.
+func (lr *loginAttemptRepository) FetchLocked(ctx context.Context, now time.Time) ([]*domain.LoginAttempt, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	attempts := []*domain.LoginAttempt{}
+	opts := options.Find().SetSort(bson.D{{Key: "locked_until", Value: -1}})
+	cursor, err := collection.Find(ctx, bson.M{"locked_until": bson.M{"$gt": now}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// Reset implements domains.LoginAttemptStore.
+func (lr *loginAttemptRepository) Reset(ctx context.Context, scope string, value string) (bool, error) {
+	collection := lr.database.Collection(lr.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"scope": scope, "value": value})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (lr *loginAttemptRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := lr.database.Collection(lr.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "scope", Value: 1}, {Key: "value", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "locked_until", Value: 1}}},
+		// forgotten failures are dropped by mongo
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating login attempt indexes:", err)
+	}
+}
+
+func NewLoginAttemptRepository(db mongo.Database, collection string) domain.LoginAttemptStore {
+	repository := &loginAttemptRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notificationPreferenceRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Fetch implements domains.NotificationPreferenceRepository. Users who never
+// saved preferences get the defaults.
+func (pr *notificationPreferenceRepository) Fetch(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	collection := pr.database.Collection(pr.collection)
+
+	var preferences *domain.NotificationPreferences
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&preferences)
+	if err == mongo.ErrNoDocuments {
+		return &domain.NotificationPreferences{UserID: userID, Disabled: []string{}, EmailDisabled: []string{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// FetchMany implements domains.NotificationPreferenceRepository. Users
+// without stored preferences are missing from the result.
+func (pr *notificationPreferenceRepository) FetchMany(ctx context.Context, userIDs []string) (map[string]*domain.NotificationPreferences, error) {
+	collection := pr.database.Collection(pr.collection)
+
+	cursor, err := collection.Find(ctx, bson.M{"user_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	var found []*domain.NotificationPreferences
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[string]*domain.NotificationPreferences, len(found))
+	for _, p := range found {
+		preferences[p.UserID] = p
+	}
+	return preferences, nil
+}
+
+// Upsert implements domains.NotificationPreferenceRepository.
+func (pr *notificationPreferenceRepository) Upsert(ctx context.Context, preferences *domain.NotificationPreferences) error {
+	collection := pr.database.Collection(pr.collection)
+
+	_, err := collection.ReplaceOne(ctx, bson.M{"user_id": preferences.UserID}, preferences, options.Replace().SetUpsert(true))
+	return err
+}
+
+func NewNotificationPreferenceRepository(db mongo.Database, collection string) domain.NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{
+		database:   db,
+		collection: collection,
+	}
+}
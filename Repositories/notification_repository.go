@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notificationRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// CreateMany implements domains.NotificationRepository.
+func (nr *notificationRepository) CreateMany(ctx context.Context, notifications []*domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	collection := nr.database.Collection(nr.collection)
+
+	documents := make([]interface{}, len(notifications))
+	for i, notification := range notifications {
+		documents[i] = notification
+	}
+	_, err := collection.InsertMany(ctx, documents)
+	return err
+}
+
+// FetchPage implements domains.NotificationRepository. The newest
+// notifications come first.
+func (nr *notificationRepository) FetchPage(ctx context.Context, userID string, unreadOnly bool, page int64, limit int64) (*domain.NotificationPage, error) {
+	collection := nr.database.Collection(nr.collection)
+
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := nr.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "notification_id", Value: -1}}).
+		SetSkip((page - 1) * limit).
+		SetLimit(limit)
+	notifications := []*domain.Notification{}
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &notifications); err != nil {
+		return nil, err
+	}
+
+	return &domain.NotificationPage{
+		Notifications: notifications,
+		UnreadCount:   unread,
+		Page:          page,
+		Limit:         limit,
+		Total:         total,
+	}, nil
+}
+
+// CountUnread implements domains.NotificationRepository.
+func (nr *notificationRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	collection := nr.database.Collection(nr.collection)
+	return collection.CountDocuments(ctx, bson.M{"user_id": userID, "read": false})
+}
+
+// MarkRead implements domains.NotificationRepository.
+func (nr *notificationRepository) MarkRead(ctx context.Context, userID string, notificationID string) error {
+	collection := nr.database.Collection(nr.collection)
+
+	filter := bson.M{"notification_id": notificationID, "user_id": userID}
+	update := bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}}
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no notification found with id '%s'", notificationID)
+	}
+	return nil
+}
+
+// MarkAllRead implements domains.NotificationRepository. It returns how many
+// notifications were unread.
+func (nr *notificationRepository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	collection := nr.database.Collection(nr.collection)
+
+	filter := bson.M{"user_id": userID, "read": false}
+	update := bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}}
+	result, err := collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func (nr *notificationRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := nr.database.Collection(nr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	if err != nil {
+		log.Println("error creating notification index:", err)
+	}
+}
+
+func NewNotificationRepository(db mongo.Database, collection string) domain.NotificationRepository {
+	repository := &notificationRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type oidcStateRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.OIDCStateRepository.
+func (or *oidcStateRepository) Create(ctx context.Context, state *domain.OIDCLoginState) error {
+	collection := or.database.Collection(or.collection)
+	_, err := collection.InsertOne(ctx, state)
+	return err
+}
+
+// Consume implements domains.OIDCStateRepository. Finding and deleting in
+// one step means a state cannot be replayed, even by concurrent callbacks.
+func (or *oidcStateRepository) Consume(ctx context.Context, state string) (*domain.OIDCLoginState, error) {
+	collection := or.database.Collection(or.collection)
+
+	var found *domain.OIDCLoginState
+	err := collection.FindOneAndDelete(ctx, bson.M{"state": state}).Decode(&found)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("login state is unknown or was already used")
+	}
+	return found, err
+}
+
+func (or *oidcStateRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := or.database.Collection(or.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "state", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// abandoned logins are dropped by mongo
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating oidc state indexes:", err)
+	}
+}
+
+func NewOIDCStateRepository(db mongo.Database, collection string) domain.OIDCStateRepository {
+	repository := &oidcStateRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
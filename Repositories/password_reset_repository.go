@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type passwordResetRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.PasswordResetRepository.
+func (pr *passwordResetRepository) Create(ctx context.Context, reset *domain.PasswordReset) error {
+	collection := pr.database.Collection(pr.collection)
+	_, err := collection.InsertOne(ctx, reset)
+	return err
+}
+
+// Consume implements domains.PasswordResetRepository. Only the request that
+// sets used_at gets the token back.
+func (pr *passwordResetRepository) Consume(ctx context.Context, tokenHash string, now time.Time) (*domain.PasswordReset, error) {
+	collection := pr.database.Collection(pr.collection)
+
+	var reset *domain.PasswordReset
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"token_hash": tokenHash, "used_at": nil, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"used_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&reset)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return reset, err
+}
+
+// RevokeUser implements domains.PasswordResetRepository.
+func (pr *passwordResetRepository) RevokeUser(ctx context.Context, userID string, now time.Time) error {
+	collection := pr.database.Collection(pr.collection)
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": now}},
+	)
+	return err
+}
+
+// CountSince implements domains.PasswordResetRepository.
+func (pr *passwordResetRepository) CountSince(ctx context.Context, userID string, since time.Time) (int64, error) {
+	collection := pr.database.Collection(pr.collection)
+	return collection.CountDocuments(ctx, bson.M{"user_id": userID, "created_at": bson.M{"$gte": since}})
+}
+
+func (pr *passwordResetRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := pr.database.Collection(pr.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// expired tokens are dropped by mongo
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating password reset indexes:", err)
+	}
+}
+
+func NewPasswordResetRepository(db mongo.Database, collection string) domain.PasswordResetRepository {
+	repository := &passwordResetRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
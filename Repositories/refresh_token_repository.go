@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type refreshTokenRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.RefreshTokenRepository.
+func (rr *refreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	collection := rr.database.Collection(rr.collection)
+	_, err := collection.InsertOne(ctx, token)
+	return err
+}
+
+// FetchById implements domains.RefreshTokenRepository.
+func (rr *refreshTokenRepository) FetchById(ctx context.Context, tokenID string) (*domain.RefreshToken, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	var token *domain.RefreshToken
+	err := collection.FindOne(ctx, bson.M{"token_id": tokenID}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("no refresh token found with id '%s'", tokenID)
+	}
+	return token, err
+}
+
+// MarkUsed implements domains.RefreshTokenRepository. Only an unused token
+// matches, so of two concurrent refreshes with the same token one loses.
+func (rr *refreshTokenRepository) MarkUsed(ctx context.Context, tokenID string, usedAt time.Time) (bool, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"token_id": tokenID, "used_at": nil},
+		bson.M{"$set": bson.M{"used_at": usedAt}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// RevokeFamily implements domains.RefreshTokenRepository.
+func (rr *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	collection := rr.database.Collection(rr.collection)
+	_, err := collection.UpdateMany(ctx, bson.M{"family_id": familyID}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+// RevokeUser implements domains.RefreshTokenRepository.
+func (rr *refreshTokenRepository) RevokeUser(ctx context.Context, userID string) error {
+	collection := rr.database.Collection(rr.collection)
+	_, err := collection.UpdateMany(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (rr *refreshTokenRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := rr.database.Collection(rr.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		// expired tokens cannot be exchanged anyway, so mongo drops them
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating refresh token indexes:", err)
+	}
+}
+
+func NewRefreshTokenRepository(db mongo.Database, collection string) domain.RefreshTokenRepository {
+	repository := &refreshTokenRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ruleExecutionRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.RuleExecutionRepository.
+func (rr *ruleExecutionRepository) Create(ctx context.Context, execution *domain.RuleExecution) error {
+	collection := rr.database.Collection(rr.collection)
+	_, err := collection.InsertOne(ctx, execution)
+	return err
+}
+
+// FetchByRule implements domains.RuleExecutionRepository. The newest runs come first.
+func (rr *ruleExecutionRepository) FetchByRule(ctx context.Context, ruleID string, limit int64) ([]*domain.RuleExecution, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	executions := []*domain.RuleExecution{}
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, bson.M{"rule_id": ruleID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &executions); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+// FiredForDueDate implements domains.RuleExecutionRepository.
+func (rr *ruleExecutionRepository) FiredForDueDate(ctx context.Context, ruleID string, taskID string, dueDate time.Time) (bool, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	filter := bson.M{
+		"rule_id":  ruleID,
+		"task_id":  taskID,
+		"trigger":  domain.TaskOverdue,
+		"due_date": dueDate,
+		"dry_run":  false,
+	}
+	count, err := collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func NewRuleExecutionRepository(db mongo.Database, collection string) domain.RuleExecutionRepository {
+	return &ruleExecutionRepository{
+		database:   db,
+		collection: collection,
+	}
+}
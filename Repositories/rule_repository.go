@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ruleRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.RuleRepository.
+func (rr *ruleRepository) Create(ctx context.Context, rule *domain.Rule) error {
+	collection := rr.database.Collection(rr.collection)
+	_, err := collection.InsertOne(ctx, rule)
+	return err
+}
+
+// FetchAll implements domains.RuleRepository. Rules without a project apply
+// everywhere and are listed for every project.
+func (rr *ruleRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.Rule, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	filter := bson.M{}
+	if projectID != "" {
+		filter["project_id"] = bson.M{"$in": bson.A{projectID, ""}}
+	}
+
+	rules := []*domain.Rule{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FetchById implements domains.RuleRepository.
+func (rr *ruleRepository) FetchById(ctx context.Context, ruleID string) (*domain.Rule, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	var rule *domain.Rule
+	if err := collection.FindOne(ctx, bson.M{"rule_id": ruleID}).Decode(&rule); err != nil {
+		return nil, fmt.Errorf("no rule found with id '%s'", ruleID)
+	}
+	return rule, nil
+}
+
+// FetchEnabled implements domains.RuleRepository.
+func (rr *ruleRepository) FetchEnabled(ctx context.Context, trigger string) ([]*domain.Rule, error) {
+	collection := rr.database.Collection(rr.collection)
+
+	rules := []*domain.Rule{}
+	filter := bson.M{"trigger": trigger, "enabled": true}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpdateById implements domains.RuleRepository.
+func (rr *ruleRepository) UpdateById(ctx context.Context, ruleID string, rule *domain.Rule) error {
+	collection := rr.database.Collection(rr.collection)
+
+	settingStage := bson.M{"$set": bson.M{
+		"name":       rule.Name,
+		"project_id": rule.ProjectID,
+		"trigger":    rule.Trigger,
+		"conditions": rule.Conditions,
+		"actions":    rule.Actions,
+		"enabled":    rule.Enabled,
+		"updated_at": rule.UpdatedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, bson.M{"rule_id": ruleID}, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no rule found with id '%s'", ruleID)
+	}
+	return nil
+}
+
+// DeleteById implements domains.RuleRepository.
+func (rr *ruleRepository) DeleteById(ctx context.Context, ruleID string) error {
+	collection := rr.database.Collection(rr.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"rule_id": ruleID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no rule found with id '%s'", ruleID)
+	}
+	return nil
+}
+
+func NewRuleRepository(db mongo.Database, collection string) domain.RuleRepository {
+	return &ruleRepository{
+		database:   db,
+		collection: collection,
+	}
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type savedViewRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.SavedViewRepository.
+func (sr *savedViewRepository) Create(ctx context.Context, view *domain.SavedView) error {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{"owner_id": view.OwnerID, "name": view.Name}
+	count, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("view '%s' already exists", view.Name)
+	}
+	_, err = collection.InsertOne(ctx, view)
+	return err
+}
+
+// FetchVisible implements domains.SavedViewRepository.
+func (sr *savedViewRepository) FetchVisible(ctx context.Context, userID string, projectID string) ([]*domain.SavedView, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	shared := bson.M{"visibility": domain.SavedViewProject}
+	if projectID != "" {
+		shared["project_id"] = projectID
+	}
+	filter := bson.M{"$or": bson.A{bson.M{"owner_id": userID}, shared}}
+
+	views := []*domain.SavedView{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
+
+// FetchById implements domains.SavedViewRepository.
+func (sr *savedViewRepository) FetchById(ctx context.Context, viewID string) (*domain.SavedView, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{"view_id": viewID}
+	var view *domain.SavedView
+	if err := collection.FindOne(ctx, filter).Decode(&view); err != nil {
+		return nil, fmt.Errorf("no view found with id '%s'", viewID)
+	}
+	return view, nil
+}
+
+// UpdateById implements domains.SavedViewRepository.
+func (sr *savedViewRepository) UpdateById(ctx context.Context, viewID string, userID string, view *domain.SavedView) error {
+	collection := sr.database.Collection(sr.collection)
+
+	filterStage := bson.M{"view_id": viewID}
+	var foundView domain.SavedView
+	if err := collection.FindOne(ctx, filterStage).Decode(&foundView); err != nil {
+		return fmt.Errorf("no view found with id '%s'", viewID)
+	}
+	if userID != foundView.OwnerID {
+		return fmt.Errorf("unauthorized to update view")
+	}
+
+	settingStage := bson.M{"$set": bson.M{
+		"name":       view.Name,
+		"project_id": view.ProjectID,
+		"visibility": view.Visibility,
+		"filter":     view.Filter,
+		"sort":       view.Sort,
+		"columns":    view.Columns,
+		"updated_at": view.UpdatedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, filterStage, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no view found with id '%s'", viewID)
+	}
+	return nil
+}
+
+// DeleteById implements domains.SavedViewRepository.
+func (sr *savedViewRepository) DeleteById(ctx context.Context, viewID string, userID string) error {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{"view_id": viewID}
+	var foundView domain.SavedView
+	if err := collection.FindOne(ctx, filter).Decode(&foundView); err != nil {
+		return fmt.Errorf("no view found with id '%s'", viewID)
+	}
+	if userID != foundView.OwnerID {
+		return fmt.Errorf("unauthorized to delete view")
+	}
+
+	_, err := collection.DeleteOne(ctx, filter)
+	return err
+}
+
+func NewSavedViewRepository(db mongo.Database, collection string) domain.SavedViewRepository {
+	return &savedViewRepository{
+		database:   db,
+		collection: collection,
+	}
+}
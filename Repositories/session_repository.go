@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sessionRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.SessionRepository.
+func (sr *sessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.InsertOne(ctx, session)
+	return err
+}
+
+// FetchById implements domains.SessionRepository.
+func (sr *sessionRepository) FetchById(ctx context.Context, sessionID string) (*domain.Session, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	var session *domain.Session
+	err := collection.FindOne(ctx, bson.M{"session_id": sessionID}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("no session found with id '%s'", sessionID)
+	}
+	return session, err
+}
+
+// FetchActive implements domains.SessionRepository. The most recently used
+// session comes first.
+func (sr *sessionRepository) FetchActive(ctx context.Context, userID string) ([]*domain.Session, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{"user_id": userID, "revoked_at": nil, "expires_at": bson.M{"$gt": time.Now()}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	sessions := []*domain.Session{}
+	if err = cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Touch implements domains.SessionRepository.
+func (sr *sessionRepository) Touch(ctx context.Context, sessionID string, ip string, seenAt time.Time, expiresAt time.Time) error {
+	collection := sr.database.Collection(sr.collection)
+
+	set := bson.M{"ip": ip, "last_seen_at": seenAt}
+	if !expiresAt.IsZero() {
+		set["expires_at"] = expiresAt
+	}
+	_, err := collection.UpdateOne(ctx, bson.M{"session_id": sessionID}, bson.M{"$set": set})
+	return err
+}
+
+// Revoke implements domains.SessionRepository. Users can only end their own
+// sessions.
+func (sr *sessionRepository) Revoke(ctx context.Context, userID string, sessionID string) error {
+	collection := sr.database.Collection(sr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"session_id": sessionID, "user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no active session found with id '%s'", sessionID)
+	}
+	return nil
+}
+
+// RevokeUser implements domains.SessionRepository.
+func (sr *sessionRepository) RevokeUser(ctx context.Context, userID string) error {
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "revoked_at": nil},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	return err
+}
+
+func (sr *sessionRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "session_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "last_seen_at", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating session indexes:", err)
+	}
+}
+
+func NewSessionRepository(db mongo.Database, collection string) domain.SessionRepository {
+	repository := &sessionRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
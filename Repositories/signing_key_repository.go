@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type signingKeyRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.SigningKeyRepository.
+func (sr *signingKeyRepository) Create(ctx context.Context, key *domain.SigningKey) error {
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.InsertOne(ctx, key)
+	return err
+}
+
+// FetchValid implements domains.SigningKeyRepository.
+func (sr *signingKeyRepository) FetchValid(ctx context.Context) ([]*domain.SigningKey, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	cursor, err := collection.Find(ctx,
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	keys := []*domain.SigningKey{}
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (sr *signingKeyRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating signing key indexes:", err)
+	}
+}
+
+func NewSigningKeyRepository(db mongo.Database, collection string) domain.SigningKeyRepository {
+	repository := &signingKeyRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sprintRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.SprintRepository.
+func (sr *sprintRepository) Create(ctx context.Context, sprint *domain.Sprint) error {
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.InsertOne(ctx, sprint)
+	return err
+}
+
+// FetchAll implements domains.SprintRepository.
+func (sr *sprintRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.Sprint, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{}
+	if projectID != "" {
+		filter["project_id"] = projectID
+	}
+
+	sprints := []*domain.Sprint{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "start_date", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &sprints); err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+// FetchById implements domains.SprintRepository.
+func (sr *sprintRepository) FetchById(ctx context.Context, sprintID string) (*domain.Sprint, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	var sprint *domain.Sprint
+	if err := collection.FindOne(ctx, bson.M{"sprint_id": sprintID}).Decode(&sprint); err != nil {
+		return nil, fmt.Errorf("no sprint found with id '%s'", sprintID)
+	}
+	return sprint, nil
+}
+
+// FetchActive implements domains.SprintRepository.
+func (sr *sprintRepository) FetchActive(ctx context.Context, projectID string) (*domain.Sprint, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	var sprint *domain.Sprint
+	filter := bson.M{"project_id": projectID, "status": domain.SprintActive}
+	if err := collection.FindOne(ctx, filter).Decode(&sprint); err != nil {
+		return nil, fmt.Errorf("project '%s' has no active sprint", projectID)
+	}
+	return sprint, nil
+}
+
+// UpdateById implements domains.SprintRepository.
+func (sr *sprintRepository) UpdateById(ctx context.Context, sprintID string, sprint *domain.Sprint) error {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{"sprint_id": sprintID}
+	settingStage := bson.M{"$set": bson.M{
+		"name":         sprint.Name,
+		"goal":         sprint.Goal,
+		"start_date":   sprint.StartDate,
+		"end_date":     sprint.EndDate,
+		"status":       sprint.Status,
+		"task_ids":     sprint.TaskIDs,
+		"carried_over": sprint.CarriedOver,
+		"started_at":   sprint.StartedAt,
+		"closed_at":    sprint.ClosedAt,
+		"updated_at":   sprint.UpdatedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, filter, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no sprint found with id '%s'", sprintID)
+	}
+	return nil
+}
+
+// Start implements domains.SprintRepository. The update only matches a
+// planned sprint, and the unique partial index on active sprints rejects a
+// second one in the project even when two starts race.
+func (sr *sprintRepository) Start(ctx context.Context, sprintID string, startedAt time.Time) error {
+	collection := sr.database.Collection(sr.collection)
+
+	filter := bson.M{"sprint_id": sprintID, "status": domain.SprintPlanned}
+	settingStage := bson.M{"$set": bson.M{
+		"status":     domain.SprintActive,
+		"started_at": startedAt,
+		"updated_at": startedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, filter, settingStage)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("another sprint is already active in this project")
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("only planned sprints can be started")
+	}
+	return nil
+}
+
+// DeleteById implements domains.SprintRepository.
+func (sr *sprintRepository) DeleteById(ctx context.Context, sprintID string) error {
+	collection := sr.database.Collection(sr.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"sprint_id": sprintID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no sprint found with id '%s'", sprintID)
+	}
+	return nil
+}
+
+func (sr *sprintRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := sr.database.Collection(sr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "project_id", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"status": domain.SprintActive}),
+	})
+	if err != nil {
+		log.Println("error creating sprint index:", err)
+	}
+}
+
+func NewSprintRepository(db mongo.Database, collection string) domain.SprintRepository {
+	repository := &sprintRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
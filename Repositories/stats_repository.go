@@ -0,0 +1,247 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// statsRepository aggregates over the task collection. Cycle times read the
+// status history to find when work on a task started.
+type statsRepository struct {
+	database          mongo.Database
+	collection        string
+	historyCollection string
+}
+
+// CountBy implements domains.StatsRepository.
+func (sr *statsRepository) CountBy(ctx context.Context, filter *domain.StatsFilter, field string) ([]*domain.CountBucket, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: statsFilterToBSON(filter, "created_at")}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$" + field, ""}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	return sr.countBuckets(ctx, pipeline)
+}
+
+// Overdue implements domains.StatsRepository. It counts the open tasks past
+// their due date per assignee; the time range is not applied.
+func (sr *statsRepository) Overdue(ctx context.Context, filter *domain.StatsFilter, now time.Time) ([]*domain.CountBucket, error) {
+	match := statsFilterToBSON(&domain.StatsFilter{ProjectID: filter.ProjectID}, "")
+	// tasks without a due date hold the zero time, which the lower bound skips
+	match["due_date"] = bson.M{"$gte": time.Unix(0, 0), "$lt": now}
+	match["status"] = bson.M{"$ne": domain.TaskStatusDone}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$assignee_id", ""}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	return sr.countBuckets(ctx, pipeline)
+}
+
+// Throughput implements domains.StatsRepository. Weeks without tasks are
+// left out.
+func (sr *statsRepository) Throughput(ctx context.Context, filter *domain.StatsFilter) ([]*domain.ThroughputWeek, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	weekOf := func(field string) bson.M {
+		return bson.M{"$dateTrunc": bson.M{"date": "$" + field, "unit": "week", "startOfWeek": "monday"}}
+	}
+	completed := statsFilterToBSON(filter, "completed_at")
+	if _, ok := completed["completed_at"]; !ok {
+		completed["completed_at"] = bson.M{"$type": "date"}
+	}
+	delete(completed, "project_id")
+	created := statsFilterToBSON(filter, "created_at")
+	delete(created, "project_id")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: statsFilterToBSON(&domain.StatsFilter{ProjectID: filter.ProjectID}, "")}},
+		{{Key: "$facet", Value: bson.M{
+			"created": bson.A{
+				bson.M{"$match": created},
+				bson.M{"$group": bson.M{"_id": weekOf("created_at"), "count": bson.M{"$sum": 1}}},
+			},
+			"completed": bson.A{
+				bson.M{"$match": completed},
+				bson.M{"$group": bson.M{"_id": weekOf("completed_at"), "count": bson.M{"$sum": 1}}},
+			},
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		Created []struct {
+			Week  time.Time `bson:"_id"`
+			Count int64     `bson:"count"`
+		} `bson:"created"`
+		Completed []struct {
+			Week  time.Time `bson:"_id"`
+			Count int64     `bson:"count"`
+		} `bson:"completed"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	weeks := map[time.Time]*domain.ThroughputWeek{}
+	week := func(start time.Time) *domain.ThroughputWeek {
+		start = start.UTC()
+		if weeks[start] == nil {
+			weeks[start] = &domain.ThroughputWeek{WeekStart: start}
+		}
+		return weeks[start]
+	}
+	for _, result := range results {
+		for _, bucket := range result.Created {
+			week(bucket.Week).Created = bucket.Count
+		}
+		for _, bucket := range result.Completed {
+			week(bucket.Week).Completed = bucket.Count
+		}
+	}
+
+	throughput := make([]*domain.ThroughputWeek, 0, len(weeks))
+	for _, w := range weeks {
+		throughput = append(throughput, w)
+	}
+	return throughput, nil
+}
+
+// Flow implements domains.StatsRepository. Cycle time starts at the first
+// move to IN_PROGRESS, so tasks that never went through it only count
+// towards the lead time.
+func (sr *statsRepository) Flow(ctx context.Context, filter *domain.StatsFilter) (*domain.FlowStats, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	match := statsFilterToBSON(filter, "completed_at")
+	match["status"] = domain.TaskStatusDone
+	if _, ok := match["completed_at"]; !ok {
+		match["completed_at"] = bson.M{"$type": "date"}
+	}
+
+	hours := func(from interface{}) bson.M {
+		return bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{"$completed_at", from}}, float64(time.Hour / time.Millisecond)}}
+	}
+	// $avg, $min and $max skip the nulls left for tasks without a cycle time
+	accumulate := func(group bson.M, name string) {
+		field := "$" + name + "_hours"
+		group[name+"_tasks"] = bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{field, nil}}, 0, 1}}}
+		group[name+"_avg"] = bson.M{"$avg": field}
+		group[name+"_min"] = bson.M{"$min": field}
+		group[name+"_max"] = bson.M{"$max": field}
+	}
+	summary := func(name string) bson.M {
+		rounded := func(accumulator string) bson.M {
+			return bson.M{"$round": bson.A{bson.M{"$ifNull": bson.A{"$" + name + "_" + accumulator, 0}}, 2}}
+		}
+		return bson.M{
+			"tasks":         "$" + name + "_tasks",
+			"average_hours": rounded("avg"),
+			"min_hours":     rounded("min"),
+			"max_hours":     rounded("max"),
+		}
+	}
+	group := bson.M{"_id": nil}
+	accumulate(group, "lead")
+	accumulate(group, "cycle")
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": sr.historyCollection,
+			"let":  bson.M{"task_id": "$task_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$task_id", "$$task_id"}},
+					bson.M{"$eq": bson.A{"$to", domain.TaskStatusInProgress}},
+				}}}},
+				bson.M{"$sort": bson.M{"changed_at": 1}},
+				bson.M{"$limit": 1},
+			},
+			"as": "started",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"lead_hours": hours("$created_at"),
+			"cycle_hours": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": "$started"}, 0}},
+				hours(bson.M{"$first": "$started.changed_at"}),
+				nil,
+			}},
+		}}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$project", Value: bson.M{
+			"_id":        0,
+			"lead_time":  summary("lead"),
+			"cycle_time": summary("cycle"),
+		}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	results := []*domain.FlowStats{}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return &domain.FlowStats{}, nil
+	}
+	return results[0], nil
+}
+
+func (sr *statsRepository) countBuckets(ctx context.Context, pipeline mongo.Pipeline) ([]*domain.CountBucket, error) {
+	collection := sr.database.Collection(sr.collection)
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	buckets := []*domain.CountBucket{}
+	if err = cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
+
+// statsFilterToBSON matches the filter's project and applies its time range
+// to dateField.
+func statsFilterToBSON(filter *domain.StatsFilter, dateField string) bson.M {
+	query := bson.M{}
+	if filter.ProjectID != "" {
+		query["project_id"] = filter.ProjectID
+	}
+	if dateField != "" && (filter.From != nil || filter.To != nil) {
+		bounds := bson.M{}
+		if filter.From != nil {
+			bounds["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			bounds["$lt"] = *filter.To
+		}
+		query[dateField] = bounds
+	}
+	return query
+}
+
+func NewStatsRepository(db mongo.Database, collection string, historyCollection string) domain.StatsRepository {
+	return &statsRepository{
+		database:          db,
+		collection:        collection,
+		historyCollection: historyCollection,
+	}
+}
@@ -4,10 +4,16 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	domain "github.com/segnig/task-manager/Domains"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type taskRepository struct {
@@ -71,6 +77,151 @@ func (tr *taskRepository) FetchAll(ctx context.Context) ([]*domain.Task, error)
 
 }
 
+// FetchPage implements domains.TaskRepository.
+func (tr *taskRepository) FetchPage(ctx context.Context, query *domain.TaskQuery) (*domain.TaskPage, error) {
+	collection := tr.database.Collection(tr.collection)
+	filter := taskFilterToBSON(&query.Filter)
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetSort(taskSortToBSON(&query.Sort)).
+		SetSkip((query.Page - 1) * query.Limit).
+		SetLimit(query.Limit)
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	tasks := []*domain.Task{}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return &domain.TaskPage{
+		Tasks: tasks,
+		Page:  query.Page,
+		Limit: query.Limit,
+		Total: total,
+	}, nil
+}
+
+func taskFilterToBSON(filter *domain.TaskFilter) bson.M {
+	query := bson.M{}
+	if filter.ProjectID != "" {
+		query["project_id"] = filter.ProjectID
+	}
+	if len(filter.Statuses) > 0 {
+		query["status"] = bson.M{"$in": filter.Statuses}
+	}
+	if filter.CreatedBy != "" {
+		query["created_by"] = filter.CreatedBy
+	}
+	if filter.AssigneeID != "" {
+		query["assignee_id"] = filter.AssigneeID
+	}
+	if len(filter.Priorities) > 0 {
+		query["priority"] = bson.M{"$in": filter.Priorities}
+	}
+	if filter.Search != "" {
+		query["title"] = primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+	}
+	if filter.DueFrom != nil || filter.DueTo != nil {
+		dueDate := bson.M{}
+		if filter.DueFrom != nil {
+			dueDate["$gte"] = *filter.DueFrom
+		}
+		if filter.DueTo != nil {
+			dueDate["$lte"] = *filter.DueTo
+		}
+		query["due_date"] = dueDate
+	}
+	if filter.SprintID != "" {
+		query["sprint_id"] = filter.SprintID
+	}
+	if filter.ParentID != "" {
+		query["parent_id"] = filter.ParentID
+	}
+	if len(filter.TaskIDs) > 0 {
+		query["task_id"] = bson.M{"$in": filter.TaskIDs}
+	}
+	if len(filter.Labels) > 0 {
+		if filter.LabelMatch == domain.LabelMatchAll {
+			query["labels"] = bson.M{"$all": filter.Labels}
+		} else {
+			query["labels"] = bson.M{"$in": filter.Labels}
+		}
+	}
+	for _, condition := range filter.CustomFields {
+		path := "custom_fields." + condition.Key
+		field, ok := query[path].(bson.M)
+		if !ok {
+			field = bson.M{}
+			query[path] = field
+		}
+		switch condition.Op {
+		case domain.CustomFieldGte:
+			field["$gte"] = customFieldBound(condition.Value)
+		case domain.CustomFieldLte:
+			field["$lte"] = customFieldBound(condition.Value)
+		default:
+			field["$in"] = customFieldCandidates(condition.Value)
+		}
+	}
+	return query
+}
+
+// customFieldCandidates returns every stored form a filter value may take,
+// since conditions are written without knowing the field type.
+func customFieldCandidates(value string) bson.A {
+	candidates := bson.A{value}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, number)
+	}
+	if date, ok := parseCustomFieldDate(value); ok {
+		candidates = append(candidates, date)
+	}
+	return candidates
+}
+
+// customFieldBound picks the type a range bound compares as; BSON comparisons
+// only match values of the same type.
+func customFieldBound(value string) interface{} {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return number
+	}
+	if date, ok := parseCustomFieldDate(value); ok {
+		return date
+	}
+	return value
+}
+
+func parseCustomFieldDate(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func taskSortToBSON(sort *domain.TaskSort) bson.D {
+	field, ok := domain.TaskSortFields[sort.Field]
+	if key, custom := strings.CutPrefix(sort.Field, domain.CustomFieldSortPrefix); custom {
+		field = "custom_fields." + key
+	} else if !ok {
+		field = "created_at"
+	}
+	order := -1
+	if sort.Order == "asc" {
+		order = 1
+	}
+	// task_id breaks ties so pages stay stable between requests.
+	return bson.D{{Key: field, Value: order}, {Key: "task_id", Value: 1}}
+}
+
 // FetchById implements domains.TaskRepository.
 func (tr *taskRepository) FetchById(ctx context.Context, taskId string) (*domain.Task, error) {
 	collection := tr.database.Collection(tr.collection)
@@ -107,7 +258,25 @@ func (tr *taskRepository) UpdateById(ctx context.Context, taskId string, userID
 		return fmt.Errorf("unauthorized to update task")
 	}
 
-	settingStage := bson.M{"$set": &task}
+	// only the fields a task update may change; the project and the fields
+	// with their own endpoints (labels, rank, sprint, parent, checklist,
+	// custom fields, watchers) are left alone
+	settingStage := bson.M{"$set": bson.M{
+		"title":            task.Title,
+		"description":      task.Description,
+		"status":           task.Status,
+		"start_date":       task.StartDate,
+		"due_date":         task.DueDate,
+		"due_day":          task.DueDay,
+		"assignee_id":      task.AssigneeID,
+		"priority":         task.Priority,
+		"priority_rank":    task.PriorityRank,
+		"story_points":     task.StoryPoints,
+		"estimate_minutes": task.EstimateMinutes,
+		"completed_at":     task.CompletedAt,
+		"updated_at":       task.UpdatedAt,
+		"updated_by":       task.UpdatedBy,
+	}}
 
 	result, err := collection.UpdateOne(ctx, filterStage, settingStage)
 
@@ -121,6 +290,302 @@ func (tr *taskRepository) UpdateById(ctx context.Context, taskId string, userID
 
 }
 
+// AddLabel implements domains.TaskRepository.
+func (tr *taskRepository) AddLabel(ctx context.Context, taskId string, label string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{"task_id": taskId}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$addToSet": bson.M{"labels": label}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// RemoveLabel implements domains.TaskRepository.
+func (tr *taskRepository) RemoveLabel(ctx context.Context, taskId string, label string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{"task_id": taskId}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"labels": label}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// FetchOpenAssigned implements domains.TaskRepository.
+func (tr *taskRepository) FetchOpenAssigned(ctx context.Context, userID string) ([]*domain.Task, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{"assignee_id": userID, "status": bson.M{"$ne": domain.TaskStatusDone}}
+	tasks := []*domain.Task{}
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// FetchMatching implements domains.TaskRepository.
+func (tr *taskRepository) FetchMatching(ctx context.Context, filter *domain.TaskFilter) ([]*domain.Task, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	tasks := []*domain.Task{}
+	cursor, err := collection.Find(ctx, taskFilterToBSON(filter))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// CountMatching implements domains.TaskRepository.
+func (tr *taskRepository) CountMatching(ctx context.Context, filter *domain.TaskFilter) (int64, error) {
+	collection := tr.database.Collection(tr.collection)
+	return collection.CountDocuments(ctx, taskFilterToBSON(filter))
+}
+
+// Move implements domains.TaskRepository. Only status and rank are written so
+// a move never overwrites concurrent edits to the rest of the task.
+func (tr *taskRepository) Move(ctx context.Context, taskId string, userID string, status string, rank string, completedAt *time.Time) error {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{"task_id": taskId}
+	settingStage := bson.M{"$set": bson.M{
+		"status":       status,
+		"rank":         rank,
+		"completed_at": completedAt,
+		"updated_by":   userID,
+		"updated_at":   time.Now(),
+	}}
+	result, err := collection.UpdateOne(ctx, filter, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// SetRank implements domains.TaskRepository.
+func (tr *taskRepository) SetRank(ctx context.Context, taskId string, rank string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"task_id": taskId}, bson.M{"$set": bson.M{"rank": rank}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// SetSprint implements domains.TaskRepository. An empty sprintID moves the
+// tasks back to the backlog.
+func (tr *taskRepository) SetSprint(ctx context.Context, taskIds []string, sprintID string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	update := bson.M{"$set": bson.M{"sprint_id": sprintID}}
+	if sprintID == "" {
+		update = bson.M{"$unset": bson.M{"sprint_id": ""}}
+	}
+	_, err := collection.UpdateMany(ctx, bson.M{"task_id": bson.M{"$in": taskIds}}, update)
+	return err
+}
+
+// AddChecklistItem implements domains.TaskRepository. The push keeps the
+// checklist sorted by rank.
+func (tr *taskRepository) AddChecklistItem(ctx context.Context, taskId string, item *domain.ChecklistItem) error {
+	collection := tr.database.Collection(tr.collection)
+
+	update := bson.M{"$push": bson.M{"checklist": bson.M{
+		"$each": []*domain.ChecklistItem{item},
+		"$sort": bson.M{"rank": 1},
+	}}}
+	result, err := collection.UpdateOne(ctx, bson.M{"task_id": taskId}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// UpdateChecklistItem implements domains.TaskRepository. Only the fields of
+// the one item are set, so concurrent edits of other items are kept.
+func (tr *taskRepository) UpdateChecklistItem(ctx context.Context, taskId string, itemId string, userID string, patch *domain.ChecklistItemPatch) error {
+	collection := tr.database.Collection(tr.collection)
+
+	fields := bson.M{
+		"updated_by": userID,
+		"updated_at": time.Now(),
+	}
+	if patch.Text != nil {
+		fields["checklist.$[item].text"] = *patch.Text
+	}
+	if patch.Done != nil {
+		fields["checklist.$[item].done"] = *patch.Done
+		if *patch.Done {
+			fields["checklist.$[item].done_by"] = userID
+			fields["checklist.$[item].done_at"] = time.Now()
+		}
+	}
+	update := bson.M{"$set": fields}
+	if patch.Done != nil && !*patch.Done {
+		update["$unset"] = bson.M{"checklist.$[item].done_by": "", "checklist.$[item].done_at": ""}
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.item_id": itemId}},
+	})
+	filter := bson.M{"task_id": taskId, "checklist.item_id": itemId}
+	result, err := collection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no checklist item found with id '%s'", itemId)
+	}
+	return nil
+}
+
+// SetChecklistItemRank implements domains.TaskRepository. The item is
+// re-ranked first and the checklist re-sorted by an empty push afterwards.
+func (tr *taskRepository) SetChecklistItemRank(ctx context.Context, taskId string, itemId string, rank string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.item_id": itemId}},
+	})
+	filter := bson.M{"task_id": taskId, "checklist.item_id": itemId}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"checklist.$[item].rank": rank}}, opts)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no checklist item found with id '%s'", itemId)
+	}
+
+	sorting := bson.M{"$push": bson.M{"checklist": bson.M{
+		"$each": []interface{}{},
+		"$sort": bson.M{"rank": 1},
+	}}}
+	_, err = collection.UpdateOne(ctx, bson.M{"task_id": taskId}, sorting)
+	return err
+}
+
+// RemoveChecklistItem implements domains.TaskRepository.
+func (tr *taskRepository) RemoveChecklistItem(ctx context.Context, taskId string, itemId string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{"task_id": taskId, "checklist.item_id": itemId}
+	result, err := collection.UpdateOne(ctx, filter, bson.M{"$pull": bson.M{"checklist": bson.M{"item_id": itemId}}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no checklist item found with id '%s'", itemId)
+	}
+	return nil
+}
+
+// SetCustomFields implements domains.TaskRepository. Only the given keys are
+// touched, so values set by others in the meantime are kept.
+func (tr *taskRepository) SetCustomFields(ctx context.Context, taskId string, userID string, set map[string]interface{}, unset []string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	fields := bson.M{
+		"updated_by": userID,
+		"updated_at": time.Now(),
+	}
+	for key, value := range set {
+		fields["custom_fields."+key] = value
+	}
+	update := bson.M{"$set": fields}
+	if len(unset) > 0 {
+		removed := bson.M{}
+		for _, key := range unset {
+			removed["custom_fields."+key] = ""
+		}
+		update["$unset"] = removed
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"task_id": taskId}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// SetFields implements domains.TaskRepository. It sets the given top level
+// fields only, leaving the rest of the task as stored.
+func (tr *taskRepository) SetFields(ctx context.Context, taskId string, userID string, fields map[string]interface{}) error {
+	collection := tr.database.Collection(tr.collection)
+
+	update := bson.M{
+		"updated_by": userID,
+		"updated_at": time.Now(),
+	}
+	for field, value := range fields {
+		update[field] = value
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"task_id": taskId}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// AddWatchers implements domains.TaskRepository.
+func (tr *taskRepository) AddWatchers(ctx context.Context, taskId string, userIDs []string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	update := bson.M{"$addToSet": bson.M{"watchers": bson.M{"$each": userIDs}}}
+	result, err := collection.UpdateOne(ctx, bson.M{"task_id": taskId}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
+// RemoveWatcher implements domains.TaskRepository.
+func (tr *taskRepository) RemoveWatcher(ctx context.Context, taskId string, userID string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"task_id": taskId}, bson.M{"$pull": bson.M{"watchers": userID}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	return nil
+}
+
 func NewTaskRepository(db mongo.Database, collection string) domain.TaskRepository {
 	return &taskRepository{
 		database:   db,
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type taskStatusHistoryRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.TaskStatusHistoryRepository.
+func (hr *taskStatusHistoryRepository) Create(ctx context.Context, change *domain.TaskStatusChange) error {
+	collection := hr.database.Collection(hr.collection)
+	_, err := collection.InsertOne(ctx, change)
+	return err
+}
+
+// FetchByTasks implements domains.TaskStatusHistoryRepository.
+func (hr *taskStatusHistoryRepository) FetchByTasks(ctx context.Context, taskIDs []string) ([]*domain.TaskStatusChange, error) {
+	collection := hr.database.Collection(hr.collection)
+
+	changes := []*domain.TaskStatusChange{}
+	filter := bson.M{"task_id": bson.M{"$in": taskIDs}}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "changed_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func NewTaskStatusHistoryRepository(db mongo.Database, collection string) domain.TaskStatusHistoryRepository {
+	return &taskStatusHistoryRepository{
+		database:   db,
+		collection: collection,
+	}
+}
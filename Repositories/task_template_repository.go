@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type taskTemplateRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.TaskTemplateRepository.
+func (tr *taskTemplateRepository) Create(ctx context.Context, template *domain.TaskTemplate) error {
+	collection := tr.database.Collection(tr.collection)
+	_, err := collection.InsertOne(ctx, template)
+	return err
+}
+
+// FetchAll implements domains.TaskTemplateRepository.
+func (tr *taskTemplateRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.TaskTemplate, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{}
+	if projectID != "" {
+		filter["project_id"] = projectID
+	}
+
+	templates := []*domain.TaskTemplate{}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// FetchById implements domains.TaskTemplateRepository.
+func (tr *taskTemplateRepository) FetchById(ctx context.Context, templateID string) (*domain.TaskTemplate, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	var template *domain.TaskTemplate
+	if err := collection.FindOne(ctx, bson.M{"template_id": templateID}).Decode(&template); err != nil {
+		return nil, fmt.Errorf("no template found with id '%s'", templateID)
+	}
+	return template, nil
+}
+
+// UpdateById implements domains.TaskTemplateRepository.
+func (tr *taskTemplateRepository) UpdateById(ctx context.Context, templateID string, template *domain.TaskTemplate) error {
+	collection := tr.database.Collection(tr.collection)
+
+	settingStage := bson.M{"$set": bson.M{
+		"name":        template.Name,
+		"description": template.Description,
+		"project_id":  template.ProjectID,
+		"tasks":       template.Tasks,
+		"variables":   template.Variables,
+		"updated_at":  template.UpdatedAt,
+	}}
+	result, err := collection.UpdateOne(ctx, bson.M{"template_id": templateID}, settingStage)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no template found with id '%s'", templateID)
+	}
+	return nil
+}
+
+// DeleteById implements domains.TaskTemplateRepository.
+func (tr *taskTemplateRepository) DeleteById(ctx context.Context, templateID string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"template_id": templateID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("no template found with id '%s'", templateID)
+	}
+	return nil
+}
+
+func NewTaskTemplateRepository(db mongo.Database, collection string) domain.TaskTemplateRepository {
+	return &taskTemplateRepository{
+		database:   db,
+		collection: collection,
+	}
+}
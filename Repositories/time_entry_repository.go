@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type timeEntryRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.TimeEntryRepository.
+func (tr *timeEntryRepository) Create(ctx context.Context, entry *domain.TimeEntry) error {
+	collection := tr.database.Collection(tr.collection)
+	_, err := collection.InsertOne(ctx, entry)
+	return err
+}
+
+// StartTimer implements domains.TimeEntryRepository. The unique partial index
+// on running entries rejects a second timer even when two starts race.
+func (tr *timeEntryRepository) StartTimer(ctx context.Context, entry *domain.TimeEntry) error {
+	collection := tr.database.Collection(tr.collection)
+
+	if _, err := tr.FetchRunning(ctx, entry.UserID); err == nil {
+		return fmt.Errorf("a timer is already running")
+	}
+	_, err := collection.InsertOne(ctx, entry)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("a timer is already running")
+	}
+	return err
+}
+
+// StopTimer implements domains.TimeEntryRepository.
+func (tr *timeEntryRepository) StopTimer(ctx context.Context, userID string, endedAt time.Time) (*domain.TimeEntry, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	entry, err := tr.FetchRunning(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	entry.EndedAt = &endedAt
+	entry.DurationSeconds = int64(endedAt.Sub(entry.StartedAt).Seconds())
+	entry.Running = false
+
+	filter := bson.M{"entry_id": entry.EntryID, "running": true}
+	settingStage := bson.M{"$set": bson.M{
+		"ended_at":         entry.EndedAt,
+		"duration_seconds": entry.DurationSeconds,
+		"running":          false,
+	}}
+	result, err := collection.UpdateOne(ctx, filter, settingStage)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("no running timer found")
+	}
+	return entry, nil
+}
+
+// FetchRunning implements domains.TimeEntryRepository.
+func (tr *timeEntryRepository) FetchRunning(ctx context.Context, userID string) (*domain.TimeEntry, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	var entry *domain.TimeEntry
+	if err := collection.FindOne(ctx, bson.M{"user_id": userID, "running": true}).Decode(&entry); err != nil {
+		return nil, fmt.Errorf("no running timer found")
+	}
+	return entry, nil
+}
+
+// Fetch implements domains.TimeEntryRepository.
+func (tr *timeEntryRepository) Fetch(ctx context.Context, filter *domain.TimeEntryFilter) ([]*domain.TimeEntry, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	entries := []*domain.TimeEntry{}
+	cursor, err := collection.Find(ctx, timeEntryFilterToBSON(filter), options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// DeleteById implements domains.TimeEntryRepository.
+func (tr *timeEntryRepository) DeleteById(ctx context.Context, entryID string, userID string) error {
+	collection := tr.database.Collection(tr.collection)
+
+	filter := bson.M{"entry_id": entryID}
+	var foundEntry domain.TimeEntry
+	if err := collection.FindOne(ctx, filter).Decode(&foundEntry); err != nil {
+		return fmt.Errorf("no time entry found with id '%s'", entryID)
+	}
+	if userID != foundEntry.UserID {
+		return fmt.Errorf("unauthorized to delete time entry")
+	}
+
+	_, err := collection.DeleteOne(ctx, filter)
+	return err
+}
+
+// Timesheet implements domains.TimeEntryRepository. Entries are attributed to
+// the UTC day they started on.
+func (tr *timeEntryRepository) Timesheet(ctx context.Context, filter *domain.TimeEntryFilter) ([]*domain.TimesheetRow, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	match := timeEntryFilterToBSON(filter)
+	match["running"] = false
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"user_id": "$user_id",
+				"task_id": "$task_id",
+				"day":     bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$started_at"}},
+			},
+			"seconds": bson.M{"$sum": "$duration_seconds"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":     0,
+			"user_id": "$_id.user_id",
+			"task_id": "$_id.task_id",
+			"day":     "$_id.day",
+			"seconds": 1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "day", Value: 1}, {Key: "user_id", Value: 1}, {Key: "task_id", Value: 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	rows := []*domain.TimesheetRow{}
+	if err = cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func timeEntryFilterToBSON(filter *domain.TimeEntryFilter) bson.M {
+	query := bson.M{}
+	if filter.UserID != "" {
+		query["user_id"] = filter.UserID
+	}
+	if filter.TaskID != "" {
+		query["task_id"] = filter.TaskID
+	}
+	if filter.From != nil || filter.To != nil {
+		startedAt := bson.M{}
+		if filter.From != nil {
+			startedAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			startedAt["$lt"] = *filter.To
+		}
+		query["started_at"] = startedAt
+	}
+	return query
+}
+
+func (tr *timeEntryRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := tr.database.Collection(tr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"running": true}),
+	})
+	if err != nil {
+		log.Println("error creating time entry index:", err)
+	}
+}
+
+func NewTimeEntryRepository(db mongo.Database, collection string) domain.TimeEntryRepository {
+	repository := &timeEntryRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type tokenRevocationRepository struct {
+	database             mongo.Database
+	revokedCollection    string
+	generationCollection string
+}
+
+// RevokeToken implements domains.TokenRevocationStore. Revoking a token twice
+// is not an error.
+func (tr *tokenRevocationRepository) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	collection := tr.database.Collection(tr.revokedCollection)
+	_, err := collection.UpdateOne(ctx,
+		bson.M{"token_id": tokenID},
+		bson.M{"$set": bson.M{"token_id": tokenID, "expires_at": expiresAt}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// IsTokenRevoked implements domains.TokenRevocationStore.
+func (tr *tokenRevocationRepository) IsTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	collection := tr.database.Collection(tr.revokedCollection)
+	// the TTL monitor only runs once a minute, so expiry is checked here too
+	count, err := collection.CountDocuments(ctx, bson.M{"token_id": tokenID, "expires_at": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Generation implements domains.TokenRevocationStore. Users who never logged
+// out everywhere are at generation 0.
+func (tr *tokenRevocationRepository) Generation(ctx context.Context, userID string) (int, error) {
+	collection := tr.database.Collection(tr.generationCollection)
+
+	var record struct {
+		Generation int `bson:"generation"`
+	}
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&record)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return record.Generation, nil
+}
+
+// BumpGeneration implements domains.TokenRevocationStore.
+func (tr *tokenRevocationRepository) BumpGeneration(ctx context.Context, userID string) (int, error) {
+	collection := tr.database.Collection(tr.generationCollection)
+
+	var record struct {
+		Generation int `bson:"generation"`
+	}
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"user_id": userID},
+		bson.M{"$inc": bson.M{"generation": 1}, "$set": bson.M{"updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&record)
+	if err != nil {
+		return 0, err
+	}
+	return record.Generation, nil
+}
+
+func (tr *tokenRevocationRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := tr.database.Collection(tr.revokedCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating revoked token indexes:", err)
+	}
+	_, err = tr.database.Collection(tr.generationCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating token generation index:", err)
+	}
+}
+
+func NewTokenRevocationRepository(db mongo.Database, revokedCollection string, generationCollection string) domain.TokenRevocationStore {
+	repository := &tokenRevocationRepository{
+		database:             db,
+		revokedCollection:    revokedCollection,
+		generationCollection: generationCollection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type transactionManager struct {
+	database mongo.Database
+}
+
+// WithTransaction implements domains.TransactionManager. Transactions need
+// MongoDB to run as a replica set.
+func (tm *transactionManager) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTransaction(ctx, tm.database, func(sc mongo.SessionContext) error {
+		return fn(sc)
+	})
+}
+
+func withTransaction(ctx context.Context, database mongo.Database, fn func(sc mongo.SessionContext) error) error {
+	session, err := database.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+func NewTransactionManager(db mongo.Database) domain.TransactionManager {
+	return &transactionManager{
+		database: db,
+	}
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type twoFactorChallengeRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Create implements domains.TwoFactorChallengeRepository.
+func (cr *twoFactorChallengeRepository) Create(ctx context.Context, challenge *domain.TwoFactorChallenge) error {
+	collection := cr.database.Collection(cr.collection)
+	_, err := collection.InsertOne(ctx, challenge)
+	return err
+}
+
+// FetchById implements domains.TwoFactorChallengeRepository.
+func (cr *twoFactorChallengeRepository) FetchById(ctx context.Context, challengeID string) (*domain.TwoFactorChallenge, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	var challenge *domain.TwoFactorChallenge
+	err := collection.FindOne(ctx, bson.M{"challenge_id": challengeID}).Decode(&challenge)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("login challenge is unknown or has expired")
+	}
+	return challenge, err
+}
+
+// Fail implements domains.TwoFactorChallengeRepository.
+func (cr *twoFactorChallengeRepository) Fail(ctx context.Context, challengeID string) (int, error) {
+	collection := cr.database.Collection(cr.collection)
+
+	var challenge *domain.TwoFactorChallenge
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"challenge_id": challengeID},
+		bson.M{"$inc": bson.M{"attempts": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&challenge)
+	if err == mongo.ErrNoDocuments {
+		return domain.MaxTwoFactorAttempts, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return challenge.Attempts, nil
+}
+
+// Delete implements domains.TwoFactorChallengeRepository. It reports an
+// error when the challenge is gone, so that of two concurrent logins with
+// one challenge only the first gets tokens.
+func (cr *twoFactorChallengeRepository) Delete(ctx context.Context, challengeID string) error {
+	collection := cr.database.Collection(cr.collection)
+
+	result, err := collection.DeleteOne(ctx, bson.M{"challenge_id": challengeID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("login challenge is unknown or has expired")
+	}
+	return nil
+}
+
+func (cr *twoFactorChallengeRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := cr.database.Collection(cr.collection)
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "challenge_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		log.Println("error creating two-factor challenge indexes:", err)
+	}
+}
+
+func NewTwoFactorChallengeRepository(db mongo.Database, collection string) domain.TwoFactorChallengeRepository {
+	repository := &twoFactorChallengeRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
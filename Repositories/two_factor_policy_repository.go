@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type twoFactorPolicyRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// FetchAll implements domains.TwoFactorPolicyRepository.
+func (pr *twoFactorPolicyRepository) FetchAll(ctx context.Context) ([]*domain.TwoFactorPolicy, error) {
+	collection := pr.database.Collection(pr.collection)
+
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "user_type", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	policies := []*domain.TwoFactorPolicy{}
+	if err = cursor.All(ctx, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Required implements domains.TwoFactorPolicyRepository. A user type
+// without a policy does not require 2FA.
+func (pr *twoFactorPolicyRepository) Required(ctx context.Context, userType string) (bool, error) {
+	collection := pr.database.Collection(pr.collection)
+
+	var policy *domain.TwoFactorPolicy
+	err := collection.FindOne(ctx, bson.M{"user_type": userType}).Decode(&policy)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return policy.Required, nil
+}
+
+// Upsert implements domains.TwoFactorPolicyRepository.
+func (pr *twoFactorPolicyRepository) Upsert(ctx context.Context, policy *domain.TwoFactorPolicy) error {
+	collection := pr.database.Collection(pr.collection)
+	_, err := collection.ReplaceOne(ctx, bson.M{"user_type": policy.UserType}, policy, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (pr *twoFactorPolicyRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := pr.database.Collection(pr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_type", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating two-factor policy indexes:", err)
+	}
+}
+
+func NewTwoFactorPolicyRepository(db mongo.Database, collection string) domain.TwoFactorPolicyRepository {
+	repository := &twoFactorPolicyRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
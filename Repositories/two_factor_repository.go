@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type twoFactorRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+// Fetch implements domains.TwoFactorRepository.
+func (tr *twoFactorRepository) Fetch(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	var twoFactor *domain.TwoFactor
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&twoFactor)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return twoFactor, err
+}
+
+// Save implements domains.TwoFactorRepository. It replaces a pending
+// enrollment but never an enabled one.
+func (tr *twoFactorRepository) Save(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	collection := tr.database.Collection(tr.collection)
+
+	_, err := collection.ReplaceOne(ctx,
+		bson.M{"user_id": twoFactor.UserID, "enabled": bson.M{"$ne": true}},
+		twoFactor,
+		options.Replace().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("two-factor authentication is already enabled")
+	}
+	return err
+}
+
+// Enable implements domains.TwoFactorRepository.
+func (tr *twoFactorRepository) Enable(ctx context.Context, userID string, recoveryCodes []string, step int64, enabledAt time.Time) error {
+	collection := tr.database.Collection(tr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "enabled": false},
+		bson.M{"$set": bson.M{"enabled": true, "recovery_codes": recoveryCodes, "last_step": step, "enabled_at": enabledAt}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no pending two-factor enrollment")
+	}
+	return nil
+}
+
+// UseStep implements domains.TwoFactorRepository. Matching on an older step
+// makes checking and recording one step, so a code cannot be replayed.
+func (tr *twoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "last_step": bson.M{"$lt": step}},
+		bson.M{"$set": bson.M{"last_step": step}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// UseRecoveryCode implements domains.TwoFactorRepository.
+func (tr *twoFactorRepository) UseRecoveryCode(ctx context.Context, userID string, hashedCode string) (bool, error) {
+	collection := tr.database.Collection(tr.collection)
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "recovery_codes": hashedCode},
+		bson.M{"$pull": bson.M{"recovery_codes": hashedCode}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+// ReplaceRecoveryCodes implements domains.TwoFactorRepository.
+func (tr *twoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, recoveryCodes []string) error {
+	collection := tr.database.Collection(tr.collection)
+	_, err := collection.UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{"recovery_codes": recoveryCodes}})
+	return err
+}
+
+// Delete implements domains.TwoFactorRepository.
+func (tr *twoFactorRepository) Delete(ctx context.Context, userID string) error {
+	collection := tr.database.Collection(tr.collection)
+	_, err := collection.DeleteOne(ctx, bson.M{"user_id": userID})
+	return err
+}
+
+func (tr *twoFactorRepository) ensureIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection := tr.database.Collection(tr.collection)
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("error creating two-factor indexes:", err)
+	}
+}
+
+func NewTwoFactorRepository(db mongo.Database, collection string) domain.TwoFactorRepository {
+	repository := &twoFactorRepository{
+		database:   db,
+		collection: collection,
+	}
+	repository.ensureIndexes()
+	return repository
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	domain "github.com/segnig/task-manager/Domains"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -66,28 +66,54 @@ func (ur *userRepository) FetchById(ctx context.Context, userId string) (*domain
 	return user, err
 }
 
-// UpdateById implements domains.userRepository.
+// UpdateById implements domains.userRepository. Only the names are
+// updated, and only the ones that were sent; the email, password, username
+// and user type have their own flows.
 func (ur *userRepository) UpdateById(ctx context.Context, userId string, user *domain.User) error {
 	collection := ur.database.Collection(ur.collection)
-	objId, err := primitive.ObjectIDFromHex(userId)
 
+	update := bson.M{"updatedat": time.Now()}
+	if user.FirstName != "" {
+		update["firstname"] = user.FirstName
+	}
+	if user.LastName != "" {
+		update["lastname"] = user.LastName
+	}
+	result, err := collection.UpdateOne(ctx, bson.M{"userid": userId}, bson.M{"$set": update})
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no user found with id '%s'", userId)
+	}
+	return nil
+}
 
-	filterStage := bson.M{"userid": objId}
-	settingStage := bson.M{"$set": &user}
-
-	result, err := collection.UpdateOne(ctx, filterStage, settingStage)
+// UpdateEmail implements domains.userRepository. An empty email removes the
+// address.
+func (ur *userRepository) UpdateEmail(ctx context.Context, userID string, email string) error {
+	collection := ur.database.Collection(ur.collection)
 
+	result, err := collection.UpdateOne(ctx, bson.M{"userid": userID}, bson.M{"$set": bson.M{"email": email, "emailverified": false, "updatedat": time.Now()}})
 	if err != nil {
 		return err
 	}
 	if result.MatchedCount == 0 {
-		return fmt.Errorf("no task found with id '%s'", userId)
+		return fmt.Errorf("no user found with id '%s'", userID)
 	}
 	return nil
+}
 
+// VerifyEmail implements domains.userRepository. A token for an address the
+// user has changed since does not verify the new one.
+func (ur *userRepository) VerifyEmail(ctx context.Context, userID string, email string) (bool, error) {
+	collection := ur.database.Collection(ur.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"userid": userID, "email": email}, bson.M{"$set": bson.M{"emailverified": true, "updatedat": time.Now()}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
 }
 
 func (ur *userRepository) Create(ctx context.Context, user *domain.User) error {
@@ -133,16 +159,58 @@ func (ur *userRepository) GetUserByUsername(ctx context.Context, username string
 	return user, err
 }
 
+// UpdateAllToken implements domains.UserRepository. User has no bson tags,
+// so the stored field names are the lowercased Go names.
 func (ur *userRepository) UpdateAllToken(ctx context.Context, signedToken, signedRefreshToken, UserID string) error {
-	user, err := ur.FetchById(ctx, UserID)
+	collection := ur.database.Collection(ur.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"userid": UserID}, bson.M{"$set": bson.M{
+		"token":        signedToken,
+		"refreshtoken": signedRefreshToken,
+		"updatedat":    time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no user found with id '%s'", UserID)
+	}
+	return nil
+}
+
+// UpdateUserType implements domains.UserRepository.
+func (ur *userRepository) UpdateUserType(ctx context.Context, userID string, userType string) error {
+	collection := ur.database.Collection(ur.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"userid": userID}, bson.M{"$set": bson.M{
+		"usertype":  userType,
+		"updatedat": time.Now(),
+	}})
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no user found with id '%s'", userID)
+	}
+	return nil
+}
 
-	user.Token = signedToken
-	user.RefreshToken = signedRefreshToken
-	err = ur.UpdateById(ctx, UserID, user)
-	return err
+// UpdatePassword implements domains.UserRepository. The password must
+// already be hashed.
+func (ur *userRepository) UpdatePassword(ctx context.Context, userID string, hashedPassword string) error {
+	collection := ur.database.Collection(ur.collection)
+
+	result, err := collection.UpdateOne(ctx, bson.M{"userid": userID}, bson.M{"$set": bson.M{
+		"password":  hashedPassword,
+		"updatedat": time.Now(),
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("no user found with id '%s'", userID)
+	}
+	return nil
 }
 
 func NewUserRepository(db mongo.Database, collection string) *userRepository {
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// apiKeyDisplayLength is how much of a key is kept to recognise it in lists.
+const apiKeyDisplayLength = 12
+
+type apiKeyUsecase struct {
+	apiKeyRepository domain.ApiKeyRepository
+	revocations      domain.TokenRevocationStore
+	contextTimeout   time.Duration
+}
+
+// CreatePersonal implements domains.ApiKeyUsecase. The key acts as the user
+// with the user's current role; a change of role bumps the user's generation
+// and so ends the key.
+func (au *apiKeyUsecase) CreatePersonal(ctx context.Context, userID string, username string, userType string, request *domain.ApiKeyRequest) (*domain.NewApiKey, error) {
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+
+	generation, err := au.revocations.Generation(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	return au.create(c, &domain.ApiKey{
+		Kind:       domain.ApiKeyPersonal,
+		UserID:     userID,
+		Username:   username,
+		UserType:   userType,
+		Generation: generation,
+		CreatedBy:  userID,
+	}, request)
+}
+
+// CreateService implements domains.ApiKeyUsecase. A service key is its own
+// user: its requests carry the key ID as user_id and the key name as
+// username.
+func (au *apiKeyUsecase) CreateService(ctx context.Context, createdBy string, request *domain.ApiKeyRequest) (*domain.NewApiKey, error) {
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+
+	return au.create(c, &domain.ApiKey{
+		Kind:      domain.ApiKeyService,
+		UserType:  domain.ServiceUserType,
+		CreatedBy: createdBy,
+	}, request)
+}
+
+// FetchPersonal implements domains.ApiKeyUsecase.
+func (au *apiKeyUsecase) FetchPersonal(ctx context.Context, userID string) ([]*domain.ApiKey, error) {
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+	return au.apiKeyRepository.FetchActive(c, domain.ApiKeyPersonal, userID)
+}
+
+// FetchService implements domains.ApiKeyUsecase.
+func (au *apiKeyUsecase) FetchService(ctx context.Context) ([]*domain.ApiKey, error) {
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+	return au.apiKeyRepository.FetchActive(c, domain.ApiKeyService, "")
+}
+
+// RevokePersonal implements domains.ApiKeyUsecase.
+func (au *apiKeyUsecase) RevokePersonal(ctx context.Context, userID string, keyID string) error {
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+	return au.apiKeyRepository.Revoke(c, domain.ApiKeyPersonal, userID, keyID)
+}
+
+// RevokeService implements domains.ApiKeyUsecase.
+func (au *apiKeyUsecase) RevokeService(ctx context.Context, keyID string) error {
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+	return au.apiKeyRepository.Revoke(c, domain.ApiKeyService, "", keyID)
+}
+
+func (au *apiKeyUsecase) create(ctx context.Context, key *domain.ApiKey, request *domain.ApiKeyRequest) (*domain.NewApiKey, error) {
+	if err := validateApiKeyRequest(request); err != nil {
+		return nil, err
+	}
+	secret, err := newApiKeySecret(key.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	key.KeyID = primitive.NewObjectID().Hex()
+	key.Name = request.Name
+	key.Prefix = secret[:apiKeyDisplayLength]
+	key.Hash = domain.HashApiKey(secret)
+	key.Scopes = request.Scopes
+	key.CreatedAt = now
+	key.ExpiresAt = now.AddDate(0, 0, request.ExpiresInDays)
+	if key.Kind == domain.ApiKeyService {
+		key.UserID = key.KeyID
+		key.Username = key.Name
+	}
+	if err := au.apiKeyRepository.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return &domain.NewApiKey{ApiKey: key, Key: secret}, nil
+}
+
+// newApiKeySecret returns e.g. "tm_pat_" followed by 32 random bytes; the
+// kind is part of the key so that leaked keys are easy to recognise.
+func newApiKeySecret(kind string) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	tag := "pat_"
+	if kind == domain.ApiKeyService {
+		tag = "svc_"
+	}
+	return domain.ApiKeyPrefix + tag + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+func validateApiKeyRequest(request *domain.ApiKeyRequest) error {
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" || len(request.Name) > 50 {
+		return fmt.Errorf("key name must be between 1 and 50 characters")
+	}
+	if len(request.Scopes) == 0 {
+		return fmt.Errorf("a key needs at least one scope")
+	}
+	for _, scope := range request.Scopes {
+		if scope != domain.ScopeRead && scope != domain.ScopeWrite {
+			return fmt.Errorf("scope must be read or write, not '%s'", scope)
+		}
+	}
+	slices.Sort(request.Scopes)
+	request.Scopes = slices.Compact(request.Scopes)
+	if request.ExpiresInDays == 0 {
+		request.ExpiresInDays = domain.DefaultApiKeyLifetimeDays
+	}
+	if request.ExpiresInDays < 0 || request.ExpiresInDays > domain.MaxApiKeyLifetimeDays {
+		return fmt.Errorf("expires_in_days must be between 1 and %d", domain.MaxApiKeyLifetimeDays)
+	}
+	return nil
+}
+
+func NewApiKeyUsecase(apiKeyRepository domain.ApiKeyRepository, revocations domain.TokenRevocationStore, contextTimeout time.Duration) domain.ApiKeyUsecase {
+	return &apiKeyUsecase{
+		apiKeyRepository: apiKeyRepository,
+		revocations:      revocations,
+		contextTimeout:   contextTimeout,
+	}
+}
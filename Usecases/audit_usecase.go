@@ -0,0 +1,36 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type auditUsecase struct {
+	auditRepository domain.AuditRepository
+	contextTimeout  time.Duration
+}
+
+// FetchPage implements domains.AuditUsecase.
+func (au *auditUsecase) FetchPage(ctx context.Context, eventType string, page int64, limit int64) (*domain.AuditPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultTaskPageLimit
+	}
+	if limit > maxTaskPageLimit {
+		limit = maxTaskPageLimit
+	}
+	c, cancel := context.WithTimeout(context.Background(), au.contextTimeout)
+	defer cancel()
+	return au.auditRepository.FetchPage(c, eventType, page, limit)
+}
+
+func NewAuditUsecase(auditRepository domain.AuditRepository, contextTimeout time.Duration) domain.AuditUsecase {
+	return &auditUsecase{
+		auditRepository: auditRepository,
+		contextTimeout:  contextTimeout,
+	}
+}
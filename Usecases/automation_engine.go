@@ -0,0 +1,422 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// automationEngine runs the enabled rules for every task event it receives.
+// Its own changes go straight to the repositories and are only passed on to
+// the follow-up handlers, so a rule never triggers another rule.
+type automationEngine struct {
+	ruleRepository      domain.RuleRepository
+	executionRepository domain.RuleExecutionRepository
+	taskRepository      domain.TaskRepository
+	labelRepository     domain.LabelRepository
+	commentRepository   domain.CommentRepository
+	webhooks            domain.WebhookSender
+	followUp            []domain.TaskEventHandler
+	contextTimeout      time.Duration
+}
+
+// HandleTaskEvent implements domains.TaskEventHandler. The caller's context
+// is kept so rule changes join a surrounding transaction.
+func (e *automationEngine) HandleTaskEvent(ctx context.Context, event *domain.TaskEvent) {
+	if event.Type == domain.TaskDeleted {
+		return
+	}
+	c, cancel := context.WithTimeout(ctx, e.contextTimeout)
+	defer cancel()
+
+	rules, err := e.ruleRepository.FetchEnabled(c, event.Type)
+	if err != nil {
+		log.Println("error loading automation rules:", err)
+		return
+	}
+	for _, rule := range rules {
+		e.run(c, rule, event.Type, event.Task, event.Previous, nil)
+	}
+}
+
+// SweepOverdue implements domains.AutomationEngine. Each overdue rule fires
+// once per task and due date.
+func (e *automationEngine) SweepOverdue(ctx context.Context) {
+	c, cancel := context.WithTimeout(ctx, e.contextTimeout)
+	defer cancel()
+
+	rules, err := e.ruleRepository.FetchEnabled(c, domain.TaskOverdue)
+	if err != nil {
+		log.Println("error loading overdue rules:", err)
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	// tasks without a due date hold the zero time, which the lower bound skips
+	noDueDate := time.Unix(0, 0)
+	now := time.Now()
+	tasks, err := e.taskRepository.FetchMatching(c, &domain.TaskFilter{DueFrom: &noDueDate, DueTo: &now})
+	if err != nil {
+		log.Println("error loading overdue tasks:", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if task.Status == domain.TaskStatusDone {
+			continue
+		}
+		for _, rule := range rules {
+			if !ruleApplies(rule, task) {
+				continue
+			}
+			taskCtx, cancel := context.WithTimeout(ctx, e.contextTimeout)
+			fired, err := e.executionRepository.FiredForDueDate(taskCtx, rule.RuleID, task.TaskID, task.DueDate)
+			if err != nil {
+				log.Println("error checking overdue rule executions:", err)
+			} else if !fired {
+				dueDate := task.DueDate
+				e.run(taskCtx, rule, domain.TaskOverdue, task, nil, &dueDate)
+			}
+			cancel()
+		}
+	}
+}
+
+func (e *automationEngine) run(ctx context.Context, rule *domain.Rule, trigger string, task *domain.Task, previous *domain.Task, dueDate *time.Time) {
+	if !ruleApplies(rule, task) || !matchesConditions(rule.Conditions, task, previous) {
+		return
+	}
+
+	// actions see the changes made by the actions before them
+	current := *task
+	execution := newRuleExecution(rule, current.TaskID, trigger, false)
+	execution.Matched = true
+	execution.DueDate = dueDate
+	for _, action := range rule.Actions {
+		result := domain.ActionResult{Type: action.Type, Status: domain.ActionSucceeded}
+		if err := e.perform(ctx, rule, action, trigger, &current, previous); err != nil {
+			result.Status = domain.ActionFailed
+			result.Message = err.Error()
+		} else if action.Type == domain.ActionWebhook {
+			result.Status = domain.ActionQueued
+		}
+		execution.Results = append(execution.Results, result)
+	}
+
+	if err := e.executionRepository.Create(ctx, execution); err != nil {
+		log.Println("error recording rule execution:", err)
+	}
+}
+
+func (e *automationEngine) perform(ctx context.Context, rule *domain.Rule, action domain.RuleAction, trigger string, task *domain.Task, previous *domain.Task) error {
+	value := fillPlaceholders(action.Value, task)
+	switch action.Type {
+	case domain.ActionSetField:
+		return e.setField(ctx, rule, action.Field, value, task)
+	case domain.ActionAssign:
+		return e.setField(ctx, rule, "assignee_id", value, task)
+	case domain.ActionAddLabel:
+		label, err := e.labelRepository.FetchByName(ctx, value)
+		if err != nil {
+			return err
+		}
+		if label.Scope == domain.LabelScopeProject && label.ProjectID != task.ProjectID {
+			return fmt.Errorf("label '%s' belongs to another project", label.Name)
+		}
+		if err := e.taskRepository.AddLabel(ctx, task.TaskID, label.Name); err != nil {
+			return err
+		}
+		task.Labels = append(append([]string{}, task.Labels...), label.Name)
+		return nil
+	case domain.ActionComment:
+		id := primitive.NewObjectID()
+		return e.commentRepository.Create(ctx, &domain.Comment{
+			ID:        id,
+			CommentID: id.Hex(),
+			TaskID:    task.TaskID,
+			AuthorID:  rule.CreatedBy,
+			Body:      value,
+			RuleID:    rule.RuleID,
+			CreatedAt: time.Now(),
+		})
+	case domain.ActionWebhook:
+		// the task is copied, later actions change it while the webhook waits
+		snapshot := *task
+		e.sendWebhook(action.URL, map[string]interface{}{
+			"rule_id":  rule.RuleID,
+			"rule":     rule.Name,
+			"trigger":  trigger,
+			"task":     &snapshot,
+			"previous": previous,
+		})
+		return nil
+	default:
+		return fmt.Errorf("unknown action '%s'", action.Type)
+	}
+}
+
+// sendWebhook posts in the background so a slow endpoint does not hold up
+// the request or the transaction the event came from.
+func (e *automationEngine) sendWebhook(url string, payload interface{}) {
+	go func() {
+		c, cancel := context.WithTimeout(context.Background(), e.contextTimeout)
+		defer cancel()
+		if err := e.webhooks.Send(c, url, payload); err != nil {
+			log.Println("error sending webhook:", err)
+		}
+	}()
+}
+
+func (e *automationEngine) setField(ctx context.Context, rule *domain.Rule, field string, value string, task *domain.Task) error {
+	switch field {
+	case "status":
+		value = strings.ToUpper(value)
+		if value == "" || value == task.Status {
+			return nil
+		}
+		before := *task
+		now := time.Now()
+		completedAt := completionTime(&before, value, now)
+		if err := e.taskRepository.Move(ctx, task.TaskID, rule.CreatedBy, value, task.Rank, completedAt); err != nil {
+			return err
+		}
+		task.Status = value
+		task.CompletedAt = completedAt
+		changed := *task
+		publishTaskEvent(ctx, e.followUp, &domain.TaskEvent{
+			Type:       domain.TaskStatusChanged,
+			Task:       &changed,
+			Previous:   &before,
+			ActorID:    rule.CreatedBy,
+			OccurredAt: now,
+		})
+		return nil
+	case "priority":
+		value = strings.ToUpper(value)
+		rank, ok := domain.PriorityRanks[value]
+		if !ok {
+			return fmt.Errorf("'%s' is not a priority", value)
+		}
+		if err := e.taskRepository.SetFields(ctx, task.TaskID, rule.CreatedBy, map[string]interface{}{"priority": value, "priority_rank": rank}); err != nil {
+			return err
+		}
+		task.Priority = value
+		task.PriorityRank = rank
+		return nil
+	case "assignee_id":
+		if err := e.taskRepository.SetFields(ctx, task.TaskID, rule.CreatedBy, map[string]interface{}{"assignee_id": value}); err != nil {
+			return err
+		}
+		task.AssigneeID = value
+		return nil
+	default:
+		return fmt.Errorf("field '%s' cannot be set by a rule", field)
+	}
+}
+
+func newRuleExecution(rule *domain.Rule, taskID string, trigger string, dryRun bool) *domain.RuleExecution {
+	id := primitive.NewObjectID()
+	return &domain.RuleExecution{
+		ID:          id,
+		ExecutionID: id.Hex(),
+		RuleID:      rule.RuleID,
+		TaskID:      taskID,
+		Trigger:     trigger,
+		DryRun:      dryRun,
+		Results:     []domain.ActionResult{},
+		OccurredAt:  time.Now(),
+	}
+}
+
+// ruleApplies reports whether the task is in the rule's project.
+func ruleApplies(rule *domain.Rule, task *domain.Task) bool {
+	return rule.ProjectID == "" || rule.ProjectID == task.ProjectID
+}
+
+func matchesConditions(conditions []domain.RuleCondition, task *domain.Task, previous *domain.Task) bool {
+	for _, condition := range conditions {
+		subject, field := task, condition.Field
+		if name, ok := strings.CutPrefix(field, "previous."); ok {
+			subject, field = previous, name
+		}
+		if !matchesCondition(condition, taskFieldValues(subject, field)) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesCondition(condition domain.RuleCondition, values []string) bool {
+	anyValue := func(match func(value string) bool) bool {
+		for _, value := range values {
+			if match(value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch condition.Op {
+	case domain.ConditionEq:
+		return anyValue(func(value string) bool { return value == condition.Value })
+	case domain.ConditionNeq:
+		return !anyValue(func(value string) bool { return value == condition.Value })
+	case domain.ConditionIn:
+		return anyValue(func(value string) bool {
+			for _, candidate := range condition.Values {
+				if value == candidate {
+					return true
+				}
+			}
+			return false
+		})
+	case domain.ConditionContains:
+		return anyValue(func(value string) bool {
+			return strings.Contains(strings.ToLower(value), strings.ToLower(condition.Value))
+		})
+	case domain.ConditionGt:
+		return anyValue(func(value string) bool {
+			result, ok := compareValues(value, condition.Value)
+			return ok && result > 0
+		})
+	case domain.ConditionLt:
+		return anyValue(func(value string) bool {
+			result, ok := compareValues(value, condition.Value)
+			return ok && result < 0
+		})
+	case domain.ConditionSet:
+		return anyValue(func(value string) bool { return value != "" })
+	case domain.ConditionUnset:
+		return !anyValue(func(value string) bool { return value != "" })
+	default:
+		return false
+	}
+}
+
+// compareValues compares two values as numbers or, failing that, as RFC3339
+// times. It reports false when the values are of neither kind.
+func compareValues(a string, b string) (int, bool) {
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+	s, errS := time.Parse(time.RFC3339, a)
+	t, errT := time.Parse(time.RFC3339, b)
+	if errS == nil && errT == nil {
+		return s.Compare(t), true
+	}
+	return 0, false
+}
+
+// ruleFields lists the task fields rules can look at, besides custom_fields.<key>.
+var ruleFields = map[string]bool{
+	"title": true, "description": true, "status": true, "priority": true,
+	"project_id": true, "assignee_id": true, "created_by": true, "updated_by": true,
+	"sprint_id": true, "parent_id": true, "labels": true, "story_points": true,
+	"estimate_minutes": true, "start_date": true, "due_date": true, "completed_at": true,
+}
+
+func taskFieldValues(task *domain.Task, field string) []string {
+	if task == nil {
+		return nil
+	}
+	if key, ok := strings.CutPrefix(field, "custom_fields."); ok {
+		value, ok := task.CustomFields[key]
+		if !ok {
+			return nil
+		}
+		return []string{formatRuleValue(value)}
+	}
+
+	switch field {
+	case "title":
+		return []string{task.Title}
+	case "description":
+		return []string{task.Description}
+	case "status":
+		return []string{task.Status}
+	case "priority":
+		return []string{task.Priority}
+	case "project_id":
+		return []string{task.ProjectID}
+	case "assignee_id":
+		return []string{task.AssigneeID}
+	case "created_by":
+		return []string{task.CreatedBy}
+	case "updated_by":
+		return []string{task.UpdatedBy}
+	case "sprint_id":
+		return []string{task.SprintID}
+	case "parent_id":
+		return []string{task.ParentID}
+	case "labels":
+		return task.Labels
+	case "story_points":
+		return []string{strconv.FormatFloat(task.StoryPoints, 'f', -1, 64)}
+	case "estimate_minutes":
+		return []string{strconv.Itoa(task.EstimateMinutes)}
+	case "start_date":
+		return []string{formatRuleValue(task.StartDate)}
+	case "due_date":
+		return []string{formatRuleValue(task.DueDate)}
+	case "completed_at":
+		if task.CompletedAt == nil {
+			return nil
+		}
+		return []string{formatRuleValue(*task.CompletedAt)}
+	default:
+		return nil
+	}
+}
+
+func formatRuleValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case primitive.DateTime:
+		return v.Time().UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fillPlaceholders replaces {{field}} with the task's value for that field.
+func fillPlaceholders(text string, task *domain.Task) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+		return strings.Join(taskFieldValues(task, name), ", ")
+	})
+}
+
+func NewAutomationEngine(ruleRepository domain.RuleRepository, executionRepository domain.RuleExecutionRepository, taskRepository domain.TaskRepository, labelRepository domain.LabelRepository, commentRepository domain.CommentRepository, webhooks domain.WebhookSender, contextTimeout time.Duration, followUp ...domain.TaskEventHandler) domain.AutomationEngine {
+	return &automationEngine{
+		ruleRepository:      ruleRepository,
+		executionRepository: executionRepository,
+		taskRepository:      taskRepository,
+		labelRepository:     labelRepository,
+		commentRepository:   commentRepository,
+		webhooks:            webhooks,
+		followUp:            followUp,
+		contextTimeout:      contextTimeout,
+	}
+}
@@ -0,0 +1,373 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type boardUsecase struct {
+	boardRepository domain.BoardRepository
+	taskRepository  domain.TaskRepository
+	transactions    domain.TransactionManager
+	contextTimeout  time.Duration
+	eventHandlers   []domain.TaskEventHandler
+}
+
+// Create implements domains.BoardUsecase.
+func (b *boardUsecase) Create(ctx context.Context, board *domain.Board) error {
+	if err := validateBoard(board); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+	return b.boardRepository.Create(c, board)
+}
+
+// FetchAll implements domains.BoardUsecase.
+func (b *boardUsecase) FetchAll(ctx context.Context, projectID string) ([]*domain.Board, error) {
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+	return b.boardRepository.FetchAll(c, projectID)
+}
+
+// FetchById implements domains.BoardUsecase.
+func (b *boardUsecase) FetchById(ctx context.Context, boardID string) (*domain.Board, error) {
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+	return b.boardRepository.FetchById(c, boardID)
+}
+
+// UpdateById implements domains.BoardUsecase.
+func (b *boardUsecase) UpdateById(ctx context.Context, boardID string, board *domain.Board) error {
+	if err := validateBoard(board); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+	return b.boardRepository.UpdateById(c, boardID, board)
+}
+
+// DeleteById implements domains.BoardUsecase.
+func (b *boardUsecase) DeleteById(ctx context.Context, boardID string) error {
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+	return b.boardRepository.DeleteById(c, boardID)
+}
+
+// View implements domains.BoardUsecase.
+func (b *boardUsecase) View(ctx context.Context, boardID string) (*domain.BoardView, error) {
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+
+	board, err := b.boardRepository.FetchById(c, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &domain.BoardView{Board: board, Columns: make([]*domain.BoardColumnView, 0, len(board.Columns))}
+	for _, column := range board.Columns {
+		tasks, err := b.columnTasks(c, board, column)
+		if err != nil {
+			return nil, err
+		}
+		view.Columns = append(view.Columns, &domain.BoardColumnView{Column: column, Tasks: tasks})
+	}
+	return view, nil
+}
+
+// Move implements domains.BoardUsecase. The WIP limit is checked and the
+// task moved in one transaction that also locks the board, so concurrent
+// moves cannot overfill a column. The status change is published once the
+// move is committed.
+func (b *boardUsecase) Move(ctx context.Context, boardID string, userID string, move *domain.TaskMove) (*domain.Task, error) {
+	c, cancel := context.WithTimeout(context.Background(), b.contextTimeout)
+	defer cancel()
+
+	board, err := b.boardRepository.FetchById(c, boardID)
+	if err != nil {
+		return nil, err
+	}
+	column, ok := findColumn(board, move.ColumnID)
+	if !ok {
+		return nil, fmt.Errorf("no column found with id '%s'", move.ColumnID)
+	}
+
+	var task, previous *domain.Task
+	err = b.transactions.WithTransaction(c, func(txCtx context.Context) error {
+		if err := b.boardRepository.Lock(txCtx, board.BoardID); err != nil {
+			return err
+		}
+		found, err := b.taskRepository.FetchById(txCtx, move.TaskID)
+		if err != nil {
+			return fmt.Errorf("no task found with id '%s'", move.TaskID)
+		}
+		if board.ProjectID != "" && found.ProjectID != board.ProjectID {
+			return fmt.Errorf("task does not belong to the board's project")
+		}
+		if userID != found.CreatedBy && userID != found.AssigneeID {
+			return domain.ErrMoveNotAllowed
+		}
+
+		if column.WIPLimit > 0 && found.Status != column.Status {
+			count, err := b.taskRepository.CountMatching(txCtx, &domain.TaskFilter{
+				ProjectID: board.ProjectID,
+				Statuses:  []string{column.Status},
+			})
+			if err != nil {
+				return err
+			}
+			if count >= int64(column.WIPLimit) {
+				return fmt.Errorf("%w: '%s' allows %d tasks", domain.ErrWIPLimitReached, column.Name, column.WIPLimit)
+			}
+		}
+
+		tasks, err := b.columnTasks(txCtx, board, column)
+		if err != nil {
+			return err
+		}
+		siblings := make([]*domain.Task, 0, len(tasks))
+		for _, sibling := range tasks {
+			if sibling.TaskID != found.TaskID {
+				siblings = append(siblings, sibling)
+			}
+		}
+		if err := b.rankUnranked(txCtx, siblings); err != nil {
+			return err
+		}
+
+		lower, upper, err := neighbourRanks(siblings, move)
+		if err != nil {
+			return err
+		}
+		rank := rankBetween(lower, upper)
+		completedAt := completionTime(found, column.Status, time.Now())
+		if err := b.taskRepository.Move(txCtx, found.TaskID, userID, column.Status, rank, completedAt); err != nil {
+			return err
+		}
+
+		before := *found
+		found.Status = column.Status
+		found.Rank = rank
+		found.CompletedAt = completedAt
+		found.UpdatedBy = userID
+		task, previous = found, &before
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if previous.Status != task.Status {
+		publishTaskEvent(c, b.eventHandlers, &domain.TaskEvent{
+			Type:       domain.TaskStatusChanged,
+			Task:       task,
+			Previous:   previous,
+			ActorID:    userID,
+			OccurredAt: time.Now(),
+		})
+	}
+	return task, nil
+}
+
+func (b *boardUsecase) columnTasks(ctx context.Context, board *domain.Board, column domain.Column) ([]*domain.Task, error) {
+	tasks, err := b.taskRepository.FetchMatching(ctx, &domain.TaskFilter{
+		ProjectID: board.ProjectID,
+		Statuses:  []string{column.Status},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortByRank(tasks)
+	return tasks, nil
+}
+
+// rankUnranked gives tasks that were never placed on a board a rank after the
+// last ranked task, so they can be used as neighbours of a move.
+func (b *boardUsecase) rankUnranked(ctx context.Context, tasks []*domain.Task) error {
+	last := ""
+	for _, task := range tasks {
+		if task.Rank != "" {
+			last = task.Rank
+			continue
+		}
+		task.Rank = rankBetween(last, "")
+		if err := b.taskRepository.SetRank(ctx, task.TaskID, task.Rank); err != nil {
+			return err
+		}
+		last = task.Rank
+	}
+	return nil
+}
+
+// neighbourRanks returns the ranks the moved task has to be placed between.
+// An empty upper rank stands for the end of the column.
+func neighbourRanks(tasks []*domain.Task, move *domain.TaskMove) (lower string, upper string, err error) {
+	index := func(taskID string) int {
+		for i, task := range tasks {
+			if task.TaskID == taskID {
+				return i
+			}
+		}
+		return -1
+	}
+
+	switch {
+	case move.AfterTaskID != "" && move.BeforeTaskID != "":
+		after, before := index(move.AfterTaskID), index(move.BeforeTaskID)
+		if after < 0 || before < 0 {
+			return "", "", fmt.Errorf("neighbouring tasks must be in the target column")
+		}
+		if before != after+1 {
+			return "", "", fmt.Errorf("after_task_id and before_task_id must be adjacent")
+		}
+		return tasks[after].Rank, tasks[before].Rank, nil
+	case move.AfterTaskID != "":
+		after := index(move.AfterTaskID)
+		if after < 0 {
+			return "", "", fmt.Errorf("neighbouring tasks must be in the target column")
+		}
+		if after+1 < len(tasks) {
+			upper = tasks[after+1].Rank
+		}
+		return tasks[after].Rank, upper, nil
+	case move.BeforeTaskID != "":
+		before := index(move.BeforeTaskID)
+		if before < 0 {
+			return "", "", fmt.Errorf("neighbouring tasks must be in the target column")
+		}
+		if before > 0 {
+			lower = tasks[before-1].Rank
+		}
+		return lower, tasks[before].Rank, nil
+	default:
+		if len(tasks) > 0 {
+			lower = tasks[len(tasks)-1].Rank
+		}
+		return lower, "", nil
+	}
+}
+
+func sortByRank(tasks []*domain.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if (a.Rank == "") != (b.Rank == "") {
+			return a.Rank != ""
+		}
+		if a.Rank != b.Rank {
+			return a.Rank < b.Rank
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.TaskID < b.TaskID
+	})
+}
+
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// rankBetween returns a rank that sorts strictly between lower and upper.
+// Ranks are base-36 fractions, so there is always room for another one and a
+// move only rewrites the moved task. An empty upper means "no upper bound";
+// generated ranks never end in '0', which keeps room below every rank.
+func rankBetween(lower string, upper string) string {
+	if upper != "" {
+		n := 0
+		for n < len(upper) && rankDigitAt(lower, n) == upper[n] {
+			n++
+		}
+		if n > 0 {
+			rest := ""
+			if n < len(lower) {
+				rest = lower[n:]
+			}
+			return upper[:n] + rankBetween(rest, upper[n:])
+		}
+	}
+
+	lo := 0
+	if lower != "" {
+		lo = strings.IndexByte(rankDigits, lower[0])
+	}
+	hi := len(rankDigits)
+	if upper != "" {
+		hi = strings.IndexByte(rankDigits, upper[0])
+	}
+	if hi-lo > 1 {
+		return string(rankDigits[(lo+hi)/2])
+	}
+	if upper != "" && len(upper) > 1 {
+		return upper[:1]
+	}
+
+	rest := ""
+	if len(lower) > 1 {
+		rest = lower[1:]
+	}
+	return string(rankDigits[lo]) + rankBetween(rest, "")
+}
+
+func rankDigitAt(rank string, i int) byte {
+	if i < len(rank) {
+		return rank[i]
+	}
+	return '0'
+}
+
+func findColumn(board *domain.Board, columnID string) (domain.Column, bool) {
+	for _, column := range board.Columns {
+		if column.ColumnID == columnID {
+			return column, true
+		}
+	}
+	return domain.Column{}, false
+}
+
+func validateBoard(board *domain.Board) error {
+	board.Name = strings.TrimSpace(board.Name)
+	if board.Name == "" || len(board.Name) > 50 {
+		return fmt.Errorf("board name must be between 1 and 50 characters")
+	}
+	if len(board.Columns) == 0 {
+		return fmt.Errorf("a board needs at least one column")
+	}
+
+	statuses := map[string]bool{}
+	for i := range board.Columns {
+		column := &board.Columns[i]
+		column.Name = strings.TrimSpace(column.Name)
+		column.Status = strings.ToUpper(strings.TrimSpace(column.Status))
+		if column.Name == "" || len(column.Name) > 30 {
+			return fmt.Errorf("column name must be between 1 and 30 characters")
+		}
+		if column.Status == "" {
+			return fmt.Errorf("column '%s' needs a status", column.Name)
+		}
+		if statuses[column.Status] {
+			return fmt.Errorf("status '%s' is mapped to more than one column", column.Status)
+		}
+		statuses[column.Status] = true
+		if column.WIPLimit < 0 {
+			return fmt.Errorf("WIP limit of column '%s' must not be negative", column.Name)
+		}
+		if column.ColumnID == "" {
+			column.ColumnID = primitive.NewObjectID().Hex()
+		}
+	}
+	return nil
+}
+
+func NewBoardUsecase(boardRepository domain.BoardRepository, taskRepository domain.TaskRepository, transactions domain.TransactionManager, contextTimeout time.Duration, eventHandlers ...domain.TaskEventHandler) domain.BoardUsecase {
+	return &boardUsecase{
+		boardRepository: boardRepository,
+		taskRepository:  taskRepository,
+		transactions:    transactions,
+		contextTimeout:  contextTimeout,
+		eventHandlers:   eventHandlers,
+	}
+}
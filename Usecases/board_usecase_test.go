@@ -0,0 +1,79 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRankBetween(t *testing.T) {
+	tests := []struct {
+		name  string
+		lower string
+		upper string
+		want  string
+	}{
+		{"empty column", "", "", "i"},
+		{"at the bottom", "i", "", "r"},
+		{"at the top", "", "i", "9"},
+		{"after the last digit", "z", "", "zi"},
+		{"room between", "a", "c", "b"},
+		{"neighbouring digits", "a", "b", "ai"},
+		{"longer lower", "ab", "b", "an"},
+		{"longer upper", "a", "b5", "b"},
+		{"shared prefix", "a", "a1", "a0i"},
+		{"below the smallest digit", "", "1", "0i"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := rankBetween(test.lower, test.upper)
+			if got != test.want {
+				t.Errorf("rankBetween(%q, %q) = %q, want %q", test.lower, test.upper, got, test.want)
+			}
+			checkRank(t, test.lower, got, test.upper)
+		})
+	}
+}
+
+func TestRankBetweenRepeatedMoves(t *testing.T) {
+	tests := []struct {
+		name string
+		next func(lower string, upper string, rank string) (string, string)
+	}{
+		// each move lands just below the last one
+		{"towards the upper", func(lower, upper, rank string) (string, string) { return rank, upper }},
+		// each move lands just above the last one
+		{"towards the lower", func(lower, upper, rank string) (string, string) { return lower, rank }},
+		// each move goes to the top of the column
+		{"to the top", func(lower, upper, rank string) (string, string) { return "", rank }},
+		// each move goes to the bottom of the column
+		{"to the bottom", func(lower, upper, rank string) (string, string) { return rank, "" }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lower, upper := "a", "b"
+			for i := 0; i < 200; i++ {
+				rank := rankBetween(lower, upper)
+				if !checkRank(t, lower, rank, upper) {
+					t.Fatalf("move %d", i)
+				}
+				lower, upper = test.next(lower, upper, rank)
+			}
+		})
+	}
+}
+
+// checkRank reports whether rank sorts strictly between lower and upper and
+// does not end in '0'.
+func checkRank(t *testing.T, lower string, rank string, upper string) bool {
+	t.Helper()
+	ok := true
+	if rank <= lower || (upper != "" && rank >= upper) {
+		t.Errorf("rank %q does not sort between %q and %q", rank, lower, upper)
+		ok = false
+	}
+	if rank == "" || strings.HasSuffix(rank, "0") {
+		t.Errorf("rank %q is empty or ends in '0'", rank)
+		ok = false
+	}
+	return ok
+}
@@ -0,0 +1,210 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const (
+	maxHolidays       = 366
+	maxDueWorkingDays = 365
+)
+
+type calendarUsecase struct {
+	calendarRepository domain.CalendarRepository
+	contextTimeout     time.Duration
+}
+
+// Fetch implements domains.CalendarUsecase. Owners without a calendar get the
+// default one.
+func (cu *calendarUsecase) Fetch(ctx context.Context, ownerType string, ownerID string) (*domain.WorkingCalendar, error) {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return fetchCalendar(c, cu.calendarRepository, ownerType, ownerID)
+}
+
+// Update implements domains.CalendarUsecase.
+func (cu *calendarUsecase) Update(ctx context.Context, calendar *domain.WorkingCalendar) error {
+	if err := validateCalendar(calendar); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return cu.calendarRepository.Upsert(c, calendar)
+}
+
+// DueIn implements domains.CalendarUsecase. It counts on the project's
+// calendar, or the user's when the project has none.
+func (cu *calendarUsecase) DueIn(ctx context.Context, userID string, projectID string, workingDays int, from time.Time) (*domain.DueDateEstimate, error) {
+	if workingDays < 0 || workingDays > maxDueWorkingDays {
+		return nil, fmt.Errorf("working days must be between 0 and %d", maxDueWorkingDays)
+	}
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	calendar, err := taskCalendar(c, cu.calendarRepository, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	day := calendar.AddWorkingDays(from, workingDays)
+	dueDate, err := calendar.EndOfDay(day)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.DueDateEstimate{DueDay: day, DueDate: dueDate.In(calendar.Location()), TimeZone: calendar.TimeZone}, nil
+}
+
+// Location implements domains.CalendarUsecase. An explicit zone wins over the
+// user's calendar; anonymous callers get UTC. Only a bad explicit zone is an
+// error.
+func (cu *calendarUsecase) Location(ctx context.Context, userID string, override string) (*time.Location, error) {
+	if override != "" {
+		location, err := time.LoadLocation(override)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a time zone", override)
+		}
+		return location, nil
+	}
+	if userID == "" {
+		return time.UTC, nil
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	calendar, err := fetchCalendar(c, cu.calendarRepository, domain.CalendarOwnerUser, userID)
+	if err != nil {
+		// dates still render, only in UTC
+		log.Println("error loading calendar:", err)
+		return time.UTC, nil
+	}
+	return calendar.Location(), nil
+}
+
+func fetchCalendar(ctx context.Context, calendarRepository domain.CalendarRepository, ownerType string, ownerID string) (*domain.WorkingCalendar, error) {
+	calendars, err := calendarRepository.FetchMany(ctx, ownerType, []string{ownerID})
+	if err != nil {
+		return nil, err
+	}
+	if calendar, ok := calendars[ownerID]; ok {
+		return calendar, nil
+	}
+	return domain.DefaultCalendar(ownerType, ownerID), nil
+}
+
+// taskCalendar is the calendar a task's all-day due date is read in: its
+// project's when the project has one, the user's otherwise.
+func taskCalendar(ctx context.Context, calendarRepository domain.CalendarRepository, projectID string, userID string) (*domain.WorkingCalendar, error) {
+	if projectID != "" {
+		calendars, err := calendarRepository.FetchMany(ctx, domain.CalendarOwnerProject, []string{projectID})
+		if err != nil {
+			return nil, err
+		}
+		if calendar, ok := calendars[projectID]; ok {
+			return calendar, nil
+		}
+	}
+	return fetchCalendar(ctx, calendarRepository, domain.CalendarOwnerUser, userID)
+}
+
+// userLocations returns the time zone of each user, UTC for users without a
+// calendar.
+func userLocations(ctx context.Context, calendarRepository domain.CalendarRepository, userIDs []string) (map[string]*time.Location, error) {
+	calendars, err := calendarRepository.FetchMany(ctx, domain.CalendarOwnerUser, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	locations := make(map[string]*time.Location, len(userIDs))
+	for _, userID := range userIDs {
+		locations[userID] = time.UTC
+		if calendar, ok := calendars[userID]; ok {
+			locations[userID] = calendar.Location()
+		}
+	}
+	return locations, nil
+}
+
+// userLocation is the time zone of a single user, UTC when it cannot be
+// loaded.
+func userLocation(ctx context.Context, calendarRepository domain.CalendarRepository, userID string) *time.Location {
+	locations, err := userLocations(ctx, calendarRepository, []string{userID})
+	if err != nil {
+		log.Println("error loading calendar:", err)
+		return time.UTC
+	}
+	return locations[userID]
+}
+
+func validateCalendar(calendar *domain.WorkingCalendar) error {
+	if calendar.TimeZone == "" {
+		calendar.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(calendar.TimeZone); err != nil {
+		return fmt.Errorf("'%s' is not a time zone", calendar.TimeZone)
+	}
+
+	worked := map[string]bool{}
+	for _, day := range calendar.WorkingDays {
+		day = strings.ToUpper(day)
+		if !slices.Contains(domain.Weekdays, day) {
+			return fmt.Errorf("'%s' is not a weekday; use MON to SUN", day)
+		}
+		worked[day] = true
+	}
+	workingDays := []string{}
+	for _, weekday := range domain.Weekdays {
+		if worked[weekday] {
+			workingDays = append(workingDays, weekday)
+		}
+	}
+	if len(workingDays) == 0 {
+		return fmt.Errorf("a calendar needs at least one working day")
+	}
+	calendar.WorkingDays = workingDays
+
+	start, err := time.Parse("15:04", calendar.WorkStart)
+	if err != nil {
+		return fmt.Errorf("work_start must be a time like 09:00")
+	}
+	end, err := time.Parse("15:04", calendar.WorkEnd)
+	if err != nil {
+		return fmt.Errorf("work_end must be a time like 17:00")
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("work must start before it ends")
+	}
+
+	if len(calendar.Holidays) > maxHolidays {
+		return fmt.Errorf("a calendar can have at most %d holidays", maxHolidays)
+	}
+	seen := map[string]bool{}
+	holidays := []domain.Holiday{}
+	for _, holiday := range calendar.Holidays {
+		if _, err := time.Parse(time.DateOnly, holiday.Date); err != nil {
+			return fmt.Errorf("holiday '%s' must be a date like 2025-12-25", holiday.Date)
+		}
+		holiday.Name = strings.TrimSpace(holiday.Name)
+		if len(holiday.Name) > 50 {
+			return fmt.Errorf("holiday names must be at most 50 characters")
+		}
+		if !seen[holiday.Date] {
+			seen[holiday.Date] = true
+			holidays = append(holidays, holiday)
+		}
+	}
+	slices.SortFunc(holidays, func(a, b domain.Holiday) int { return strings.Compare(a.Date, b.Date) })
+	calendar.Holidays = holidays
+	return nil
+}
+
+func NewCalendarUsecase(calendarRepository domain.CalendarRepository, contextTimeout time.Duration) domain.CalendarUsecase {
+	return &calendarUsecase{
+		calendarRepository: calendarRepository,
+		contextTimeout:     contextTimeout,
+	}
+}
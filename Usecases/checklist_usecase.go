@@ -0,0 +1,199 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxChecklistItems = 100
+
+type checklistUsecase struct {
+	taskRepository domain.TaskRepository
+	contextTimeout time.Duration
+}
+
+// AddItem implements domains.ChecklistUsecase. New items go to the end of the list.
+func (cu *checklistUsecase) AddItem(ctx context.Context, taskID string, userID string, text string) (*domain.ChecklistItem, error) {
+	text, err := validateChecklistText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	task, err := cu.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTaskEditor(task, userID); err != nil {
+		return nil, err
+	}
+	if len(task.Checklist) >= maxChecklistItems {
+		return nil, fmt.Errorf("a checklist can hold at most %d items", maxChecklistItems)
+	}
+
+	lower := ""
+	if len(task.Checklist) > 0 {
+		lower = task.Checklist[len(task.Checklist)-1].Rank
+	}
+	id := primitive.NewObjectID()
+	item := &domain.ChecklistItem{
+		ItemID:    id.Hex(),
+		Text:      text,
+		Rank:      rankBetween(lower, ""),
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+	}
+	if err := cu.taskRepository.AddChecklistItem(c, taskID, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// UpdateItem implements domains.ChecklistUsecase.
+func (cu *checklistUsecase) UpdateItem(ctx context.Context, taskID string, itemID string, userID string, patch *domain.ChecklistItemPatch) error {
+	if patch.Text == nil && patch.Done == nil {
+		return fmt.Errorf("nothing to update")
+	}
+	if patch.Text != nil {
+		text, err := validateChecklistText(*patch.Text)
+		if err != nil {
+			return err
+		}
+		patch.Text = &text
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	if err := cu.checkEditor(c, taskID, userID); err != nil {
+		return err
+	}
+	return cu.taskRepository.UpdateChecklistItem(c, taskID, itemID, userID, patch)
+}
+
+// MoveItem implements domains.ChecklistUsecase.
+func (cu *checklistUsecase) MoveItem(ctx context.Context, taskID string, itemID string, userID string, move *domain.ChecklistItemMove) error {
+	if move.AfterItemID == itemID || move.BeforeItemID == itemID {
+		return fmt.Errorf("an item cannot be moved next to itself")
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	task, err := cu.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return err
+	}
+	if err := checkTaskEditor(task, userID); err != nil {
+		return err
+	}
+
+	others := make([]domain.ChecklistItem, 0, len(task.Checklist))
+	found := false
+	for _, item := range task.Checklist {
+		if item.ItemID == itemID {
+			found = true
+			continue
+		}
+		others = append(others, item)
+	}
+	if !found {
+		return fmt.Errorf("no checklist item found with id '%s'", itemID)
+	}
+
+	lower, upper, err := checklistNeighbourRanks(others, move)
+	if err != nil {
+		return err
+	}
+	return cu.taskRepository.SetChecklistItemRank(c, taskID, itemID, rankBetween(lower, upper))
+}
+
+// RemoveItem implements domains.ChecklistUsecase.
+func (cu *checklistUsecase) RemoveItem(ctx context.Context, taskID string, itemID string, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	if err := cu.checkEditor(c, taskID, userID); err != nil {
+		return err
+	}
+	return cu.taskRepository.RemoveChecklistItem(c, taskID, itemID)
+}
+
+// checkEditor loads the task and checks that the user may change it.
+func (cu *checklistUsecase) checkEditor(ctx context.Context, taskID string, userID string) error {
+	task, err := cu.taskRepository.FetchById(ctx, taskID)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskID)
+	}
+	return checkTaskEditor(task, userID)
+}
+
+// checklistNeighbourRanks returns the ranks the moved item has to fit between.
+// Without neighbours the item moves to the top of the list.
+func checklistNeighbourRanks(items []domain.ChecklistItem, move *domain.ChecklistItemMove) (lower string, upper string, err error) {
+	index := func(itemID string) int {
+		for i, item := range items {
+			if item.ItemID == itemID {
+				return i
+			}
+		}
+		return -1
+	}
+
+	switch {
+	case move.AfterItemID != "" && move.BeforeItemID != "":
+		after, before := index(move.AfterItemID), index(move.BeforeItemID)
+		if after < 0 || before < 0 {
+			return "", "", fmt.Errorf("neighbouring items must belong to the same checklist")
+		}
+		if before != after+1 {
+			return "", "", fmt.Errorf("after_item_id and before_item_id must be adjacent")
+		}
+		return items[after].Rank, items[before].Rank, nil
+	case move.AfterItemID != "":
+		after := index(move.AfterItemID)
+		if after < 0 {
+			return "", "", fmt.Errorf("neighbouring items must belong to the same checklist")
+		}
+		if after+1 < len(items) {
+			upper = items[after+1].Rank
+		}
+		return items[after].Rank, upper, nil
+	case move.BeforeItemID != "":
+		before := index(move.BeforeItemID)
+		if before < 0 {
+			return "", "", fmt.Errorf("neighbouring items must belong to the same checklist")
+		}
+		if before > 0 {
+			lower = items[before-1].Rank
+		}
+		return lower, items[before].Rank, nil
+	default:
+		if len(items) > 0 {
+			upper = items[0].Rank
+		}
+		return "", upper, nil
+	}
+}
+
+func validateChecklistText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || len(text) > 200 {
+		return "", fmt.Errorf("checklist item text must be between 1 and 200 characters")
+	}
+	return text, nil
+}
+
+func NewChecklistUsecase(taskRepository domain.TaskRepository, contextTimeout time.Duration) domain.ChecklistUsecase {
+	return &checklistUsecase{
+		taskRepository: taskRepository,
+		contextTimeout: contextTimeout,
+	}
+}
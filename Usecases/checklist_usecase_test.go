@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+func TestChecklistNeedsCreatorOrAssignee(t *testing.T) {
+	// the fake has no checklist writes, so a missing check panics
+	tasks := &fakeTaskRepository{tasks: map[string]*domain.Task{
+		"t1": {TaskID: "t1", CreatedBy: "alice", AssigneeID: "bob", Checklist: []domain.ChecklistItem{{ItemID: "i1", Text: "Write tests", Rank: "m"}}},
+	}}
+	usecase := NewChecklistUsecase(tasks, 5*time.Second)
+	text := "Edited"
+
+	changes := map[string]func() error{
+		"add": func() error {
+			_, err := usecase.AddItem(context.Background(), "t1", "mallory", "Ship it")
+			return err
+		},
+		"update": func() error {
+			return usecase.UpdateItem(context.Background(), "t1", "i1", "mallory", &domain.ChecklistItemPatch{Text: &text})
+		},
+		"move": func() error {
+			return usecase.MoveItem(context.Background(), "t1", "i1", "mallory", &domain.ChecklistItemMove{})
+		},
+		"remove": func() error {
+			return usecase.RemoveItem(context.Background(), "t1", "i1", "mallory")
+		},
+	}
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			if err := change(); !errors.Is(err, domain.ErrTaskChangeNotAllowed) {
+				t.Errorf("err = %v, want ErrTaskChangeNotAllowed", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type commentUsecase struct {
+	commentRepository domain.CommentRepository
+	taskRepository    domain.TaskRepository
+	contextTimeout    time.Duration
+	eventHandlers     []domain.CommentEventHandler
+}
+
+// Create implements domains.CommentUsecase.
+func (cu *commentUsecase) Create(ctx context.Context, comment *domain.Comment) error {
+	comment.Body = strings.TrimSpace(comment.Body)
+	if comment.Body == "" || len(comment.Body) > 5000 {
+		return fmt.Errorf("comment must be between 1 and 5000 characters")
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	if _, err := cu.taskRepository.FetchById(c, comment.TaskID); err != nil {
+		return fmt.Errorf("no task found with id '%s'", comment.TaskID)
+	}
+	if err := cu.commentRepository.Create(c, comment); err != nil {
+		return err
+	}
+
+	event := &domain.CommentEvent{Type: domain.CommentCreated, Comment: comment, OccurredAt: time.Now()}
+	for _, handler := range cu.eventHandlers {
+		handler.HandleCommentEvent(c, event)
+	}
+	return nil
+}
+
+// FetchByTask implements domains.CommentUsecase.
+func (cu *commentUsecase) FetchByTask(ctx context.Context, taskID string) ([]*domain.Comment, error) {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return cu.commentRepository.FetchByTask(c, taskID)
+}
+
+// DeleteById implements domains.CommentUsecase. Only the author may delete a comment.
+func (cu *commentUsecase) DeleteById(ctx context.Context, commentID string, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	comment, err := cu.commentRepository.FetchById(c, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != userID {
+		return fmt.Errorf("only the author can delete a comment")
+	}
+	return cu.commentRepository.DeleteById(c, commentID)
+}
+
+func NewCommentUsecase(commentRepository domain.CommentRepository, taskRepository domain.TaskRepository, contextTimeout time.Duration, eventHandlers ...domain.CommentEventHandler) domain.CommentUsecase {
+	return &commentUsecase{
+		commentRepository: commentRepository,
+		taskRepository:    taskRepository,
+		contextTimeout:    contextTimeout,
+		eventHandlers:     eventHandlers,
+	}
+}
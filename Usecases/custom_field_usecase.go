@@ -0,0 +1,213 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const (
+	maxCustomFieldTextLength = 500
+	maxCustomFieldOptions    = 50
+)
+
+type customFieldUsecase struct {
+	customFieldRepository domain.CustomFieldRepository
+	taskRepository        domain.TaskRepository
+	userRepository        domain.UserRepository
+	contextTimeout        time.Duration
+}
+
+// Create implements domains.CustomFieldUsecase.
+func (cu *customFieldUsecase) Create(ctx context.Context, field *domain.CustomField) error {
+	field.Key = strings.ToLower(strings.TrimSpace(field.Key))
+	if !domain.CustomFieldKeyPattern.MatchString(field.Key) {
+		return fmt.Errorf("key must start with a letter and hold at most 30 lowercase letters, digits or underscores")
+	}
+	if field.ProjectID == "" {
+		return fmt.Errorf("project_id is required")
+	}
+	field.Type = strings.ToUpper(field.Type)
+	switch field.Type {
+	case domain.CustomFieldText, domain.CustomFieldNumber, domain.CustomFieldDate, domain.CustomFieldEnum, domain.CustomFieldUser:
+	default:
+		return fmt.Errorf("type must be TEXT, NUMBER, DATE, ENUM or USER")
+	}
+	if err := validateCustomField(field); err != nil {
+		return err
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return cu.customFieldRepository.Create(c, field)
+}
+
+// FetchAll implements domains.CustomFieldUsecase.
+func (cu *customFieldUsecase) FetchAll(ctx context.Context, projectID string) ([]*domain.CustomField, error) {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return cu.customFieldRepository.FetchAll(c, projectID)
+}
+
+// FetchById implements domains.CustomFieldUsecase.
+func (cu *customFieldUsecase) FetchById(ctx context.Context, fieldID string) (*domain.CustomField, error) {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return cu.customFieldRepository.FetchById(c, fieldID)
+}
+
+// UpdateById implements domains.CustomFieldUsecase. Only the name and the enum
+// options can change; values of removed options stay on tasks until edited.
+func (cu *customFieldUsecase) UpdateById(ctx context.Context, fieldID string, field *domain.CustomField) error {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	existing, err := cu.customFieldRepository.FetchById(c, fieldID)
+	if err != nil {
+		return err
+	}
+	field.Key = existing.Key
+	field.Type = existing.Type
+	field.ProjectID = existing.ProjectID
+	if err := validateCustomField(field); err != nil {
+		return err
+	}
+	return cu.customFieldRepository.UpdateById(c, fieldID, field)
+}
+
+// DeleteById implements domains.CustomFieldUsecase.
+func (cu *customFieldUsecase) DeleteById(ctx context.Context, fieldID string) error {
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+	return cu.customFieldRepository.DeleteById(c, fieldID)
+}
+
+// SetValues implements domains.CustomFieldUsecase. Every key must be defined
+// for the task's project; a null value removes the key from the task.
+func (cu *customFieldUsecase) SetValues(ctx context.Context, taskID string, userID string, values map[string]interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("no custom field values given")
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), cu.contextTimeout)
+	defer cancel()
+
+	task, err := cu.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskID)
+	}
+	if err := checkTaskEditor(task, userID); err != nil {
+		return err
+	}
+	if task.ProjectID == "" {
+		return fmt.Errorf("custom fields need the task to belong to a project")
+	}
+	fields, err := cu.customFieldRepository.FetchAll(c, task.ProjectID)
+	if err != nil {
+		return err
+	}
+	definitions := make(map[string]*domain.CustomField, len(fields))
+	for _, field := range fields {
+		definitions[field.Key] = field
+	}
+
+	set := map[string]interface{}{}
+	var unset []string
+	for key, value := range values {
+		field, ok := definitions[key]
+		if !ok {
+			return fmt.Errorf("project '%s' has no custom field '%s'", task.ProjectID, key)
+		}
+		if value == nil {
+			unset = append(unset, key)
+			continue
+		}
+		converted, err := cu.convertValue(c, field, value)
+		if err != nil {
+			return err
+		}
+		set[key] = converted
+	}
+	return cu.taskRepository.SetCustomFields(c, taskID, userID, set, unset)
+}
+
+// convertValue checks a JSON value against the field type and returns the
+// form it is stored in.
+func (cu *customFieldUsecase) convertValue(ctx context.Context, field *domain.CustomField, value interface{}) (interface{}, error) {
+	if field.Type == domain.CustomFieldNumber {
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("'%s' must be a number", field.Key)
+		}
+		return number, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("'%s' must be a string", field.Key)
+	}
+	text = strings.TrimSpace(text)
+
+	switch field.Type {
+	case domain.CustomFieldDate:
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if date, err := time.Parse(layout, text); err == nil {
+				return date.UTC(), nil
+			}
+		}
+		return nil, fmt.Errorf("'%s' must be a date like 2025-08-01", field.Key)
+	case domain.CustomFieldEnum:
+		for _, option := range field.Options {
+			if option == text {
+				return text, nil
+			}
+		}
+		return nil, fmt.Errorf("'%s' must be one of: %s", field.Key, strings.Join(field.Options, ", "))
+	case domain.CustomFieldUser:
+		if _, err := cu.userRepository.FetchById(ctx, text); err != nil {
+			return nil, fmt.Errorf("'%s' must reference an existing user", field.Key)
+		}
+		return text, nil
+	default:
+		if text == "" || len(text) > maxCustomFieldTextLength {
+			return nil, fmt.Errorf("'%s' must be between 1 and %d characters", field.Key, maxCustomFieldTextLength)
+		}
+		return text, nil
+	}
+}
+
+func validateCustomField(field *domain.CustomField) error {
+	field.Name = strings.TrimSpace(field.Name)
+	if field.Name == "" || len(field.Name) > 50 {
+		return fmt.Errorf("field name must be between 1 and 50 characters")
+	}
+	if field.Type != domain.CustomFieldEnum {
+		field.Options = nil
+		return nil
+	}
+	if len(field.Options) == 0 || len(field.Options) > maxCustomFieldOptions {
+		return fmt.Errorf("an enum field needs between 1 and %d options", maxCustomFieldOptions)
+	}
+	seen := map[string]bool{}
+	for i, option := range field.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[option] {
+			return fmt.Errorf("enum options must be non-empty and unique")
+		}
+		seen[option] = true
+		field.Options[i] = option
+	}
+	return nil
+}
+
+func NewCustomFieldUsecase(customFieldRepository domain.CustomFieldRepository, taskRepository domain.TaskRepository, userRepository domain.UserRepository, contextTimeout time.Duration) domain.CustomFieldUsecase {
+	return &customFieldUsecase{
+		customFieldRepository: customFieldRepository,
+		taskRepository:        taskRepository,
+		userRepository:        userRepository,
+		contextTimeout:        contextTimeout,
+	}
+}
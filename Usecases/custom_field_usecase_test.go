@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// fakeTaskRepository only implements what the task sub-resources use.
+type fakeTaskRepository struct {
+	domain.TaskRepository
+	tasks map[string]*domain.Task
+}
+
+func (r *fakeTaskRepository) FetchById(ctx context.Context, taskId string) (*domain.Task, error) {
+	task, ok := r.tasks[taskId]
+	if !ok {
+		return nil, fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	copied := *task
+	return &copied, nil
+}
+
+func (r *fakeTaskRepository) SetCustomFields(ctx context.Context, taskId string, userID string, set map[string]interface{}, unset []string) error {
+	task := r.tasks[taskId]
+	if task.CustomFields == nil {
+		task.CustomFields = map[string]interface{}{}
+	}
+	for key, value := range set {
+		task.CustomFields[key] = value
+	}
+	for _, key := range unset {
+		delete(task.CustomFields, key)
+	}
+	return nil
+}
+
+type fakeCustomFieldRepository struct {
+	domain.CustomFieldRepository
+	fields []*domain.CustomField
+}
+
+func (r *fakeCustomFieldRepository) FetchAll(ctx context.Context, projectID string) ([]*domain.CustomField, error) {
+	return r.fields, nil
+}
+
+func TestCustomFieldSetValuesNeedsCreatorOrAssignee(t *testing.T) {
+	tasks := &fakeTaskRepository{tasks: map[string]*domain.Task{
+		"t1": {TaskID: "t1", ProjectID: "p1", CreatedBy: "alice", AssigneeID: "bob"},
+	}}
+	fields := &fakeCustomFieldRepository{fields: []*domain.CustomField{
+		{ProjectID: "p1", Key: "customer", Name: "Customer", Type: domain.CustomFieldText},
+	}}
+	usecase := NewCustomFieldUsecase(fields, tasks, &fakeUserRepository{}, 5*time.Second)
+
+	tests := []struct {
+		userID  string
+		allowed bool
+	}{
+		{"alice", true},
+		{"bob", true},
+		{"mallory", false},
+	}
+	for _, test := range tests {
+		t.Run(test.userID, func(t *testing.T) {
+			err := usecase.SetValues(context.Background(), "t1", test.userID, map[string]interface{}{"customer": test.userID})
+			if test.allowed && err != nil {
+				t.Fatal(err)
+			}
+			if !test.allowed {
+				if !errors.Is(err, domain.ErrTaskChangeNotAllowed) {
+					t.Fatalf("err = %v, want ErrTaskChangeNotAllowed", err)
+				}
+				if tasks.tasks["t1"].CustomFields["customer"] == test.userID {
+					t.Error("the value was set anyway")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// emailNotifier emails users when they are assigned a task or mentioned in a
+// comment. Messages are built while the event is handled and sent in the
+// background so a slow SMTP relay does not hold up the request.
+type emailNotifier struct {
+	mailer               domain.MailSender
+	preferenceRepository domain.NotificationPreferenceRepository
+	taskRepository       domain.TaskRepository
+	userRepository       domain.UserRepository
+	calendarRepository   domain.CalendarRepository
+}
+
+// HandleTaskEvent implements domains.TaskEventHandler.
+func (n *emailNotifier) HandleTaskEvent(ctx context.Context, event *domain.TaskEvent) {
+	task := event.Task
+	switch event.Type {
+	case domain.TaskCreated:
+	case domain.TaskUpdated:
+		if event.Previous == nil || event.Previous.AssigneeID == task.AssigneeID {
+			return
+		}
+	default:
+		return
+	}
+	if task.AssigneeID == "" || task.AssigneeID == event.ActorID {
+		return
+	}
+
+	recipient, ok := emailRecipient(ctx, n.userRepository, n.preferenceRepository, task.AssigneeID, domain.EmailAssignment)
+	if !ok {
+		return
+	}
+	message, err := renderEmail(domain.EmailAssignment, recipient.Email, fmt.Sprintf("You were assigned: %s", task.Title), &emailData{
+		Zone:      userLocation(ctx, n.calendarRepository, recipient.UserID),
+		Recipient: recipient.Username,
+		Actor:     n.username(ctx, event.ActorID),
+		Task:      task,
+	})
+	if err != nil {
+		log.Println("error rendering assignment email:", err)
+		return
+	}
+	n.send(message)
+}
+
+// HandleCommentEvent implements domains.CommentEventHandler.
+func (n *emailNotifier) HandleCommentEvent(ctx context.Context, event *domain.CommentEvent) {
+	comment := event.Comment
+	mentioned := mentionedUsers(ctx, n.userRepository, comment.Body)
+	if len(mentioned) == 0 {
+		return
+	}
+	task, err := n.taskRepository.FetchById(ctx, comment.TaskID)
+	if err != nil {
+		log.Println("error loading commented task:", err)
+		return
+	}
+
+	author := n.username(ctx, comment.AuthorID)
+	for _, userID := range mentioned {
+		if userID == comment.AuthorID {
+			continue
+		}
+		recipient, ok := emailRecipient(ctx, n.userRepository, n.preferenceRepository, userID, domain.EmailMention)
+		if !ok {
+			continue
+		}
+		message, err := renderEmail(domain.EmailMention, recipient.Email, fmt.Sprintf("%s mentioned you on %s", author, task.Title), &emailData{
+			Recipient: recipient.Username,
+			Actor:     author,
+			Task:      task,
+			Comment:   comment.Body,
+		})
+		if err != nil {
+			log.Println("error rendering mention email:", err)
+			continue
+		}
+		n.send(message)
+	}
+}
+
+func (n *emailNotifier) username(ctx context.Context, userID string) string {
+	user, err := n.userRepository.FetchById(ctx, userID)
+	if err != nil || user == nil {
+		return "Someone"
+	}
+	return user.Username
+}
+
+func (n *emailNotifier) send(message *domain.MailMessage) {
+	go func() {
+		if err := n.mailer.Send(message); err != nil {
+			log.Println("error sending email:", err)
+		}
+	}()
+}
+
+// emailRecipient loads the user an email of the given kind is for. It
+// reports false when the user has no verified address or opted out of the
+// kind.
+func emailRecipient(ctx context.Context, userRepository domain.UserRepository, preferenceRepository domain.NotificationPreferenceRepository, userID string, kind string) (*domain.User, bool) {
+	user, err := userRepository.FetchById(ctx, userID)
+	if err != nil || user == nil || user.Email == "" || !user.EmailVerified {
+		return nil, false
+	}
+	preferences, err := preferenceRepository.Fetch(ctx, userID)
+	if err != nil {
+		log.Println("error loading notification preferences:", err)
+		return nil, false
+	}
+	if slices.Contains(preferences.EmailDisabled, kind) {
+		return nil, false
+	}
+	return user, true
+}
+
+func NewEmailNotifier(mailer domain.MailSender, preferenceRepository domain.NotificationPreferenceRepository, taskRepository domain.TaskRepository, userRepository domain.UserRepository, calendarRepository domain.CalendarRepository) domain.EmailNotifier {
+	return &emailNotifier{
+		mailer:               mailer,
+		preferenceRepository: preferenceRepository,
+		taskRepository:       taskRepository,
+		userRepository:       userRepository,
+		calendarRepository:   calendarRepository,
+	}
+}
@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type fakeNotificationPreferenceRepository struct {
+	domain.NotificationPreferenceRepository
+	preferences map[string]*domain.NotificationPreferences
+	err         error
+}
+
+func (r *fakeNotificationPreferenceRepository) Fetch(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if preferences, ok := r.preferences[userID]; ok {
+		return preferences, nil
+	}
+	return &domain.NotificationPreferences{UserID: userID}, nil
+}
+
+func TestEmailRecipient(t *testing.T) {
+	users := &fakeUserRepository{users: map[string]*domain.User{
+		"alice": {UserID: "alice", Username: "alice", Email: "alice@example.com", EmailVerified: true},
+		"bob":   {UserID: "bob", Username: "bob", Email: "bob@example.com", EmailVerified: true},
+		"carol": {UserID: "carol", Username: "carol"},
+		"erin":  {UserID: "erin", Username: "erin", Email: "erin@example.com"},
+	}}
+	preferences := &fakeNotificationPreferenceRepository{preferences: map[string]*domain.NotificationPreferences{
+		"bob": {UserID: "bob", EmailDisabled: []string{domain.EmailDigest, domain.EmailMention}},
+	}}
+
+	tests := []struct {
+		name   string
+		userID string
+		kind   string
+		want   bool
+	}{
+		{"no preferences", "alice", domain.EmailDigest, true},
+		{"opted out", "bob", domain.EmailDigest, false},
+		{"opted out of another kind", "bob", domain.EmailAssignment, true},
+		{"no address", "carol", domain.EmailAssignment, false},
+		{"unverified address", "erin", domain.EmailAssignment, false},
+		{"unknown user", "dave", domain.EmailAssignment, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, ok := emailRecipient(context.Background(), users, preferences, test.userID, test.kind)
+			if ok != test.want {
+				t.Fatalf("ok = %v, want %v", ok, test.want)
+			}
+			if ok && user.UserID != test.userID {
+				t.Errorf("user = %s, want %s", user.UserID, test.userID)
+			}
+		})
+	}
+}
+
+func TestEmailRecipientWithoutPreferences(t *testing.T) {
+	users := &fakeUserRepository{users: map[string]*domain.User{
+		"alice": {UserID: "alice", Email: "alice@example.com", EmailVerified: true},
+	}}
+	// better no email than one the user may have turned off
+	preferences := &fakeNotificationPreferenceRepository{err: fmt.Errorf("database is down")}
+	if _, ok := emailRecipient(context.Background(), users, preferences, "alice", domain.EmailAssignment); ok {
+		t.Error("email was sent without knowing the preferences")
+	}
+}
@@ -0,0 +1,165 @@
+package usecases
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// emailData is what the email templates can use. Only the fields an email
+// needs are set. Dates are shown in Zone, the recipient's time zone.
+type emailData struct {
+	Zone      *time.Location
+	Recipient string
+	Actor     string
+	Task      *domain.Task
+	Comment   string
+	Date      time.Time
+	Overdue   []*domain.Task
+	DueToday  []*domain.Task
+	Link      string
+	Token     string
+}
+
+var emailFuncs = map[string]interface{}{
+	"day": func(t time.Time) string { return t.Format("Mon, 02 Jan 2006") },
+	"due": formatDue,
+}
+
+// formatDue shows all-day due dates as the day only.
+func formatDue(task *domain.Task, zone *time.Location) string {
+	if day, err := time.Parse(time.DateOnly, task.DueDay); err == nil {
+		return day.Format("Mon, 02 Jan 2006")
+	}
+	if zone == nil {
+		zone = time.UTC
+	}
+	return task.DueDate.In(zone).Format("Mon, 02 Jan 2006 15:04 MST")
+}
+
+const textEmails = `
+{{define "ASSIGNMENT"}}Hi {{.Recipient}},
+
+{{.Actor}} assigned you "{{.Task.Title}}" ({{.Task.Priority}}, {{.Task.Status}}).
+{{if not .Task.DueDate.IsZero}}It is due {{due .Task $.Zone}}.
+{{end}}{{if .Task.Description}}
+{{.Task.Description}}
+{{end}}
+Task id: {{.Task.TaskID}}
+{{end}}
+
+{{define "MENTION"}}Hi {{.Recipient}},
+
+{{.Actor}} mentioned you on "{{.Task.Title}}":
+
+{{.Comment}}
+
+Task id: {{.Task.TaskID}}
+{{end}}
+
+{{define "DUE_REMINDER"}}Hi {{.Recipient}},
+
+"{{.Task.Title}}" is due {{due .Task $.Zone}} and is still {{.Task.Status}}.
+
+Task id: {{.Task.TaskID}}
+{{end}}
+
+{{define "DIGEST"}}Hi {{.Recipient}},
+
+Your tasks for {{day .Date}}.
+{{if .Overdue}}
+Overdue:
+{{range .Overdue}}  - {{.Title}} ({{.Priority}}), due {{due . $.Zone}}
+{{end}}{{end}}{{if .DueToday}}
+Due today:
+{{range .DueToday}}  - {{.Title}} ({{.Priority}}), due {{due . $.Zone}}
+{{end}}{{end}}{{if not (or .Overdue .DueToday)}}
+Nothing is due or overdue.
+{{end}}{{end}}
+
+{{define "PASSWORD_RESET"}}Hi {{.Recipient}},
+
+Someone asked to reset the password of your account. {{if .Link}}Open this link to choose a new one:
+
+{{.Link}}{{else}}Use this token to choose a new one:
+
+{{.Token}}{{end}}
+
+It works once and expires {{.Date.Format "Mon, 02 Jan 2006 15:04 MST"}}. If you did not ask for it, ignore this email and your password stays the same.
+{{end}}
+
+{{define "EMAIL_VERIFICATION"}}Hi {{.Recipient}},
+
+This address was added to your account. {{if .Link}}Open this link to confirm it is yours:
+
+{{.Link}}{{else}}Use this token to confirm it is yours:
+
+{{.Token}}{{end}}
+
+It works once and expires {{.Date.Format "Mon, 02 Jan 2006 15:04 MST"}}. If you did not add it, ignore this email.
+{{end}}
+`
+
+const htmlEmails = `
+{{define "ASSIGNMENT"}}<p>Hi {{.Recipient}},</p>
+<p>{{.Actor}} assigned you <strong>{{.Task.Title}}</strong> ({{.Task.Priority}}, {{.Task.Status}}).{{if not .Task.DueDate.IsZero}} It is due {{due .Task $.Zone}}.{{end}}</p>
+{{if .Task.Description}}<blockquote>{{.Task.Description}}</blockquote>{{end}}
+<p style="color:#888">Task id: {{.Task.TaskID}}</p>{{end}}
+
+{{define "MENTION"}}<p>Hi {{.Recipient}},</p>
+<p>{{.Actor}} mentioned you on <strong>{{.Task.Title}}</strong>:</p>
+<blockquote>{{.Comment}}</blockquote>
+<p style="color:#888">Task id: {{.Task.TaskID}}</p>{{end}}
+
+{{define "DUE_REMINDER"}}<p>Hi {{.Recipient}},</p>
+<p><strong>{{.Task.Title}}</strong> is due {{due .Task $.Zone}} and is still {{.Task.Status}}.</p>
+<p style="color:#888">Task id: {{.Task.TaskID}}</p>{{end}}
+
+{{define "DIGEST"}}<p>Hi {{.Recipient}},</p>
+<p>Your tasks for {{day .Date}}.</p>
+{{if .Overdue}}<h3>Overdue</h3><ul>{{range .Overdue}}<li><strong>{{.Title}}</strong> ({{.Priority}}), due {{due . $.Zone}}</li>{{end}}</ul>{{end}}
+{{if .DueToday}}<h3>Due today</h3><ul>{{range .DueToday}}<li><strong>{{.Title}}</strong> ({{.Priority}}), due {{due . $.Zone}}</li>{{end}}</ul>{{end}}
+{{if not (or .Overdue .DueToday)}}<p>Nothing is due or overdue.</p>{{end}}{{end}}
+
+{{define "PASSWORD_RESET"}}<p>Hi {{.Recipient}},</p>
+<p>Someone asked to reset the password of your account.</p>
+{{if .Link}}<p><a href="{{.Link}}">Choose a new password</a></p>{{else}}<p>Use this token to choose a new one:</p>
+<p><code>{{.Token}}</code></p>{{end}}
+<p style="color:#888">It works once and expires {{.Date.Format "Mon, 02 Jan 2006 15:04 MST"}}. If you did not ask for it, ignore this email and your password stays the same.</p>{{end}}
+
+{{define "EMAIL_VERIFICATION"}}<p>Hi {{.Recipient}},</p>
+<p>This address was added to your account.</p>
+{{if .Link}}<p><a href="{{.Link}}">Confirm your email</a></p>{{else}}<p>Use this token to confirm it is yours:</p>
+<p><code>{{.Token}}</code></p>{{end}}
+<p style="color:#888">It works once and expires {{.Date.Format "Mon, 02 Jan 2006 15:04 MST"}}. If you did not add it, ignore this email.</p>{{end}}
+`
+
+var (
+	textEmailTemplates = texttemplate.Must(texttemplate.New("text").Funcs(emailFuncs).Parse(textEmails))
+	htmlEmailTemplates = htmltemplate.Must(htmltemplate.New("html").Funcs(emailFuncs).Parse(htmlEmails))
+)
+
+// renderEmail fills both bodies of the email of the given kind. The HTML
+// template escapes task and comment text.
+func renderEmail(kind string, to string, subject string, data *emailData) (*domain.MailMessage, error) {
+	var text, html bytes.Buffer
+	if err := textEmailTemplates.ExecuteTemplate(&text, kind, data); err != nil {
+		return nil, err
+	}
+	if err := htmlEmailTemplates.ExecuteTemplate(&html, kind, data); err != nil {
+		return nil, err
+	}
+	recipients := []string{}
+	if to != "" {
+		recipients = append(recipients, to)
+	}
+	return &domain.MailMessage{
+		To:       recipients,
+		Subject:  subject,
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type emailUsecase struct {
+	userRepository              domain.UserRepository
+	emailVerificationRepository domain.EmailVerificationRepository
+	notifier                    domain.EmailVerificationNotifier
+	password                    domain.PasswordServiceProvider
+	contextTimeout              time.Duration
+}
+
+// Change implements domains.EmailUsecase. Whoever holds a token of the
+// account cannot move its password resets to their own address without the
+// password.
+func (eu *emailUsecase) Change(ctx context.Context, userID string, email string, currentPassword string) error {
+	c, cancel := context.WithTimeout(context.Background(), eu.contextTimeout)
+	defer cancel()
+
+	user, err := eu.userRepository.FetchById(c, userID)
+	if err != nil || user == nil {
+		return fmt.Errorf("no user found with id '%s'", userID)
+	}
+	if email == user.Email {
+		return nil
+	}
+	if user.Password == "" {
+		return fmt.Errorf("your account signs in through single sign-on and has no password to confirm the change")
+	}
+	if ok, _ := eu.password.VerifyPassword(user.Password, currentPassword); !ok {
+		return fmt.Errorf("current password is incorrect")
+	}
+	if err := eu.userRepository.UpdateEmail(c, user.UserID, email); err != nil {
+		return err
+	}
+	// tokens sent to the old address must not verify anything
+	if err := eu.emailVerificationRepository.RevokeUser(c, user.UserID, time.Now()); err != nil {
+		return err
+	}
+	if email == "" {
+		return nil
+	}
+	user.Email = email
+	return eu.send(c, user)
+}
+
+// SendVerification implements domains.EmailUsecase.
+func (eu *emailUsecase) SendVerification(ctx context.Context, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), eu.contextTimeout)
+	defer cancel()
+
+	user, err := eu.userRepository.FetchById(c, userID)
+	if err != nil || user == nil {
+		return fmt.Errorf("no user found with id '%s'", userID)
+	}
+	if user.Email == "" {
+		return fmt.Errorf("you have no email address")
+	}
+	if user.EmailVerified {
+		return fmt.Errorf("your email is already verified")
+	}
+	recent, err := eu.emailVerificationRepository.CountSince(c, user.UserID, time.Now().Add(-domain.EmailVerificationCooldown))
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return fmt.Errorf("a verification email was just sent, try again in a minute")
+	}
+	return eu.send(c, user)
+}
+
+// Verify implements domains.EmailUsecase.
+func (eu *emailUsecase) Verify(ctx context.Context, token string) error {
+	c, cancel := context.WithTimeout(context.Background(), eu.contextTimeout)
+	defer cancel()
+
+	verification, err := eu.emailVerificationRepository.Consume(c, hashResetToken(token), time.Now())
+	if err != nil {
+		return err
+	}
+	if verification == nil {
+		return fmt.Errorf("verification token is invalid, expired or was already used")
+	}
+	verified, err := eu.userRepository.VerifyEmail(c, verification.UserID, verification.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return fmt.Errorf("the email has changed since the token was sent")
+	}
+	return nil
+}
+
+// send replaces the pending tokens of the user with one for user.Email.
+func (eu *emailUsecase) send(ctx context.Context, user *domain.User) error {
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := eu.emailVerificationRepository.RevokeUser(ctx, user.UserID, now); err != nil {
+		return err
+	}
+	verification := &domain.EmailVerification{
+		TokenHash: hashResetToken(token),
+		UserID:    user.UserID,
+		Email:     user.Email,
+		CreatedAt: now,
+		ExpiresAt: now.Add(domain.EmailVerificationLifetime),
+	}
+	if err := eu.emailVerificationRepository.Create(ctx, verification); err != nil {
+		return err
+	}
+	if err := eu.notifier.NotifyEmailVerification(ctx, user, token, verification.ExpiresAt); err != nil {
+		log.Println("error sending email verification:", err)
+	}
+	return nil
+}
+
+func NewEmailUsecase(userRepository domain.UserRepository, emailVerificationRepository domain.EmailVerificationRepository, notifier domain.EmailVerificationNotifier, password domain.PasswordServiceProvider, contextTimeout time.Duration) domain.EmailUsecase {
+	return &emailUsecase{
+		userRepository:              userRepository,
+		emailVerificationRepository: emailVerificationRepository,
+		notifier:                    notifier,
+		password:                    password,
+		contextTimeout:              contextTimeout,
+	}
+}
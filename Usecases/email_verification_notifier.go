@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// emailVerificationNotifier emails verification tokens to the address they
+// verify. With a verify URL the email links to it with the token in the
+// token query parameter, otherwise it shows the token itself.
+type emailVerificationNotifier struct {
+	mailer    domain.MailSender
+	verifyURL string
+}
+
+// NotifyEmailVerification implements domains.EmailVerificationNotifier. The
+// email is sent in the background so a slow SMTP relay does not hold up the
+// request.
+func (n *emailVerificationNotifier) NotifyEmailVerification(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	if user.Email == "" {
+		return fmt.Errorf("user '%s' has no email address", user.UserID)
+	}
+	data := &emailData{Recipient: user.Username, Date: expiresAt.UTC(), Token: token}
+	if n.verifyURL != "" {
+		link, err := url.Parse(n.verifyURL)
+		if err != nil {
+			return err
+		}
+		query := link.Query()
+		query.Set("token", token)
+		link.RawQuery = query.Encode()
+		data.Link = link.String()
+	}
+	message, err := renderEmail(domain.EmailAddressVerification, user.Email, "Verify your email", data)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := n.mailer.Send(message); err != nil {
+			log.Println("error sending email verification:", err)
+		}
+	}()
+	return nil
+}
+
+func NewEmailVerificationNotifier(mailer domain.MailSender, verifyURL string) domain.EmailVerificationNotifier {
+	return &emailVerificationNotifier{
+		mailer:    mailer,
+		verifyURL: verifyURL,
+	}
+}
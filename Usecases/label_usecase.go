@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+var labelColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+type labelUsecase struct {
+	labelRepository domain.LabelRepository
+	taskRepository  domain.TaskRepository
+	contextTimeout  time.Duration
+}
+
+// Create implements domains.LabelUsecase.
+func (l *labelUsecase) Create(ctx context.Context, label *domain.Label) error {
+	if err := validateLabel(label); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+	return l.labelRepository.Create(c, label)
+}
+
+// FetchAll implements domains.LabelUsecase.
+func (l *labelUsecase) FetchAll(ctx context.Context, projectID string) ([]*domain.Label, error) {
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+	return l.labelRepository.FetchAll(c, projectID)
+}
+
+// FetchById implements domains.LabelUsecase.
+func (l *labelUsecase) FetchById(ctx context.Context, labelID string) (*domain.Label, error) {
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+	return l.labelRepository.FetchById(c, labelID)
+}
+
+// UpdateById implements domains.LabelUsecase.
+func (l *labelUsecase) UpdateById(ctx context.Context, labelID string, label *domain.Label) error {
+	if err := validateLabel(label); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+	return l.labelRepository.UpdateById(c, labelID, label)
+}
+
+// DeleteById implements domains.LabelUsecase.
+func (l *labelUsecase) DeleteById(ctx context.Context, labelID string) error {
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+	return l.labelRepository.DeleteById(c, labelID)
+}
+
+// Usage implements domains.LabelUsecase.
+func (l *labelUsecase) Usage(ctx context.Context, projectID string) ([]*domain.LabelUsage, error) {
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+
+	labels, err := l.labelRepository.FetchAll(c, projectID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := l.labelRepository.UsageCounts(c)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]*domain.LabelUsage, 0, len(labels))
+	for _, label := range labels {
+		usage = append(usage, &domain.LabelUsage{Label: label, Count: counts[label.Name]})
+	}
+	return usage, nil
+}
+
+// Merge implements domains.LabelUsecase.
+func (l *labelUsecase) Merge(ctx context.Context, sourceID string, targetID string) error {
+	if sourceID == targetID {
+		return fmt.Errorf("cannot merge a label into itself")
+	}
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+	return l.labelRepository.Merge(c, sourceID, targetID)
+}
+
+// Attach implements domains.LabelUsecase.
+func (l *labelUsecase) Attach(ctx context.Context, taskID string, userID string, labelName string) error {
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+
+	label, err := l.labelRepository.FetchByName(c, labelName)
+	if err != nil {
+		return err
+	}
+	task, err := l.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskID)
+	}
+	if err := checkTaskEditor(task, userID); err != nil {
+		return err
+	}
+	if label.Scope == domain.LabelScopeProject && label.ProjectID != task.ProjectID {
+		return fmt.Errorf("label '%s' belongs to another project", label.Name)
+	}
+	return l.taskRepository.AddLabel(c, taskID, label.Name)
+}
+
+// Detach implements domains.LabelUsecase.
+func (l *labelUsecase) Detach(ctx context.Context, taskID string, userID string, labelName string) error {
+	c, cancel := context.WithTimeout(context.Background(), l.contextTimeout)
+	defer cancel()
+
+	task, err := l.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskID)
+	}
+	if err := checkTaskEditor(task, userID); err != nil {
+		return err
+	}
+	return l.taskRepository.RemoveLabel(c, taskID, labelName)
+}
+
+func validateLabel(label *domain.Label) error {
+	label.Name = strings.TrimSpace(label.Name)
+	if label.Name == "" || len(label.Name) > 30 {
+		return fmt.Errorf("label name must be between 1 and 30 characters")
+	}
+	if !labelColor.MatchString(label.Color) {
+		return fmt.Errorf("label color must be a hex color like #1F77B4")
+	}
+	if label.Scope == "" {
+		label.Scope = domain.LabelScopeGlobal
+	}
+	switch label.Scope {
+	case domain.LabelScopeGlobal:
+		label.ProjectID = ""
+	case domain.LabelScopeProject:
+		if label.ProjectID == "" {
+			return fmt.Errorf("project_id is required for a project label")
+		}
+	default:
+		return fmt.Errorf("scope must be GLOBAL or PROJECT")
+	}
+	return nil
+}
+
+func NewLabelUsecase(labelRepository domain.LabelRepository, taskRepository domain.TaskRepository, contextTimeout time.Duration) domain.LabelUsecase {
+	return &labelUsecase{
+		labelRepository: labelRepository,
+		taskRepository:  taskRepository,
+		contextTimeout:  contextTimeout,
+	}
+}
@@ -0,0 +1,87 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// fakeLabelRepository only implements what attaching a label uses.
+type fakeLabelRepository struct {
+	domain.LabelRepository
+	labels map[string]*domain.Label
+}
+
+func (r *fakeLabelRepository) FetchByName(ctx context.Context, name string) (*domain.Label, error) {
+	label, ok := r.labels[name]
+	if !ok {
+		return nil, fmt.Errorf("no label named '%s'", name)
+	}
+	return label, nil
+}
+
+// labelTaskRepository keeps the labels of the tasks of a fakeTaskRepository.
+type labelTaskRepository struct {
+	*fakeTaskRepository
+}
+
+func (r *labelTaskRepository) AddLabel(ctx context.Context, taskId string, label string) error {
+	r.tasks[taskId].Labels = append(r.tasks[taskId].Labels, label)
+	return nil
+}
+
+func (r *labelTaskRepository) RemoveLabel(ctx context.Context, taskId string, label string) error {
+	task := r.tasks[taskId]
+	for i, name := range task.Labels {
+		if name == label {
+			task.Labels = append(task.Labels[:i], task.Labels[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func TestLabelAttachNeedsCreatorOrAssignee(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		allowed bool
+	}{
+		{"creator", "alice", true},
+		{"assignee", "bob", true},
+		{"someone else", "mallory", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tasks := &labelTaskRepository{&fakeTaskRepository{tasks: map[string]*domain.Task{
+				"t1": {TaskID: "t1", CreatedBy: "alice", AssigneeID: "bob", Labels: []string{"urgent"}},
+			}}}
+			labels := &fakeLabelRepository{labels: map[string]*domain.Label{
+				"bug": {LabelID: "l1", Name: "bug", Scope: domain.LabelScopeGlobal},
+			}}
+			usecase := NewLabelUsecase(labels, tasks, 5*time.Second)
+
+			attachErr := usecase.Attach(context.Background(), "t1", test.userID, "bug")
+			detachErr := usecase.Detach(context.Background(), "t1", test.userID, "urgent")
+			if test.allowed {
+				if attachErr != nil || detachErr != nil {
+					t.Fatalf("attach err = %v, detach err = %v", attachErr, detachErr)
+				}
+				if got := tasks.tasks["t1"].Labels; len(got) != 1 || got[0] != "bug" {
+					t.Errorf("labels = %v, want [bug]", got)
+				}
+				return
+			}
+			if !errors.Is(attachErr, domain.ErrTaskChangeNotAllowed) || !errors.Is(detachErr, domain.ErrTaskChangeNotAllowed) {
+				t.Errorf("attach err = %v, detach err = %v, want ErrTaskChangeNotAllowed", attachErr, detachErr)
+			}
+			if got := tasks.tasks["t1"].Labels; len(got) != 1 || got[0] != "urgent" {
+				t.Errorf("labels = %v, want them unchanged", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type loginThrottleUsecase struct {
+	store           domain.LoginAttemptStore
+	auditRepository domain.AuditRepository
+	policy          domain.LoginPolicy
+	contextTimeout  time.Duration
+}
+
+// Check implements domains.LoginThrottleUsecase. The longest wait of the
+// username and the address wins. A scope that has to wait between attempts
+// reserves its wait before the password is checked, so concurrent guesses
+// cannot all slip through while the first one is still being verified.
+//
+// Every attempt that gets through, free ones included, is counted as a
+// failure right away; Succeed takes it back. A burst that arrives before
+// any of its passwords were checked therefore sees its own attempts and
+// cannot go past the free failures or MaxFailures.
+func (lu *loginThrottleUsecase) Check(ctx context.Context, username string, ip string) (*domain.LoginBlock, error) {
+	c, cancel := context.WithTimeout(context.Background(), lu.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	var block *domain.LoginBlock
+	waits := map[string]time.Duration{}
+	scopes := loginScopes(username, ip)
+	for _, scope := range scopes {
+		attempt, err := lu.store.Fetch(c, scope[0], scope[1])
+		if err != nil {
+			return nil, err
+		}
+		if attempt == nil {
+			continue
+		}
+		if !attempt.RetryAt.After(now) {
+			waits[scope[0]] = lu.policy.Delay(scope[0], attempt.Failures)
+			continue
+		}
+		if wait := attempt.RetryAt.Sub(now); block == nil || wait > block.RetryAfter {
+			block = &domain.LoginBlock{Scope: scope[0], Locked: attempt.Locked(now), RetryAfter: wait}
+		}
+	}
+	if block != nil {
+		return block, nil
+	}
+
+	for _, scope := range scopes {
+		wait := waits[scope[0]]
+		if wait <= 0 {
+			continue
+		}
+		reserved, err := lu.store.Reserve(c, scope[0], scope[1], now, now.Add(wait), lu.policy.Window)
+		if err != nil {
+			return nil, err
+		}
+		if !reserved && (block == nil || wait > block.RetryAfter) {
+			block = &domain.LoginBlock{Scope: scope[0], RetryAfter: wait}
+		}
+	}
+	if block != nil {
+		return block, nil
+	}
+
+	var counted [][2]string
+	for _, scope := range scopes {
+		attempt, err := lu.store.Fail(c, scope[0], scope[1], now, lu.policy.Window)
+		if err != nil {
+			lu.refund(c, counted)
+			return nil, err
+		}
+		counted = append(counted, scope)
+
+		// attempts in flight count as failures until they are decided; this
+		// one has to wait for them unless it reserved that wait above
+		var scopeBlock *domain.LoginBlock
+		if attempt.Failures > lu.policy.Limit(scope[0]).MaxFailures {
+			scopeBlock = &domain.LoginBlock{Scope: scope[0], Locked: true, RetryAfter: lu.policy.Lockout}
+		} else if owed := lu.policy.Delay(scope[0], attempt.Failures-1); owed > waits[scope[0]] {
+			scopeBlock = &domain.LoginBlock{Scope: scope[0], RetryAfter: owed}
+		}
+		if scopeBlock != nil && (block == nil || scopeBlock.RetryAfter > block.RetryAfter) {
+			block = scopeBlock
+		}
+	}
+	if block != nil {
+		// a refused attempt checks no password, so it is no failure
+		lu.refund(c, counted)
+	}
+	return block, nil
+}
+
+// Fail implements domains.LoginThrottleUsecase. A scope that reaches its
+// maximum is locked out, every other failure past the free ones makes it
+// wait a little longer. The failures include attempts still in flight, which
+// only makes the wait longer.
+func (lu *loginThrottleUsecase) Fail(ctx context.Context, username string, ip string) (*domain.LoginBlock, error) {
+	c, cancel := context.WithTimeout(context.Background(), lu.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	var block *domain.LoginBlock
+	for _, scope := range loginScopes(username, ip) {
+		attempt, err := lu.store.Fetch(c, scope[0], scope[1])
+		if err != nil {
+			return nil, err
+		}
+		// an admin unlocked the scope while the password was checked
+		if attempt == nil {
+			continue
+		}
+
+		locked := attempt.Failures >= lu.policy.Limit(scope[0]).MaxFailures
+		wait := lu.policy.Delay(scope[0], attempt.Failures)
+		if locked {
+			wait = lu.policy.Lockout
+		}
+		if wait <= 0 {
+			continue
+		}
+		if err := lu.store.Block(c, scope[0], scope[1], now.Add(wait), locked, lu.policy.Window); err != nil {
+			return nil, err
+		}
+		// only the failure that starts a lockout is audited
+		if locked && !attempt.Locked(now) {
+			lu.audit(c, &domain.AuditEvent{
+				Type:    domain.AuditLoginLockout,
+				Subject: scope[0] + ":" + scope[1],
+				IP:      ip,
+				Details: map[string]string{
+					"failures":     strconv.Itoa(attempt.Failures),
+					"locked_until": now.Add(wait).UTC().Format(time.RFC3339),
+				},
+				OccurredAt: now,
+			})
+		}
+		if block == nil || wait > block.RetryAfter {
+			block = &domain.LoginBlock{Scope: scope[0], Locked: locked, RetryAfter: wait}
+		}
+	}
+	return block, nil
+}
+
+// Succeed implements domains.LoginThrottleUsecase.
+func (lu *loginThrottleUsecase) Succeed(ctx context.Context, username string, ip string) error {
+	c, cancel := context.WithTimeout(context.Background(), lu.contextTimeout)
+	defer cancel()
+
+	if ip != "" {
+		if err := lu.store.Refund(c, domain.LoginScopeIP, ip); err != nil {
+			return err
+		}
+	}
+	_, err := lu.store.Reset(c, domain.LoginScopeUsername, normalizeLoginUsername(username))
+	return err
+}
+
+// Locked implements domains.LoginThrottleUsecase.
+func (lu *loginThrottleUsecase) Locked(ctx context.Context) ([]*domain.LoginAttempt, error) {
+	c, cancel := context.WithTimeout(context.Background(), lu.contextTimeout)
+	defer cancel()
+	return lu.store.FetchLocked(c, time.Now())
+}
+
+// Unlock implements domains.LoginThrottleUsecase.
+func (lu *loginThrottleUsecase) Unlock(ctx context.Context, scope string, value string, actorID string, ip string) error {
+	if scope != domain.LoginScopeUsername && scope != domain.LoginScopeIP {
+		return fmt.Errorf("scope must be %s or %s", domain.LoginScopeUsername, domain.LoginScopeIP)
+	}
+	if scope == domain.LoginScopeUsername {
+		value = normalizeLoginUsername(value)
+	}
+	c, cancel := context.WithTimeout(context.Background(), lu.contextTimeout)
+	defer cancel()
+
+	found, err := lu.store.Reset(c, scope, value)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no failed logins recorded for %s '%s'", scope, value)
+	}
+	lu.audit(c, &domain.AuditEvent{
+		Type:       domain.AuditLoginUnlock,
+		ActorID:    actorID,
+		Subject:    scope + ":" + value,
+		IP:         ip,
+		OccurredAt: time.Now(),
+	})
+	return nil
+}
+
+// refund takes back the attempts Check counted for the scopes.
+func (lu *loginThrottleUsecase) refund(ctx context.Context, scopes [][2]string) {
+	for _, scope := range scopes {
+		if err := lu.store.Refund(ctx, scope[0], scope[1]); err != nil {
+			log.Println("error refunding login attempt:", err)
+		}
+	}
+}
+
+// audit only logs when the event cannot be stored; the login itself has
+// already been decided.
+func (lu *loginThrottleUsecase) audit(ctx context.Context, event *domain.AuditEvent) {
+	log.Printf("audit %s %s", event.Type, event.Subject)
+	if err := lu.auditRepository.Create(ctx, event); err != nil {
+		log.Println("error recording audit event:", err)
+	}
+}
+
+// loginScopes lists the scopes a login counts against. Requests without a
+// username only count against the address.
+func loginScopes(username string, ip string) [][2]string {
+	scopes := [][2]string{}
+	if username = normalizeLoginUsername(username); username != "" {
+		scopes = append(scopes, [2]string{domain.LoginScopeUsername, username})
+	}
+	if ip != "" {
+		scopes = append(scopes, [2]string{domain.LoginScopeIP, ip})
+	}
+	return scopes
+}
+
+// normalizeLoginUsername makes "Alice" and "alice " count as the same
+// username.
+func normalizeLoginUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func NewLoginThrottleUsecase(store domain.LoginAttemptStore, auditRepository domain.AuditRepository, policy domain.LoginPolicy, contextTimeout time.Duration) domain.LoginThrottleUsecase {
+	return &loginThrottleUsecase{
+		store:           store,
+		auditRepository: auditRepository,
+		policy:          policy,
+		contextTimeout:  contextTimeout,
+	}
+}
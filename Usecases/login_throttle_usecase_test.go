@@ -0,0 +1,238 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+)
+
+type fakeAuditRepository struct {
+	domain.AuditRepository
+	mu     sync.Mutex
+	events []*domain.AuditEvent
+}
+
+func (r *fakeAuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func testLoginPolicy() domain.LoginPolicy {
+	return domain.LoginPolicy{
+		Username:  domain.LoginLimit{FreeFailures: 3, MaxFailures: 10},
+		IP:        domain.LoginLimit{FreeFailures: 10, MaxFailures: 100},
+		BaseDelay: time.Second,
+		MaxDelay:  30 * time.Second,
+		Lockout:   15 * time.Minute,
+		Window:    15 * time.Minute,
+	}
+}
+
+// burst sends n concurrent wrong passwords and returns how many of them got
+// past Check.
+func burst(t *testing.T, throttle domain.LoginThrottleUsecase, n int) int {
+	t.Helper()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	passed := 0
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			block, err := throttle.Check(context.Background(), "alice", "10.0.0.1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if block != nil {
+				return
+			}
+			mu.Lock()
+			passed++
+			mu.Unlock()
+			if _, err := throttle.Fail(context.Background(), "alice", "10.0.0.1"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	return passed
+}
+
+func TestLoginThrottleBurstStaysWithinFreeFailures(t *testing.T) {
+	store := Intrastructures.NewMemoryLoginAttempts()
+	throttle := NewLoginThrottleUsecase(store, &fakeAuditRepository{}, testLoginPolicy(), 5*time.Second)
+
+	// the fourth failure is the first that makes the next attempt wait
+	if passed := burst(t, throttle, 50); passed != 4 {
+		t.Errorf("%d attempts got through, want 4", passed)
+	}
+	attempt, _ := store.Fetch(context.Background(), domain.LoginScopeUsername, "alice")
+	if attempt == nil || attempt.Failures != 4 {
+		t.Fatalf("attempt = %+v, want 4 failures", attempt)
+	}
+	if !attempt.RetryAt.After(time.Now()) {
+		t.Error("the username does not have to wait after its fourth failure")
+	}
+}
+
+func TestLoginThrottleBurstCannotPassMaxFailures(t *testing.T) {
+	store := Intrastructures.NewMemoryLoginAttempts()
+	audit := &fakeAuditRepository{}
+	throttle := NewLoginThrottleUsecase(store, audit, testLoginPolicy(), 5*time.Second)
+	now := time.Now()
+	for range 9 {
+		store.Fail(context.Background(), domain.LoginScopeUsername, "alice", now, time.Hour)
+	}
+
+	if passed := burst(t, throttle, 50); passed != 1 {
+		t.Errorf("%d attempts got through, want 1", passed)
+	}
+	attempt, _ := store.Fetch(context.Background(), domain.LoginScopeUsername, "alice")
+	if attempt.Failures != 10 || !attempt.Locked(time.Now()) {
+		t.Errorf("attempt = %+v, want locked after 10 failures", attempt)
+	}
+	if len(audit.events) != 1 {
+		t.Errorf("%d lockouts audited, want 1", len(audit.events))
+	}
+}
+
+func TestLoginThrottleSuccessTakesBackTheAttempt(t *testing.T) {
+	store := Intrastructures.NewMemoryLoginAttempts()
+	throttle := NewLoginThrottleUsecase(store, &fakeAuditRepository{}, testLoginPolicy(), 5*time.Second)
+
+	for range 3 {
+		block, err := throttle.Check(context.Background(), "alice", "10.0.0.1")
+		if err != nil || block != nil {
+			t.Fatalf("block = %+v err = %v, want a free attempt", block, err)
+		}
+		if err := throttle.Succeed(context.Background(), "alice", "10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if attempt, _ := store.Fetch(context.Background(), domain.LoginScopeUsername, "alice"); attempt != nil {
+		t.Errorf("username attempt = %+v, want none", attempt)
+	}
+	if attempt, _ := store.Fetch(context.Background(), domain.LoginScopeIP, "10.0.0.1"); attempt != nil && attempt.Failures != 0 {
+		t.Errorf("address failures = %d, want 0", attempt.Failures)
+	}
+}
+
+func TestLoginThrottleFailureWaits(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		ip       string
+		failures int
+		wait     time.Duration
+		locked   bool
+	}{
+		{"first username failure", "alice", "", 1, 0, false},
+		{"last free username failure", "alice", "", 3, 0, false},
+		{"first username wait", "alice", "", 4, time.Second, false},
+		{"username wait doubles", "alice", "", 5, 2 * time.Second, false},
+		{"username wait keeps doubling", "alice", "", 8, 16 * time.Second, false},
+		{"username wait is capped", "alice", "", 9, 30 * time.Second, false},
+		{"username lockout", "alice", "", 10, 15 * time.Minute, true},
+		{"username stays locked out", "alice", "", 12, 15 * time.Minute, true},
+		{"last free address failure", "", "10.0.0.1", 10, 0, false},
+		{"first address wait", "", "10.0.0.1", 11, time.Second, false},
+		{"address lockout", "", "10.0.0.1", 100, 15 * time.Minute, true},
+		{"address still within its free failures", "alice", "10.0.0.1", 4, time.Second, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := Intrastructures.NewMemoryLoginAttempts()
+			audit := &fakeAuditRepository{}
+			throttle := NewLoginThrottleUsecase(store, audit, testLoginPolicy(), 5*time.Second)
+
+			// Check counts every attempt before the password is checked
+			now := time.Now()
+			for _, scope := range loginScopes(test.username, test.ip) {
+				for range test.failures {
+					store.Fail(context.Background(), scope[0], scope[1], now, time.Hour)
+				}
+			}
+			block, err := throttle.Fail(context.Background(), test.username, test.ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.wait == 0 {
+				if block != nil {
+					t.Errorf("block = %+v, want none", block)
+				}
+				return
+			}
+			if block == nil || block.RetryAfter != test.wait || block.Locked != test.locked {
+				t.Fatalf("block = %+v, want a wait of %s, locked %v", block, test.wait, test.locked)
+			}
+			if want := map[bool]int{true: 1, false: 0}[test.locked]; len(audit.events) != want {
+				t.Errorf("%d lockouts audited, want %d", len(audit.events), want)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleLockout(t *testing.T) {
+	tests := []struct {
+		name     string
+		unlock   func(throttle domain.LoginThrottleUsecase) error
+		username string
+		ip       string
+		locked   bool
+	}{
+		{"same address", nil, "alice", "10.0.0.1", true},
+		{"another address", nil, "alice", "10.0.0.2", true},
+		{"case of the username", nil, "ALICE", "10.0.0.2", true},
+		{"another username", nil, "bob", "10.0.0.1", false},
+		{"unlocked by an admin", func(throttle domain.LoginThrottleUsecase) error {
+			return throttle.Unlock(context.Background(), domain.LoginScopeUsername, "Alice", "admin", "10.0.0.9")
+		}, "alice", "10.0.0.1", false},
+		{"unlocking the address leaves the username", func(throttle domain.LoginThrottleUsecase) error {
+			return throttle.Unlock(context.Background(), domain.LoginScopeIP, "10.0.0.1", "admin", "10.0.0.9")
+		}, "alice", "10.0.0.1", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := Intrastructures.NewMemoryLoginAttempts()
+			throttle := NewLoginThrottleUsecase(store, &fakeAuditRepository{}, testLoginPolicy(), 5*time.Second)
+			now := time.Now()
+			for range 9 {
+				store.Fail(context.Background(), domain.LoginScopeUsername, "alice", now, time.Hour)
+			}
+			// the tenth wrong password locks the username out
+			if passed := burst(t, throttle, 1); passed != 1 {
+				t.Fatalf("%d attempts got through, want 1", passed)
+			}
+
+			if test.unlock != nil {
+				if err := test.unlock(throttle); err != nil {
+					t.Fatal(err)
+				}
+			}
+			block, err := throttle.Check(context.Background(), test.username, test.ip)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.locked {
+				if block != nil {
+					t.Errorf("block = %+v, want none", block)
+				}
+				return
+			}
+			if block == nil || !block.Locked || block.Scope != domain.LoginScopeUsername || block.RetryAfter > 15*time.Minute || block.RetryAfter < 14*time.Minute {
+				t.Errorf("block = %+v, want the username locked out for 15 minutes", block)
+			}
+			// a refused attempt is no failure
+			if attempt, _ := store.Fetch(context.Background(), domain.LoginScopeUsername, "alice"); attempt.Failures != 10 {
+				t.Errorf("failures = %d, want 10", attempt.Failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const dueReminderWindow = 24 * time.Hour
+
+// mailUsecase sends the scheduled emails. Each email is recorded in the email
+// log before it goes out, so it is sent at most once even when several
+// instances run the schedule.
+type mailUsecase struct {
+	mailer               domain.MailSender
+	taskRepository       domain.TaskRepository
+	userRepository       domain.UserRepository
+	preferenceRepository domain.NotificationPreferenceRepository
+	emailLogRepository   domain.EmailLogRepository
+	calendarRepository   domain.CalendarRepository
+	digestHour           int
+	contextTimeout       time.Duration
+}
+
+// SendDueReminders implements domains.MailUsecase. Assignees of open tasks
+// due within the next day are reminded once per task and due date.
+func (mu *mailUsecase) SendDueReminders(ctx context.Context, now time.Time) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	defer cancel()
+
+	dueTo := now.Add(dueReminderWindow)
+	tasks, err := mu.taskRepository.FetchMatching(c, &domain.TaskFilter{DueFrom: &now, DueTo: &dueTo})
+	if err != nil {
+		log.Println("error loading tasks due soon:", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if task.Status == domain.TaskStatusDone || task.AssigneeID == "" {
+			continue
+		}
+		taskCtx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+		recipient, ok := emailRecipient(taskCtx, mu.userRepository, mu.preferenceRepository, task.AssigneeID, domain.EmailDueReminder)
+		if ok {
+			message, err := renderEmail(domain.EmailDueReminder, recipient.Email, fmt.Sprintf("Due soon: %s", task.Title), &emailData{
+				Zone:      userLocation(taskCtx, mu.calendarRepository, recipient.UserID),
+				Recipient: recipient.Username,
+				Task:      task,
+			})
+			if err != nil {
+				log.Println("error rendering due reminder:", err)
+			} else {
+				key := task.TaskID + "@" + task.DueDate.UTC().Format(time.RFC3339)
+				mu.sendOnce(taskCtx, domain.EmailDueReminder, recipient.UserID, key, message)
+			}
+		}
+		cancel()
+	}
+}
+
+// SendDailyDigests implements domains.MailUsecase. Digests go out once a day
+// from the configured hour in each user's time zone on, and only to users
+// with something due.
+func (mu *mailUsecase) SendDailyDigests(ctx context.Context, now time.Time) {
+	c, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+	users, err := mu.userRepository.FetchAll(c)
+	if err != nil {
+		cancel()
+		log.Println("error loading users for digests:", err)
+		return
+	}
+	userIDs := make([]string, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+	}
+	locations, err := userLocations(c, mu.calendarRepository, userIDs)
+	cancel()
+	if err != nil {
+		log.Println("error loading time zones for digests:", err)
+		return
+	}
+
+	for _, user := range users {
+		local := now.In(locations[user.UserID])
+		if user.Email == "" || local.Hour() < mu.digestHour {
+			continue
+		}
+		userCtx, cancel := context.WithTimeout(ctx, mu.contextTimeout)
+		recipient, ok := emailRecipient(userCtx, mu.userRepository, mu.preferenceRepository, user.UserID, domain.EmailDigest)
+		if ok {
+			data, err := mu.digest(userCtx, recipient, local)
+			if err != nil {
+				log.Println("error building digest:", err)
+			} else if len(data.Overdue)+len(data.DueToday) > 0 {
+				message, err := renderDigest(recipient, data)
+				if err != nil {
+					log.Println("error rendering digest:", err)
+				} else {
+					mu.sendOnce(userCtx, domain.EmailDigest, recipient.UserID, local.Format(time.DateOnly), message)
+				}
+			}
+		}
+		cancel()
+	}
+}
+
+// PreviewDigest implements domains.MailUsecase. It renders today's digest
+// without sending it or looking at the opt-outs.
+func (mu *mailUsecase) PreviewDigest(ctx context.Context, userID string, now time.Time) (*domain.MailMessage, error) {
+	c, cancel := context.WithTimeout(context.Background(), mu.contextTimeout)
+	defer cancel()
+
+	user, err := mu.userRepository.FetchById(c, userID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("no user found with id '%s'", userID)
+	}
+	data, err := mu.digest(c, user, now.In(userLocation(c, mu.calendarRepository, userID)))
+	if err != nil {
+		return nil, err
+	}
+	return renderDigest(user, data)
+}
+
+// digest collects the user's open tasks that are overdue or due before the
+// end of the day. now is in the user's time zone.
+func (mu *mailUsecase) digest(ctx context.Context, user *domain.User, now time.Time) (*emailData, error) {
+	// tasks without a due date hold the zero time, which the lower bound skips
+	noDueDate := time.Unix(0, 0)
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	tasks, err := mu.taskRepository.FetchMatching(ctx, &domain.TaskFilter{AssigneeID: user.UserID, DueFrom: &noDueDate, DueTo: &endOfDay})
+	if err != nil {
+		return nil, err
+	}
+
+	data := &emailData{Zone: now.Location(), Recipient: user.Username, Date: now}
+	for _, task := range tasks {
+		switch {
+		case task.Status == domain.TaskStatusDone || !task.DueDate.Before(endOfDay):
+		case task.DueDate.Before(now):
+			data.Overdue = append(data.Overdue, task)
+		default:
+			data.DueToday = append(data.DueToday, task)
+		}
+	}
+	return data, nil
+}
+
+func (mu *mailUsecase) sendOnce(ctx context.Context, kind string, userID string, key string, message *domain.MailMessage) {
+	first, err := mu.emailLogRepository.MarkSent(ctx, kind, userID, key)
+	if err != nil {
+		log.Println("error recording email:", err)
+		return
+	}
+	if !first {
+		return
+	}
+	if err := mu.mailer.Send(message); err != nil {
+		log.Println("error sending email:", err)
+	}
+}
+
+func renderDigest(user *domain.User, data *emailData) (*domain.MailMessage, error) {
+	subject := fmt.Sprintf("Your tasks for %s: %d overdue, %d due today", data.Date.Format("Mon, 02 Jan"), len(data.Overdue), len(data.DueToday))
+	return renderEmail(domain.EmailDigest, user.Email, subject, data)
+}
+
+func NewMailUsecase(mailer domain.MailSender, taskRepository domain.TaskRepository, userRepository domain.UserRepository, preferenceRepository domain.NotificationPreferenceRepository, emailLogRepository domain.EmailLogRepository, calendarRepository domain.CalendarRepository, digestHour int, contextTimeout time.Duration) domain.MailUsecase {
+	return &mailUsecase{
+		mailer:               mailer,
+		taskRepository:       taskRepository,
+		userRepository:       userRepository,
+		preferenceRepository: preferenceRepository,
+		emailLogRepository:   emailLogRepository,
+		calendarRepository:   calendarRepository,
+		digestHour:           digestHour,
+		contextTimeout:       contextTimeout,
+	}
+}
@@ -0,0 +1,213 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxMentions = 20
+
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([A-Za-z0-9_.-]+)`)
+
+// notificationDispatcher turns task and comment events into inbox
+// notifications for the task's watchers. Creators and assignees start
+// watching automatically unless they opted out.
+type notificationDispatcher struct {
+	notificationRepository domain.NotificationRepository
+	preferenceRepository   domain.NotificationPreferenceRepository
+	taskRepository         domain.TaskRepository
+	userRepository         domain.UserRepository
+}
+
+// HandleTaskEvent implements domains.TaskEventHandler.
+func (d *notificationDispatcher) HandleTaskEvent(ctx context.Context, event *domain.TaskEvent) {
+	task := event.Task
+	var notifications []*domain.Notification
+	skip := map[string]bool{event.ActorID: true}
+
+	switch event.Type {
+	case domain.TaskCreated:
+		d.autoWatch(ctx, task.TaskID, task.CreatedBy, task.AssigneeID)
+		if task.AssigneeID != "" && task.AssigneeID != event.ActorID {
+			notifications = append(notifications, newNotification(task.AssigneeID, domain.NotificationTaskAssigned, event,
+				fmt.Sprintf("You were assigned '%s'", task.Title)))
+		}
+	case domain.TaskUpdated:
+		if event.Previous != nil && task.AssigneeID != "" && task.AssigneeID != event.Previous.AssigneeID {
+			d.autoWatch(ctx, task.TaskID, task.AssigneeID)
+			if task.AssigneeID != event.ActorID {
+				notifications = append(notifications, newNotification(task.AssigneeID, domain.NotificationTaskAssigned, event,
+					fmt.Sprintf("You were assigned '%s'", task.Title)))
+			}
+			skip[task.AssigneeID] = true
+		}
+		// a status change is announced by the event that follows
+		if event.Previous != nil && event.Previous.Status != task.Status {
+			break
+		}
+		notifications = append(notifications, notifyWatchers(task.Watchers, skip, domain.NotificationTaskUpdated, event,
+			fmt.Sprintf("'%s' was updated", task.Title))...)
+	case domain.TaskStatusChanged:
+		notifications = append(notifications, notifyWatchers(task.Watchers, skip, domain.NotificationTaskStatusChanged, event,
+			fmt.Sprintf("'%s' moved from %s to %s", task.Title, event.Previous.Status, task.Status))...)
+	case domain.TaskDeleted:
+		notifications = append(notifications, notifyWatchers(task.Watchers, skip, domain.NotificationTaskDeleted, event,
+			fmt.Sprintf("'%s' was deleted", task.Title))...)
+	}
+
+	d.deliver(ctx, notifications)
+}
+
+// HandleCommentEvent implements domains.CommentEventHandler. Mentioned users
+// are told even when they do not watch the task.
+func (d *notificationDispatcher) HandleCommentEvent(ctx context.Context, event *domain.CommentEvent) {
+	comment := event.Comment
+	task, err := d.taskRepository.FetchById(ctx, comment.TaskID)
+	if err != nil {
+		log.Println("error loading commented task:", err)
+		return
+	}
+
+	taskEvent := &domain.TaskEvent{Task: task, ActorID: comment.AuthorID, OccurredAt: event.OccurredAt}
+	skip := map[string]bool{comment.AuthorID: true}
+	var notifications []*domain.Notification
+	for _, userID := range mentionedUsers(ctx, d.userRepository, comment.Body) {
+		if skip[userID] {
+			continue
+		}
+		skip[userID] = true
+		notification := newNotification(userID, domain.NotificationMentioned, taskEvent,
+			fmt.Sprintf("You were mentioned on '%s'", task.Title))
+		notification.CommentID = comment.CommentID
+		notifications = append(notifications, notification)
+	}
+	for _, notification := range notifyWatchers(task.Watchers, skip, domain.NotificationCommentAdded, taskEvent,
+		fmt.Sprintf("New comment on '%s'", task.Title)) {
+		notification.CommentID = comment.CommentID
+		notifications = append(notifications, notification)
+	}
+
+	d.deliver(ctx, notifications)
+}
+
+// mentionedUsers returns the ids of the users @mentioned in text.
+func mentionedUsers(ctx context.Context, userRepository domain.UserRepository, text string) []string {
+	seen := map[string]bool{}
+	var userIDs []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := match[1]
+		if seen[username] || len(seen) >= maxMentions {
+			continue
+		}
+		seen[username] = true
+		user, err := userRepository.GetUserByUsername(ctx, username)
+		if err != nil || user == nil {
+			continue
+		}
+		userIDs = append(userIDs, user.UserID)
+	}
+	return userIDs
+}
+
+func (d *notificationDispatcher) autoWatch(ctx context.Context, taskID string, userIDs ...string) {
+	candidates := []string{}
+	for _, userID := range userIDs {
+		if userID != "" {
+			candidates = append(candidates, userID)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	preferences, err := d.preferenceRepository.FetchMany(ctx, candidates)
+	if err != nil {
+		log.Println("error loading notification preferences:", err)
+		return
+	}
+
+	watchers := []string{}
+	for _, userID := range candidates {
+		if p, ok := preferences[userID]; ok && p.DisableAutoWatch {
+			continue
+		}
+		watchers = append(watchers, userID)
+	}
+	if len(watchers) == 0 {
+		return
+	}
+	if err := d.taskRepository.AddWatchers(ctx, taskID, watchers); err != nil {
+		log.Println("error adding task watchers:", err)
+	}
+}
+
+// deliver stores the notifications their recipients have not switched off.
+func (d *notificationDispatcher) deliver(ctx context.Context, notifications []*domain.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	recipients := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		recipients = append(recipients, notification.UserID)
+	}
+	preferences, err := d.preferenceRepository.FetchMany(ctx, recipients)
+	if err != nil {
+		log.Println("error loading notification preferences:", err)
+		return
+	}
+
+	wanted := make([]*domain.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		if p, ok := preferences[notification.UserID]; ok && slices.Contains(p.Disabled, notification.Type) {
+			continue
+		}
+		wanted = append(wanted, notification)
+	}
+	if err := d.notificationRepository.CreateMany(ctx, wanted); err != nil {
+		log.Println("error storing notifications:", err)
+	}
+}
+
+func notifyWatchers(watchers []string, skip map[string]bool, notificationType string, event *domain.TaskEvent, message string) []*domain.Notification {
+	var notifications []*domain.Notification
+	for _, userID := range watchers {
+		if skip[userID] {
+			continue
+		}
+		notifications = append(notifications, newNotification(userID, notificationType, event, message))
+	}
+	return notifications
+}
+
+func newNotification(userID string, notificationType string, event *domain.TaskEvent, message string) *domain.Notification {
+	id := primitive.NewObjectID()
+	createdAt := event.OccurredAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &domain.Notification{
+		ID:             id,
+		NotificationID: id.Hex(),
+		UserID:         userID,
+		Type:           notificationType,
+		TaskID:         event.Task.TaskID,
+		ActorID:        event.ActorID,
+		Message:        message,
+		CreatedAt:      createdAt,
+	}
+}
+
+func NewNotificationDispatcher(notificationRepository domain.NotificationRepository, preferenceRepository domain.NotificationPreferenceRepository, taskRepository domain.TaskRepository, userRepository domain.UserRepository) domain.NotificationDispatcher {
+	return &notificationDispatcher{
+		notificationRepository: notificationRepository,
+		preferenceRepository:   preferenceRepository,
+		taskRepository:         taskRepository,
+		userRepository:         userRepository,
+	}
+}
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type notificationUsecase struct {
+	notificationRepository domain.NotificationRepository
+	preferenceRepository   domain.NotificationPreferenceRepository
+	taskRepository         domain.TaskRepository
+	contextTimeout         time.Duration
+}
+
+// FetchPage implements domains.NotificationUsecase.
+func (n *notificationUsecase) FetchPage(ctx context.Context, userID string, unreadOnly bool, page int64, limit int64) (*domain.NotificationPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultTaskPageLimit
+	}
+	if limit > maxTaskPageLimit {
+		limit = maxTaskPageLimit
+	}
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.notificationRepository.FetchPage(c, userID, unreadOnly, page, limit)
+}
+
+// CountUnread implements domains.NotificationUsecase.
+func (n *notificationUsecase) CountUnread(ctx context.Context, userID string) (int64, error) {
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.notificationRepository.CountUnread(c, userID)
+}
+
+// MarkRead implements domains.NotificationUsecase.
+func (n *notificationUsecase) MarkRead(ctx context.Context, userID string, notificationID string) error {
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.notificationRepository.MarkRead(c, userID, notificationID)
+}
+
+// MarkAllRead implements domains.NotificationUsecase.
+func (n *notificationUsecase) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.notificationRepository.MarkAllRead(c, userID)
+}
+
+// FetchPreferences implements domains.NotificationUsecase.
+func (n *notificationUsecase) FetchPreferences(ctx context.Context, userID string) (*domain.NotificationPreferences, error) {
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.preferenceRepository.Fetch(c, userID)
+}
+
+// UpdatePreferences implements domains.NotificationUsecase.
+func (n *notificationUsecase) UpdatePreferences(ctx context.Context, preferences *domain.NotificationPreferences) error {
+	disabled := []string{}
+	seen := map[string]bool{}
+	for _, notificationType := range preferences.Disabled {
+		if !domain.NotificationTypes[notificationType] {
+			return fmt.Errorf("'%s' is not a notification type", notificationType)
+		}
+		if !seen[notificationType] {
+			seen[notificationType] = true
+			disabled = append(disabled, notificationType)
+		}
+	}
+	preferences.Disabled = disabled
+
+	emailDisabled := []string{}
+	seen = map[string]bool{}
+	for _, emailType := range preferences.EmailDisabled {
+		if !domain.EmailTypes[emailType] {
+			return fmt.Errorf("'%s' is not an email type", emailType)
+		}
+		if !seen[emailType] {
+			seen[emailType] = true
+			emailDisabled = append(emailDisabled, emailType)
+		}
+	}
+	preferences.EmailDisabled = emailDisabled
+
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.preferenceRepository.Upsert(c, preferences)
+}
+
+// Watch implements domains.NotificationUsecase.
+func (n *notificationUsecase) Watch(ctx context.Context, taskID string, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.taskRepository.AddWatchers(c, taskID, []string{userID})
+}
+
+// Unwatch implements domains.NotificationUsecase.
+func (n *notificationUsecase) Unwatch(ctx context.Context, taskID string, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), n.contextTimeout)
+	defer cancel()
+	return n.taskRepository.RemoveWatcher(c, taskID, userID)
+}
+
+func NewNotificationUsecase(notificationRepository domain.NotificationRepository, preferenceRepository domain.NotificationPreferenceRepository, taskRepository domain.TaskRepository, contextTimeout time.Duration) domain.NotificationUsecase {
+	return &notificationUsecase{
+		notificationRepository: notificationRepository,
+		preferenceRepository:   preferenceRepository,
+		taskRepository:         taskRepository,
+		contextTimeout:         contextTimeout,
+	}
+}
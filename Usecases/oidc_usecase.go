@@ -0,0 +1,241 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// invalidUsernameCharacters are replaced when a provider username becomes a
+// local one; local usernames are letters, digits and underscores.
+var invalidUsernameCharacters = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+type oidcUsecase struct {
+	providers                  map[string]domain.OIDCProvider
+	names                      []string
+	stateRepository            domain.OIDCStateRepository
+	externalIdentityRepository domain.ExternalIdentityRepository
+	userRepository             domain.UserRepository
+	revocations                domain.TokenRevocationStore
+	contextTimeout             time.Duration
+}
+
+// Providers implements domains.OIDCUsecase.
+func (ou *oidcUsecase) Providers() []string {
+	return ou.names
+}
+
+// Begin implements domains.OIDCUsecase. The state ties the callback to this
+// login, the nonce ties the ID token to it and the PKCE verifier makes a
+// stolen code useless.
+func (ou *oidcUsecase) Begin(ctx context.Context, provider string) (string, error) {
+	oidcProvider, ok := ou.providers[provider]
+	if !ok {
+		return "", fmt.Errorf("no identity provider named '%s'", provider)
+	}
+	c, cancel := context.WithTimeout(context.Background(), ou.contextTimeout)
+	defer cancel()
+
+	state, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	err = ou.stateRepository.Create(c, &domain.OIDCLoginState{
+		State:        state,
+		Provider:     provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(domain.OIDCStateLifetime),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return oidcProvider.AuthorizationURL(c, state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+}
+
+// Complete implements domains.OIDCUsecase.
+func (ou *oidcUsecase) Complete(ctx context.Context, provider string, state string, code string) (*domain.User, error) {
+	oidcProvider, ok := ou.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("no identity provider named '%s'", provider)
+	}
+	c, cancel := context.WithTimeout(context.Background(), ou.contextTimeout)
+	defer cancel()
+
+	login, err := ou.stateRepository.Consume(c, state)
+	if err != nil {
+		return nil, err
+	}
+	if login.Provider != provider || !login.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("login state is unknown or was already used")
+	}
+	identity, err := oidcProvider.Exchange(c, code, login.CodeVerifier, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	role, ok := oidcProvider.Config().Role(identity.Groups)
+	if !ok {
+		return nil, fmt.Errorf("none of your groups at '%s' may use this app", provider)
+	}
+
+	linked, err := ou.externalIdentityRepository.FetchBySubject(c, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked == nil {
+		return ou.provision(c, provider, identity, role)
+	}
+
+	user, err := ou.userRepository.FetchById(c, linked.UserID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("no user found with id '%s'", linked.UserID)
+	}
+	// the provider's groups decide the role, also after it changed there.
+	// Tokens and personal API keys carry the role, so the old ones stop
+	// working.
+	if user.UserType != role {
+		if err := ou.userRepository.UpdateUserType(c, user.UserID, role); err != nil {
+			return nil, err
+		}
+		if _, err := ou.revocations.BumpGeneration(c, user.UserID); err != nil {
+			return nil, err
+		}
+		user.UserType = role
+	}
+	if err := ou.externalIdentityRepository.Touch(c, identity.Issuer, identity.Subject, identity.Email, time.Now()); err != nil {
+		log.Println("error recording identity login:", err)
+	}
+	return user, nil
+}
+
+// provision creates the user for an account's first login. The user has no
+// password, so it can only log in through the provider.
+func (ou *oidcUsecase) provision(ctx context.Context, provider string, identity *domain.OIDCIdentity, role string) (*domain.User, error) {
+	username, err := ou.freeUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	firstName, lastName := identity.GivenName, identity.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(identity.Name, " ")
+	}
+	if firstName == "" {
+		firstName = username
+	}
+
+	now := time.Now()
+	id := primitive.NewObjectID()
+	user := &domain.User{
+		ID:        id,
+		UserID:    id.Hex(),
+		FirstName: firstName,
+		LastName:  lastName,
+		Username:  username,
+		UserType:  role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// an unverified address could belong to someone else
+	if identity.EmailVerified {
+		user.Email = identity.Email
+		user.EmailVerified = true
+	}
+	if err := ou.userRepository.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	err = ou.externalIdentityRepository.Create(ctx, &domain.ExternalIdentity{
+		Provider:    provider,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		UserID:      user.UserID,
+		Email:       identity.Email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("created user %s for %s account %s", user.UserID, provider, identity.Subject)
+	return user, nil
+}
+
+// freeUsername derives a valid local username from the provider's and adds
+// a number when it is taken.
+func (ou *oidcUsecase) freeUsername(ctx context.Context, identity *domain.OIDCIdentity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = invalidUsernameCharacters.ReplaceAllString(base, "_")
+	if base == "" || !(base[0] >= 'A' && base[0] <= 'Z' || base[0] >= 'a' && base[0] <= 'z') {
+		base = "user_" + base
+	}
+	for len(base) < 5 {
+		base += "_"
+	}
+	// leave room for the number
+	if len(base) > 20 {
+		base = base[:20]
+	}
+
+	for attempt := 1; attempt <= 50; attempt++ {
+		candidate := base
+		if attempt > 1 {
+			candidate = base + strconv.Itoa(attempt)
+		}
+		existing, err := ou.userRepository.GetUserByUsername(ctx, candidate)
+		if err != nil || existing == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("could not find a free username for '%s'", base)
+}
+
+// randomToken is a URL-safe random string, also a valid PKCE verifier.
+func randomToken() (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+func NewOIDCUsecase(providers []domain.OIDCProvider, stateRepository domain.OIDCStateRepository, externalIdentityRepository domain.ExternalIdentityRepository, userRepository domain.UserRepository, revocations domain.TokenRevocationStore, contextTimeout time.Duration) domain.OIDCUsecase {
+	usecase := &oidcUsecase{
+		providers:                  map[string]domain.OIDCProvider{},
+		names:                      []string{},
+		stateRepository:            stateRepository,
+		externalIdentityRepository: externalIdentityRepository,
+		userRepository:             userRepository,
+		revocations:                revocations,
+		contextTimeout:             contextTimeout,
+	}
+	for _, provider := range providers {
+		name := provider.Config().Name
+		usecase.providers[name] = provider
+		usecase.names = append(usecase.names, name)
+	}
+	return usecase
+}
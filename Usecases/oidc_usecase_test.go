@@ -0,0 +1,335 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+)
+
+// fakeOIDCProvider answers every code with identity, as long as the login
+// passes the nonce and verifier it was begun with.
+type fakeOIDCProvider struct {
+	config   *domain.OIDCProviderConfig
+	identity *domain.OIDCIdentity
+	nonce    string
+	verifier string
+}
+
+func (p *fakeOIDCProvider) Config() *domain.OIDCProviderConfig {
+	return p.config
+}
+
+func (p *fakeOIDCProvider) AuthorizationURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	p.nonce = nonce
+	return "https://idp.example.com/authorize?" + url.Values{"state": {state}, "code_challenge": {codeChallenge}}.Encode(), nil
+}
+
+func (p *fakeOIDCProvider) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*domain.OIDCIdentity, error) {
+	if nonce != p.nonce {
+		return nil, fmt.Errorf("wrong nonce")
+	}
+	p.verifier = codeVerifier
+	identity := *p.identity
+	return &identity, nil
+}
+
+type fakeOIDCStateRepository struct {
+	states map[string]*domain.OIDCLoginState
+}
+
+func (r *fakeOIDCStateRepository) Create(ctx context.Context, state *domain.OIDCLoginState) error {
+	r.states[state.State] = state
+	return nil
+}
+
+func (r *fakeOIDCStateRepository) Consume(ctx context.Context, state string) (*domain.OIDCLoginState, error) {
+	login, ok := r.states[state]
+	if !ok {
+		return nil, fmt.Errorf("login state is unknown or was already used")
+	}
+	delete(r.states, state)
+	return login, nil
+}
+
+type fakeExternalIdentityRepository struct {
+	identities []*domain.ExternalIdentity
+}
+
+func (r *fakeExternalIdentityRepository) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeExternalIdentityRepository) FetchBySubject(ctx context.Context, issuer string, subject string) (*domain.ExternalIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Issuer == issuer && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeExternalIdentityRepository) Touch(ctx context.Context, issuer string, subject string, email string, loginAt time.Time) error {
+	return nil
+}
+
+// fakeUserRepository only implements what the OIDC login uses.
+type fakeUserRepository struct {
+	domain.UserRepository
+	users map[string]*domain.User
+}
+
+func (r *fakeUserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.users[user.UserID] = user
+	return nil
+}
+
+func (r *fakeUserRepository) FetchById(ctx context.Context, userID string) (*domain.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("no user found with id '%s'", userID)
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("no user named '%s'", username)
+}
+
+func (r *fakeUserRepository) UpdateUserType(ctx context.Context, userID string, userType string) error {
+	r.users[userID].UserType = userType
+	return nil
+}
+
+type oidcFixture struct {
+	usecase     domain.OIDCUsecase
+	provider    *fakeOIDCProvider
+	users       *fakeUserRepository
+	identities  *fakeExternalIdentityRepository
+	revocations domain.TokenRevocationStore
+}
+
+func newOIDCFixture(defaultRole string) *oidcFixture {
+	provider := &fakeOIDCProvider{
+		config: &domain.OIDCProviderConfig{
+			Name:        "corp",
+			Issuer:      "https://idp.example.com",
+			GroupRoles:  map[string]string{"task-admins": "ADMIN", "staff": "USER"},
+			DefaultRole: defaultRole,
+		},
+		identity: &domain.OIDCIdentity{
+			Issuer:            "https://idp.example.com",
+			Subject:           "subject-1",
+			Email:             "alice@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "alice.l",
+			GivenName:         "Alice",
+			FamilyName:        "Liddell",
+		},
+	}
+	users := &fakeUserRepository{users: map[string]*domain.User{}}
+	identities := &fakeExternalIdentityRepository{}
+	states := &fakeOIDCStateRepository{states: map[string]*domain.OIDCLoginState{}}
+	revocations := Intrastructures.NewMemoryTokenRevocation()
+	return &oidcFixture{
+		usecase:     NewOIDCUsecase([]domain.OIDCProvider{provider}, states, identities, users, revocations, 5*time.Second),
+		provider:    provider,
+		users:       users,
+		identities:  identities,
+		revocations: revocations,
+	}
+}
+
+// login runs a whole login and returns the user it ends with.
+func (f *oidcFixture) login(t *testing.T) (*domain.User, error) {
+	t.Helper()
+	authorizationURL, err := f.usecase.Begin(context.Background(), "corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.usecase.Complete(context.Background(), "corp", parsed.Query().Get("state"), "the-code")
+}
+
+func TestOIDCUsecaseProvisionsUser(t *testing.T) {
+	fixture := newOIDCFixture("USER")
+	fixture.provider.identity.Groups = []string{"staff"}
+
+	user, err := fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice_l" || user.FirstName != "Alice" || user.LastName != "Liddell" {
+		t.Errorf("user = %s %s %s, want alice_l Alice Liddell", user.Username, user.FirstName, user.LastName)
+	}
+	if user.Password != "" {
+		t.Error("a provisioned user must not have a password")
+	}
+	if user.Email != "alice@example.com" || !user.EmailVerified {
+		t.Errorf("email = %q verified %v, want the verified provider email", user.Email, user.EmailVerified)
+	}
+	if user.UserType != "USER" {
+		t.Errorf("user type = %s, want USER", user.UserType)
+	}
+	if len(fixture.identities.identities) != 1 || fixture.identities.identities[0].UserID != user.UserID {
+		t.Fatalf("identities = %+v, want one linked to %s", fixture.identities.identities, user.UserID)
+	}
+
+	// the second login finds the same user
+	again, err := fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.UserID != user.UserID || len(fixture.users.users) != 1 {
+		t.Errorf("second login created another user")
+	}
+}
+
+func TestOIDCUsecaseSkipsUnverifiedEmail(t *testing.T) {
+	fixture := newOIDCFixture("USER")
+	fixture.provider.identity.EmailVerified = false
+
+	user, err := fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "" || user.EmailVerified {
+		t.Errorf("email = %q verified %v, want none", user.Email, user.EmailVerified)
+	}
+}
+
+func TestOIDCUsecaseMakesUsernamesUnique(t *testing.T) {
+	fixture := newOIDCFixture("USER")
+	fixture.users.users["existing"] = &domain.User{UserID: "existing", Username: "alice_l"}
+
+	user, err := fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "alice_l2" {
+		t.Errorf("username = %s, want alice_l2", user.Username)
+	}
+}
+
+func TestOIDCUsecaseUsesPKCE(t *testing.T) {
+	fixture := newOIDCFixture("USER")
+	authorizationURL, err := fixture.usecase.Begin(context.Background(), "corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(authorizationURL)
+	if _, err := fixture.usecase.Complete(context.Background(), "corp", parsed.Query().Get("state"), "the-code"); err != nil {
+		t.Fatal(err)
+	}
+	if fixture.provider.verifier == "" || strings.Contains(authorizationURL, fixture.provider.verifier) {
+		t.Error("the verifier must reach the code exchange but never the browser")
+	}
+
+	// the state works once
+	if _, err := fixture.usecase.Complete(context.Background(), "corp", parsed.Query().Get("state"), "the-code"); err == nil {
+		t.Error("a used state was accepted")
+	}
+}
+
+func TestOIDCUsecaseMapsGroupsToRoles(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultRole string
+		groups      []string
+		want        string
+		refused     bool
+	}{
+		{"admin group", "USER", []string{"staff", "task-admins"}, "ADMIN", false},
+		{"user group", "", []string{"staff"}, "USER", false},
+		{"unmapped group takes the default", "USER", []string{"contractors"}, "USER", false},
+		{"admin default", "ADMIN", nil, "ADMIN", false},
+		{"no default refuses", "", []string{"contractors"}, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fixture := newOIDCFixture(test.defaultRole)
+			fixture.provider.identity.Groups = test.groups
+
+			user, err := fixture.login(t)
+			if test.refused {
+				if err == nil {
+					t.Fatal("login was not refused")
+				}
+				if len(fixture.users.users) != 0 {
+					t.Error("a refused login created a user")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if user.UserType != test.want {
+				t.Errorf("user type = %s, want %s", user.UserType, test.want)
+			}
+		})
+	}
+}
+
+func TestOIDCUsecaseRoleFollowsGroups(t *testing.T) {
+	fixture := newOIDCFixture("USER")
+	fixture.provider.identity.Groups = []string{"task-admins"}
+	user, err := fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserType != "ADMIN" {
+		t.Fatalf("user type = %s, want ADMIN", user.UserType)
+	}
+
+	// leaving the admin group at the provider takes the role away
+	fixture.provider.identity.Groups = []string{"staff"}
+	user, err = fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.UserType != "USER" || fixture.users.users[user.UserID].UserType != "USER" {
+		t.Errorf("user type = %s, want USER", user.UserType)
+	}
+}
+
+func TestOIDCUsecaseDemotionEndsOldTokens(t *testing.T) {
+	fixture := newOIDCFixture("USER")
+	fixture.provider.identity.Groups = []string{"task-admins"}
+	user, err := fixture.login(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, _ := fixture.revocations.Generation(context.Background(), user.UserID)
+
+	// the same role keeps the tokens and keys of the user
+	if _, err := fixture.login(t); err != nil {
+		t.Fatal(err)
+	}
+	if same, _ := fixture.revocations.Generation(context.Background(), user.UserID); same != before {
+		t.Fatalf("generation = %d after a login with the same role, want %d", same, before)
+	}
+
+	// tokens and personal API keys minted as ADMIN must not outlive the role
+	fixture.provider.identity.Groups = []string{"staff"}
+	if _, err := fixture.login(t); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := fixture.revocations.Generation(context.Background(), user.UserID); after <= before {
+		t.Errorf("generation = %d after a demotion, want more than %d", after, before)
+	}
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// emailPasswordResetNotifier emails reset tokens. With a reset URL the email
+// links to it with the token in the token query parameter, otherwise it
+// shows the token itself.
+type emailPasswordResetNotifier struct {
+	mailer   domain.MailSender
+	resetURL string
+}
+
+// NotifyPasswordReset implements domains.PasswordResetNotifier. The email is
+// sent in the background so a slow SMTP relay does not hold up the request.
+func (n *emailPasswordResetNotifier) NotifyPasswordReset(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	if user.Email == "" {
+		return fmt.Errorf("user '%s' has no email address", user.UserID)
+	}
+	data := &emailData{Recipient: user.Username, Date: expiresAt.UTC(), Token: token}
+	if n.resetURL != "" {
+		link, err := url.Parse(n.resetURL)
+		if err != nil {
+			return err
+		}
+		query := link.Query()
+		query.Set("token", token)
+		link.RawQuery = query.Encode()
+		data.Link = link.String()
+	}
+	message, err := renderEmail(domain.EmailPasswordReset, user.Email, "Reset your password", data)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := n.mailer.Send(message); err != nil {
+			log.Println("error sending password reset email:", err)
+		}
+	}()
+	return nil
+}
+
+func NewEmailPasswordResetNotifier(mailer domain.MailSender, resetURL string) domain.PasswordResetNotifier {
+	return &emailPasswordResetNotifier{
+		mailer:   mailer,
+		resetURL: resetURL,
+	}
+}
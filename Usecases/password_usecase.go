@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type passwordUsecase struct {
+	userRepository          domain.UserRepository
+	passwordResetRepository domain.PasswordResetRepository
+	notifier                domain.PasswordResetNotifier
+	tokenUsecase            domain.TokenUsecase
+	password                domain.PasswordServiceProvider
+	contextTimeout          time.Duration
+}
+
+// Change implements domains.PasswordUsecase. Every session ends, so a
+// password that leaked stops working everywhere.
+func (pu *passwordUsecase) Change(ctx context.Context, userID string, request *domain.ChangePasswordRequest) error {
+	if err := validatePassword(request.NewPassword); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), pu.contextTimeout)
+	defer cancel()
+
+	user, err := pu.userRepository.FetchById(c, userID)
+	if err != nil || user == nil {
+		return fmt.Errorf("no user found with id '%s'", userID)
+	}
+	if user.Password == "" {
+		return fmt.Errorf("your account signs in through single sign-on and has no password")
+	}
+	if ok, _ := pu.password.VerifyPassword(user.Password, request.CurrentPassword); !ok {
+		return fmt.Errorf("current password is incorrect")
+	}
+	return pu.setPassword(c, user.UserID, request.NewPassword)
+}
+
+// Forgot implements domains.PasswordUsecase. Unknown usernames, users
+// without a password or a verified email and users who just asked for a
+// token are all answered like everyone else.
+func (pu *passwordUsecase) Forgot(ctx context.Context, username string) error {
+	if username == "" {
+		return fmt.Errorf("username is required")
+	}
+	c, cancel := context.WithTimeout(context.Background(), pu.contextTimeout)
+	defer cancel()
+
+	user, err := pu.userRepository.GetUserByUsername(c, username)
+	// a reset only goes to an address the user proved to own
+	if err != nil || user == nil || user.Password == "" || user.Email == "" || !user.EmailVerified {
+		return nil
+	}
+	now := time.Now()
+	recent, err := pu.passwordResetRepository.CountSince(c, user.UserID, now.Add(-domain.PasswordResetCooldown))
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := randomToken()
+	if err != nil {
+		return err
+	}
+	// only the newest token works
+	if err := pu.passwordResetRepository.RevokeUser(c, user.UserID, now); err != nil {
+		return err
+	}
+	reset := &domain.PasswordReset{
+		TokenHash: hashResetToken(token),
+		UserID:    user.UserID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(domain.PasswordResetLifetime),
+	}
+	if err := pu.passwordResetRepository.Create(c, reset); err != nil {
+		return err
+	}
+	if err := pu.notifier.NotifyPasswordReset(c, user, token, reset.ExpiresAt); err != nil {
+		log.Println("error sending password reset:", err)
+	}
+	return nil
+}
+
+// Reset implements domains.PasswordUsecase. The token is used up even when
+// the new password is rejected later on, which only costs a new token.
+func (pu *passwordUsecase) Reset(ctx context.Context, request *domain.ResetPasswordRequest) error {
+	if err := validatePassword(request.NewPassword); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), pu.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	reset, err := pu.passwordResetRepository.Consume(c, hashResetToken(request.Token), now)
+	if err != nil {
+		return err
+	}
+	if reset == nil {
+		return fmt.Errorf("reset token is invalid, expired or was already used")
+	}
+	if err := pu.setPassword(c, reset.UserID, request.NewPassword); err != nil {
+		return err
+	}
+	return pu.passwordResetRepository.RevokeUser(c, reset.UserID, now)
+}
+
+// setPassword stores the new password and logs the user out everywhere.
+func (pu *passwordUsecase) setPassword(ctx context.Context, userID string, password string) error {
+	hashedPassword := pu.password.HashPassword(password)
+	if hashedPassword == "" {
+		return fmt.Errorf("could not hash the new password")
+	}
+	if err := pu.userRepository.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return err
+	}
+	return pu.tokenUsecase.LogoutAll(ctx, userID)
+}
+
+func validatePassword(password string) error {
+	if len(password) < domain.MinPasswordLength || len(password) > domain.MaxPasswordLength {
+		return fmt.Errorf("new password must be %d to %d characters long", domain.MinPasswordLength, domain.MaxPasswordLength)
+	}
+	return nil
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func NewPasswordUsecase(userRepository domain.UserRepository, passwordResetRepository domain.PasswordResetRepository, notifier domain.PasswordResetNotifier, tokenUsecase domain.TokenUsecase, password domain.PasswordServiceProvider, contextTimeout time.Duration) domain.PasswordUsecase {
+	return &passwordUsecase{
+		userRepository:          userRepository,
+		passwordResetRepository: passwordResetRepository,
+		notifier:                notifier,
+		tokenUsecase:            tokenUsecase,
+		password:                password,
+		contextTimeout:          contextTimeout,
+	}
+}
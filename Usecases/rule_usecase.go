@@ -0,0 +1,256 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const (
+	maxRuleConditions      = 20
+	maxRuleActions         = 10
+	defaultExecutionsLimit = 50
+	maxExecutionsLimit     = 200
+)
+
+type ruleUsecase struct {
+	ruleRepository      domain.RuleRepository
+	executionRepository domain.RuleExecutionRepository
+	taskRepository      domain.TaskRepository
+	webhooks            domain.WebhookSender
+	contextTimeout      time.Duration
+}
+
+// Create implements domains.RuleUsecase.
+func (r *ruleUsecase) Create(ctx context.Context, rule *domain.Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+	if err := r.checkWebhooks(c, rule); err != nil {
+		return err
+	}
+	return r.ruleRepository.Create(c, rule)
+}
+
+// FetchAll implements domains.RuleUsecase.
+func (r *ruleUsecase) FetchAll(ctx context.Context, projectID string) ([]*domain.Rule, error) {
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+	return r.ruleRepository.FetchAll(c, projectID)
+}
+
+// FetchById implements domains.RuleUsecase.
+func (r *ruleUsecase) FetchById(ctx context.Context, ruleID string) (*domain.Rule, error) {
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+	return r.ruleRepository.FetchById(c, ruleID)
+}
+
+// UpdateById implements domains.RuleUsecase.
+func (r *ruleUsecase) UpdateById(ctx context.Context, ruleID string, rule *domain.Rule) error {
+	if err := validateRule(rule); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+	if err := r.checkWebhooks(c, rule); err != nil {
+		return err
+	}
+	return r.ruleRepository.UpdateById(c, ruleID, rule)
+}
+
+// checkWebhooks refuses webhook urls that resolve to private addresses.
+func (r *ruleUsecase) checkWebhooks(ctx context.Context, rule *domain.Rule) error {
+	for _, action := range rule.Actions {
+		if action.Type != domain.ActionWebhook {
+			continue
+		}
+		if err := r.webhooks.CheckURL(ctx, action.URL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteById implements domains.RuleUsecase.
+func (r *ruleUsecase) DeleteById(ctx context.Context, ruleID string) error {
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+	return r.ruleRepository.DeleteById(c, ruleID)
+}
+
+// DryRun implements domains.RuleUsecase. It evaluates the rule against the
+// task as it is stored now and lists what the actions would do, without
+// changing anything. Conditions on previous values see an empty task.
+func (r *ruleUsecase) DryRun(ctx context.Context, ruleID string, taskID string) (*domain.RuleExecution, error) {
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+
+	rule, err := r.ruleRepository.FetchById(c, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	task, err := r.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("no task found with id '%s'", taskID)
+	}
+
+	execution := newRuleExecution(rule, taskID, rule.Trigger, true)
+	execution.Matched = ruleApplies(rule, task) && matchesConditions(rule.Conditions, task, nil)
+	if !execution.Matched {
+		return execution, nil
+	}
+	for _, action := range rule.Actions {
+		execution.Results = append(execution.Results, domain.ActionResult{
+			Type:    action.Type,
+			Status:  domain.ActionPlanned,
+			Message: describeAction(action, task),
+		})
+	}
+	return execution, nil
+}
+
+// Executions implements domains.RuleUsecase.
+func (r *ruleUsecase) Executions(ctx context.Context, ruleID string, limit int64) ([]*domain.RuleExecution, error) {
+	if limit < 1 {
+		limit = defaultExecutionsLimit
+	}
+	if limit > maxExecutionsLimit {
+		limit = maxExecutionsLimit
+	}
+	c, cancel := context.WithTimeout(context.Background(), r.contextTimeout)
+	defer cancel()
+	return r.executionRepository.FetchByRule(c, ruleID, limit)
+}
+
+func describeAction(action domain.RuleAction, task *domain.Task) string {
+	value := fillPlaceholders(action.Value, task)
+	switch action.Type {
+	case domain.ActionSetField:
+		return fmt.Sprintf("set %s to '%s'", action.Field, value)
+	case domain.ActionAssign:
+		return fmt.Sprintf("assign to '%s'", value)
+	case domain.ActionAddLabel:
+		return fmt.Sprintf("add label '%s'", value)
+	case domain.ActionComment:
+		return fmt.Sprintf("comment '%s'", value)
+	default:
+		return fmt.Sprintf("post to %s", action.URL)
+	}
+}
+
+var ruleTriggers = map[string]bool{
+	domain.TaskCreated:       true,
+	domain.TaskUpdated:       true,
+	domain.TaskStatusChanged: true,
+	domain.TaskOverdue:       true,
+}
+
+var settableRuleFields = map[string]bool{
+	"status":      true,
+	"priority":    true,
+	"assignee_id": true,
+}
+
+func validateRule(rule *domain.Rule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || len(rule.Name) > 50 {
+		return fmt.Errorf("rule name must be between 1 and 50 characters")
+	}
+	rule.Trigger = strings.ToUpper(rule.Trigger)
+	if !ruleTriggers[rule.Trigger] {
+		return fmt.Errorf("trigger must be TASK_CREATED, TASK_UPDATED, TASK_STATUS_CHANGED or TASK_OVERDUE")
+	}
+	if len(rule.Conditions) > maxRuleConditions {
+		return fmt.Errorf("a rule can have at most %d conditions", maxRuleConditions)
+	}
+	if len(rule.Actions) == 0 || len(rule.Actions) > maxRuleActions {
+		return fmt.Errorf("a rule needs between 1 and %d actions", maxRuleActions)
+	}
+	if rule.Conditions == nil {
+		rule.Conditions = []domain.RuleCondition{}
+	}
+
+	for _, condition := range rule.Conditions {
+		field := condition.Field
+		if name, ok := strings.CutPrefix(field, "previous."); ok {
+			if rule.Trigger != domain.TaskUpdated && rule.Trigger != domain.TaskStatusChanged {
+				return fmt.Errorf("previous values are only known for update and status change triggers")
+			}
+			field = name
+		}
+		if key, ok := strings.CutPrefix(field, "custom_fields."); ok {
+			if !domain.CustomFieldKeyPattern.MatchString(key) {
+				return fmt.Errorf("'%s' is not a valid custom field key", key)
+			}
+		} else if !ruleFields[field] {
+			return fmt.Errorf("rules cannot look at '%s'", condition.Field)
+		}
+
+		switch condition.Op {
+		case domain.ConditionIn:
+			if len(condition.Values) == 0 {
+				return fmt.Errorf("condition on '%s' needs values", condition.Field)
+			}
+		case domain.ConditionEq, domain.ConditionNeq, domain.ConditionContains, domain.ConditionGt, domain.ConditionLt:
+			if condition.Value == "" {
+				return fmt.Errorf("condition on '%s' needs a value", condition.Field)
+			}
+		case domain.ConditionSet, domain.ConditionUnset:
+		default:
+			return fmt.Errorf("'%s' is not a condition operator", condition.Op)
+		}
+	}
+
+	for i := range rule.Actions {
+		action := &rule.Actions[i]
+		action.Type = strings.ToUpper(action.Type)
+		switch action.Type {
+		case domain.ActionSetField:
+			if !settableRuleFields[action.Field] {
+				return fmt.Errorf("rules can only set status, priority or assignee_id")
+			}
+			if action.Field != "assignee_id" && action.Value == "" {
+				return fmt.Errorf("setting %s needs a value", action.Field)
+			}
+			if action.Field == "priority" && !templatePlaceholder.MatchString(action.Value) {
+				action.Value = strings.ToUpper(action.Value)
+				if _, ok := domain.PriorityRanks[action.Value]; !ok {
+					return fmt.Errorf("'%s' is not a priority", action.Value)
+				}
+			}
+		case domain.ActionAssign, domain.ActionAddLabel:
+			if action.Value == "" {
+				return fmt.Errorf("%s needs a value", action.Type)
+			}
+		case domain.ActionComment:
+			if strings.TrimSpace(action.Value) == "" || len(action.Value) > 5000 {
+				return fmt.Errorf("comment text must be between 1 and 5000 characters")
+			}
+		case domain.ActionWebhook:
+			target, err := url.Parse(action.URL)
+			if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+				return fmt.Errorf("webhook url must be an absolute http(s) url")
+			}
+		default:
+			return fmt.Errorf("'%s' is not an action type", action.Type)
+		}
+	}
+	return nil
+}
+
+func NewRuleUsecase(ruleRepository domain.RuleRepository, executionRepository domain.RuleExecutionRepository, taskRepository domain.TaskRepository, webhooks domain.WebhookSender, contextTimeout time.Duration) domain.RuleUsecase {
+	return &ruleUsecase{
+		ruleRepository:      ruleRepository,
+		executionRepository: executionRepository,
+		taskRepository:      taskRepository,
+		webhooks:            webhooks,
+		contextTimeout:      contextTimeout,
+	}
+}
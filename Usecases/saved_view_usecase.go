@@ -0,0 +1,125 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type savedViewUsecase struct {
+	savedViewRepository domain.SavedViewRepository
+	taskRepository      domain.TaskRepository
+	contextTimeout      time.Duration
+}
+
+// Create implements domains.SavedViewUsecase.
+func (s *savedViewUsecase) Create(ctx context.Context, view *domain.SavedView) error {
+	if err := validateSavedView(view); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.savedViewRepository.Create(c, view)
+}
+
+// FetchVisible implements domains.SavedViewUsecase.
+func (s *savedViewUsecase) FetchVisible(ctx context.Context, userID string, projectID string) ([]*domain.SavedView, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.savedViewRepository.FetchVisible(c, userID, projectID)
+}
+
+// FetchById implements domains.SavedViewUsecase.
+func (s *savedViewUsecase) FetchById(ctx context.Context, viewID string, userID string) (*domain.SavedView, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	view, err := s.savedViewRepository.FetchById(c, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != userID && view.Visibility != domain.SavedViewProject {
+		return nil, fmt.Errorf("unauthorized to access view")
+	}
+	return view, nil
+}
+
+// UpdateById implements domains.SavedViewUsecase.
+func (s *savedViewUsecase) UpdateById(ctx context.Context, viewID string, userID string, view *domain.SavedView) error {
+	if err := validateSavedView(view); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.savedViewRepository.UpdateById(c, viewID, userID, view)
+}
+
+// DeleteById implements domains.SavedViewUsecase.
+func (s *savedViewUsecase) DeleteById(ctx context.Context, viewID string, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.savedViewRepository.DeleteById(c, viewID, userID)
+}
+
+// Execute implements domains.SavedViewUsecase.
+func (s *savedViewUsecase) Execute(ctx context.Context, viewID string, userID string, page int64, limit int64) (*domain.TaskPage, error) {
+	view, err := s.FetchById(ctx, viewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &domain.TaskQuery{
+		Filter: view.Filter,
+		Sort:   view.Sort,
+		Page:   page,
+		Limit:  limit,
+	}
+	if err := normalizeTaskQuery(query); err != nil {
+		return nil, err
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.taskRepository.FetchPage(c, query)
+}
+
+func validateSavedView(view *domain.SavedView) error {
+	view.Name = strings.TrimSpace(view.Name)
+	if view.Name == "" || len(view.Name) > 50 {
+		return fmt.Errorf("view name must be between 1 and 50 characters")
+	}
+	if view.Visibility == "" {
+		view.Visibility = domain.SavedViewPrivate
+	}
+	if view.Visibility != domain.SavedViewPrivate && view.Visibility != domain.SavedViewProject {
+		return fmt.Errorf("visibility must be PRIVATE or PROJECT")
+	}
+	if view.Visibility == domain.SavedViewProject && view.ProjectID == "" {
+		return fmt.Errorf("project_id is required to share a view with a project")
+	}
+	if view.Sort.Field != "" {
+		if err := validateTaskSortField(view.Sort.Field); err != nil {
+			return err
+		}
+	}
+	if view.Sort.Order != "" && view.Sort.Order != "asc" && view.Sort.Order != "desc" {
+		return fmt.Errorf("sort order must be 'asc' or 'desc'")
+	}
+	for _, column := range view.Columns {
+		if !domain.TaskColumns[column] {
+			return fmt.Errorf("unknown column '%s'", column)
+		}
+	}
+	return nil
+}
+
+func NewSavedViewUsecase(savedViewRepository domain.SavedViewRepository, taskRepository domain.TaskRepository, contextTimeout time.Duration) domain.SavedViewUsecase {
+	return &savedViewUsecase{
+		savedViewRepository: savedViewRepository,
+		taskRepository:      taskRepository,
+		contextTimeout:      contextTimeout,
+	}
+}
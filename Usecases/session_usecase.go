@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"context"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type sessionUsecase struct {
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	contextTimeout         time.Duration
+}
+
+// FetchActive implements domains.SessionUsecase. The session the request
+// was made from is flagged as current.
+func (su *sessionUsecase) FetchActive(ctx context.Context, userID string, currentID string) ([]*domain.Session, error) {
+	c, cancel := context.WithTimeout(context.Background(), su.contextTimeout)
+	defer cancel()
+
+	sessions, err := su.sessionRepository.FetchActive(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		session.Current = session.SessionID == currentID
+	}
+	return sessions, nil
+}
+
+// Revoke implements domains.SessionUsecase. The session's access tokens are
+// rejected from now on and its refresh tokens can no longer be exchanged.
+func (su *sessionUsecase) Revoke(ctx context.Context, userID string, sessionID string) error {
+	c, cancel := context.WithTimeout(context.Background(), su.contextTimeout)
+	defer cancel()
+
+	if err := su.sessionRepository.Revoke(c, userID, sessionID); err != nil {
+		return err
+	}
+	return su.refreshTokenRepository.RevokeFamily(c, sessionID)
+}
+
+func NewSessionUsecase(sessionRepository domain.SessionRepository, refreshTokenRepository domain.RefreshTokenRepository, contextTimeout time.Duration) domain.SessionUsecase {
+	return &sessionUsecase{
+		sessionRepository:      sessionRepository,
+		refreshTokenRepository: refreshTokenRepository,
+		contextTimeout:         contextTimeout,
+	}
+}
@@ -0,0 +1,399 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type sprintUsecase struct {
+	sprintRepository  domain.SprintRepository
+	taskRepository    domain.TaskRepository
+	historyRepository domain.TaskStatusHistoryRepository
+	transactions      domain.TransactionManager
+	contextTimeout    time.Duration
+}
+
+// Create implements domains.SprintUsecase.
+func (s *sprintUsecase) Create(ctx context.Context, sprint *domain.Sprint) error {
+	if err := validateSprint(sprint); err != nil {
+		return err
+	}
+	sprint.Status = domain.SprintPlanned
+
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.sprintRepository.Create(c, sprint)
+}
+
+// FetchAll implements domains.SprintUsecase.
+func (s *sprintUsecase) FetchAll(ctx context.Context, projectID string) ([]*domain.Sprint, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.sprintRepository.FetchAll(c, projectID)
+}
+
+// FetchById implements domains.SprintUsecase.
+func (s *sprintUsecase) FetchById(ctx context.Context, sprintID string) (*domain.Sprint, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.sprintRepository.FetchById(c, sprintID)
+}
+
+// UpdateById implements domains.SprintUsecase. Only the name, goal and dates
+// can be edited, and only until the sprint is closed.
+func (s *sprintUsecase) UpdateById(ctx context.Context, sprintID string, userID string, userType string, sprint *domain.Sprint) error {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	found, err := s.sprintRepository.FetchById(c, sprintID)
+	if err != nil {
+		return err
+	}
+	if err := checkSprintOwner(found, userID, userType); err != nil {
+		return err
+	}
+	if found.Status == domain.SprintClosed {
+		return fmt.Errorf("a closed sprint cannot be changed")
+	}
+
+	sprint.ProjectID = found.ProjectID
+	if err := validateSprint(sprint); err != nil {
+		return err
+	}
+	found.Name = sprint.Name
+	found.Goal = sprint.Goal
+	found.StartDate = sprint.StartDate
+	found.EndDate = sprint.EndDate
+	found.UpdatedAt = sprint.UpdatedAt
+	return s.sprintRepository.UpdateById(c, sprintID, found)
+}
+
+// DeleteById implements domains.SprintUsecase. Planned tasks go back to the backlog.
+func (s *sprintUsecase) DeleteById(ctx context.Context, sprintID string, userID string, userType string) error {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	sprint, err := s.sprintRepository.FetchById(c, sprintID)
+	if err != nil {
+		return err
+	}
+	if err := checkSprintOwner(sprint, userID, userType); err != nil {
+		return err
+	}
+	if sprint.Status != domain.SprintPlanned {
+		return fmt.Errorf("only planned sprints can be deleted")
+	}
+
+	tasks, err := s.taskRepository.FetchMatching(c, &domain.TaskFilter{SprintID: sprintID})
+	if err != nil {
+		return err
+	}
+	if len(tasks) > 0 {
+		if err := s.taskRepository.SetSprint(c, taskIDs(tasks), ""); err != nil {
+			return err
+		}
+	}
+	return s.sprintRepository.DeleteById(c, sprintID)
+}
+
+// Plan implements domains.SprintUsecase.
+func (s *sprintUsecase) Plan(ctx context.Context, sprintID string, userID string, userType string, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("task_ids must not be empty")
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	sprint, err := s.sprintRepository.FetchById(c, sprintID)
+	if err != nil {
+		return err
+	}
+	if err := checkSprintOwner(sprint, userID, userType); err != nil {
+		return err
+	}
+	if sprint.Status == domain.SprintClosed {
+		return fmt.Errorf("tasks cannot be planned into a closed sprint")
+	}
+
+	tasks, err := s.taskRepository.FetchMatching(c, &domain.TaskFilter{TaskIDs: ids})
+	if err != nil {
+		return err
+	}
+	if len(tasks) != len(ids) {
+		return fmt.Errorf("some tasks do not exist")
+	}
+	for _, task := range tasks {
+		if task.ProjectID != sprint.ProjectID {
+			return fmt.Errorf("task '%s' does not belong to the sprint's project", task.TaskID)
+		}
+	}
+	return s.taskRepository.SetSprint(c, ids, sprintID)
+}
+
+// Unplan implements domains.SprintUsecase.
+func (s *sprintUsecase) Unplan(ctx context.Context, sprintID string, userID string, userType string, taskID string) error {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	sprint, err := s.sprintRepository.FetchById(c, sprintID)
+	if err != nil {
+		return err
+	}
+	if err := checkSprintOwner(sprint, userID, userType); err != nil {
+		return err
+	}
+	task, err := s.taskRepository.FetchById(c, taskID)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskID)
+	}
+	if task.SprintID != sprintID {
+		return fmt.Errorf("task '%s' is not part of the sprint", taskID)
+	}
+	return s.taskRepository.SetSprint(c, []string{taskID}, "")
+}
+
+// Start implements domains.SprintUsecase.
+func (s *sprintUsecase) Start(ctx context.Context, sprintID string, userID string, userType string) (*domain.Sprint, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	sprint, err := s.sprintRepository.FetchById(c, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSprintOwner(sprint, userID, userType); err != nil {
+		return nil, err
+	}
+	if sprint.Status != domain.SprintPlanned {
+		return nil, fmt.Errorf("only planned sprints can be started")
+	}
+	if active, err := s.sprintRepository.FetchActive(c, sprint.ProjectID); err == nil {
+		return nil, fmt.Errorf("sprint '%s' is already active in this project", active.Name)
+	}
+
+	// the checks above only give a clear message; Start is what keeps two
+	// racing starts from both succeeding
+	now := time.Now()
+	if err := s.sprintRepository.Start(c, sprintID, now); err != nil {
+		return nil, err
+	}
+	sprint.Status = domain.SprintActive
+	sprint.StartedAt = &now
+	sprint.UpdatedAt = now
+	return sprint, nil
+}
+
+// Close implements domains.SprintUsecase. Unfinished tasks move to the
+// carryOverTo sprint, or back to the backlog when it is empty. Moving the
+// tasks and closing the sprint happen in one transaction, so a failure
+// cannot leave the tasks moved out of a sprint that is still active.
+func (s *sprintUsecase) Close(ctx context.Context, sprintID string, userID string, userType string, carryOverTo string) (*domain.Sprint, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	var sprint *domain.Sprint
+	err := s.transactions.WithTransaction(c, func(txCtx context.Context) error {
+		found, err := s.sprintRepository.FetchById(txCtx, sprintID)
+		if err != nil {
+			return err
+		}
+		if err := checkSprintOwner(found, userID, userType); err != nil {
+			return err
+		}
+		if found.Status != domain.SprintActive {
+			return fmt.Errorf("only active sprints can be closed")
+		}
+		if carryOverTo != "" {
+			next, err := s.sprintRepository.FetchById(txCtx, carryOverTo)
+			if err != nil {
+				return err
+			}
+			if next.SprintID == found.SprintID || next.ProjectID != found.ProjectID || next.Status == domain.SprintClosed {
+				return fmt.Errorf("unfinished tasks can only carry over to an open sprint of the same project")
+			}
+		}
+
+		tasks, err := s.taskRepository.FetchMatching(txCtx, &domain.TaskFilter{SprintID: sprintID})
+		if err != nil {
+			return err
+		}
+		var unfinished []string
+		for _, task := range tasks {
+			if task.Status != domain.TaskStatusDone {
+				unfinished = append(unfinished, task.TaskID)
+			}
+		}
+		if len(unfinished) > 0 {
+			if err := s.taskRepository.SetSprint(txCtx, unfinished, carryOverTo); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		found.Status = domain.SprintClosed
+		found.TaskIDs = taskIDs(tasks)
+		found.CarriedOver = unfinished
+		found.ClosedAt = &now
+		found.UpdatedAt = now
+		if err := s.sprintRepository.UpdateById(txCtx, sprintID, found); err != nil {
+			return err
+		}
+		sprint = found
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sprint, nil
+}
+
+// Burndown implements domains.SprintUsecase. The status of every sprint task
+// at the end of each day is rebuilt from the task status history.
+func (s *sprintUsecase) Burndown(ctx context.Context, sprintID string) (*domain.Burndown, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	sprint, err := s.sprintRepository.FetchById(c, sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*domain.Task{}
+	if sprint.Status == domain.SprintClosed {
+		if len(sprint.TaskIDs) > 0 {
+			tasks, err = s.taskRepository.FetchMatching(c, &domain.TaskFilter{TaskIDs: sprint.TaskIDs})
+		}
+	} else {
+		tasks, err = s.taskRepository.FetchMatching(c, &domain.TaskFilter{SprintID: sprintID})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[string][]*domain.TaskStatusChange{}
+	if len(tasks) > 0 {
+		changes, err := s.historyRepository.FetchByTasks(c, taskIDs(tasks))
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			history[change.TaskID] = append(history[change.TaskID], change)
+		}
+	}
+
+	first := truncateToDay(sprint.StartDate)
+	last := truncateToDay(sprint.EndDate)
+	totalDays := int(last.Sub(first).Hours()/24) + 1
+	until := last
+	if today := truncateToDay(time.Now()); today.Before(until) {
+		until = today
+	}
+
+	burndown := &domain.Burndown{Sprint: sprint, Points: []*domain.BurndownPoint{}}
+	var initialScope float64
+	for day, i := first, 0; !day.After(until); day, i = day.AddDate(0, 0, 1), i+1 {
+		endOfDay := day.AddDate(0, 0, 1)
+		point := &domain.BurndownPoint{Day: day.Format("2006-01-02")}
+		for _, task := range tasks {
+			if !task.CreatedAt.Before(endOfDay) {
+				continue
+			}
+			point.ScopePoints += task.StoryPoints
+			if statusAt(task, history[task.TaskID], endOfDay) == domain.TaskStatusDone {
+				point.CompletedPoints += task.StoryPoints
+			} else {
+				point.RemainingMinutes += task.EstimateMinutes
+			}
+		}
+		point.RemainingPoints = point.ScopePoints - point.CompletedPoints
+
+		if i == 0 {
+			initialScope = point.ScopePoints
+		}
+		if totalDays > 1 {
+			point.IdealPoints = math.Round(initialScope*(1-float64(i)/float64(totalDays-1))*100) / 100
+		}
+		burndown.Points = append(burndown.Points, point)
+	}
+	return burndown, nil
+}
+
+// statusAt returns the status a task had just before the given time. Tasks
+// without recorded history fall back to their completion time and status.
+func statusAt(task *domain.Task, changes []*domain.TaskStatusChange, at time.Time) string {
+	if len(changes) == 0 {
+		if task.CompletedAt != nil {
+			if task.CompletedAt.Before(at) {
+				return domain.TaskStatusDone
+			}
+			return ""
+		}
+		return task.Status
+	}
+
+	status := changes[0].From
+	for _, change := range changes {
+		if !change.ChangedAt.Before(at) {
+			break
+		}
+		status = change.To
+	}
+	return status
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// checkSprintOwner returns domains.ErrSprintChangeNotAllowed unless the user
+// created the sprint or is an ADMIN.
+func checkSprintOwner(sprint *domain.Sprint, userID string, userType string) error {
+	if userID != sprint.CreatedBy && userType != "ADMIN" {
+		return domain.ErrSprintChangeNotAllowed
+	}
+	return nil
+}
+
+func taskIDs(tasks []*domain.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.TaskID)
+	}
+	return ids
+}
+
+func validateSprint(sprint *domain.Sprint) error {
+	sprint.Name = strings.TrimSpace(sprint.Name)
+	if sprint.Name == "" || len(sprint.Name) > 50 {
+		return fmt.Errorf("sprint name must be between 1 and 50 characters")
+	}
+	if sprint.ProjectID == "" {
+		return fmt.Errorf("project_id is required")
+	}
+	if len(sprint.Goal) > 200 {
+		return fmt.Errorf("goal must be at most 200 characters")
+	}
+	if sprint.StartDate.IsZero() || sprint.EndDate.IsZero() {
+		return fmt.Errorf("start_date and end_date are required")
+	}
+	if !sprint.EndDate.After(sprint.StartDate) {
+		return fmt.Errorf("end_date must be after start_date")
+	}
+	return nil
+}
+
+func NewSprintUsecase(sprintRepository domain.SprintRepository, taskRepository domain.TaskRepository, historyRepository domain.TaskStatusHistoryRepository, transactions domain.TransactionManager, contextTimeout time.Duration) domain.SprintUsecase {
+	return &sprintUsecase{
+		sprintRepository:  sprintRepository,
+		taskRepository:    taskRepository,
+		historyRepository: historyRepository,
+		transactions:      transactions,
+		contextTimeout:    contextTimeout,
+	}
+}
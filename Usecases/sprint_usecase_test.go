@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// fakeSprintRepository only implements what starting a sprint uses.
+type fakeSprintRepository struct {
+	domain.SprintRepository
+	sprints map[string]*domain.Sprint
+}
+
+func (r *fakeSprintRepository) FetchById(ctx context.Context, sprintID string) (*domain.Sprint, error) {
+	sprint, ok := r.sprints[sprintID]
+	if !ok {
+		return nil, fmt.Errorf("no sprint found with id '%s'", sprintID)
+	}
+	copied := *sprint
+	return &copied, nil
+}
+
+func (r *fakeSprintRepository) FetchActive(ctx context.Context, projectID string) (*domain.Sprint, error) {
+	for _, sprint := range r.sprints {
+		if sprint.ProjectID == projectID && sprint.Status == domain.SprintActive {
+			return sprint, nil
+		}
+	}
+	return nil, fmt.Errorf("project '%s' has no active sprint", projectID)
+}
+
+func (r *fakeSprintRepository) Start(ctx context.Context, sprintID string, startedAt time.Time) error {
+	r.sprints[sprintID].Status = domain.SprintActive
+	return nil
+}
+
+// fakeTransactions runs fn without a transaction.
+type fakeTransactions struct{}
+
+func (fakeTransactions) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestSprintChangesNeedCreatorOrAdmin(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		userType string
+		allowed  bool
+	}{
+		{"creator", "alice", "USER", true},
+		{"admin", "root", "ADMIN", true},
+		{"someone else", "mallory", "USER", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sprints := &fakeSprintRepository{sprints: map[string]*domain.Sprint{
+				"s1": {SprintID: "s1", ProjectID: "p1", Status: domain.SprintPlanned, CreatedBy: "alice"},
+			}}
+			usecase := NewSprintUsecase(sprints, &fakeTaskRepository{}, nil, fakeTransactions{}, 5*time.Second)
+
+			_, err := usecase.Start(context.Background(), "s1", test.userID, test.userType)
+			if test.allowed {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrSprintChangeNotAllowed) {
+				t.Fatalf("start: err = %v, want ErrSprintChangeNotAllowed", err)
+			}
+			if sprints.sprints["s1"].Status != domain.SprintPlanned {
+				t.Error("the sprint was started anyway")
+			}
+			for name, err := range map[string]error{
+				"delete": usecase.DeleteById(context.Background(), "s1", test.userID, test.userType),
+				"plan":   usecase.Plan(context.Background(), "s1", test.userID, test.userType, []string{"t1"}),
+				"unplan": usecase.Unplan(context.Background(), "s1", test.userID, test.userType, "t1"),
+				"update": usecase.UpdateById(context.Background(), "s1", test.userID, test.userType, &domain.Sprint{Name: "Renamed"}),
+			} {
+				if !errors.Is(err, domain.ErrSprintChangeNotAllowed) {
+					t.Errorf("%s: err = %v, want ErrSprintChangeNotAllowed", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSprintCloseNeedsCreatorOrAdmin(t *testing.T) {
+	sprints := &fakeSprintRepository{sprints: map[string]*domain.Sprint{
+		"s1": {SprintID: "s1", ProjectID: "p1", Status: domain.SprintActive, CreatedBy: "alice"},
+	}}
+	usecase := NewSprintUsecase(sprints, &fakeTaskRepository{}, nil, fakeTransactions{}, 5*time.Second)
+
+	if _, err := usecase.Close(context.Background(), "s1", "mallory", "USER", ""); !errors.Is(err, domain.ErrSprintChangeNotAllowed) {
+		t.Errorf("err = %v, want ErrSprintChangeNotAllowed", err)
+	}
+}
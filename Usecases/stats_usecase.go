@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const (
+	defaultThroughputWeeks = 12
+	maxThroughputWeeks     = 104
+)
+
+// statsGroupings maps the groupings the API offers to task fields.
+var statsGroupings = map[string]string{
+	"status":   "status",
+	"assignee": "assignee_id",
+	"project":  "project_id",
+}
+
+type statsUsecase struct {
+	statsRepository domain.StatsRepository
+	contextTimeout  time.Duration
+}
+
+// Summary implements domains.StatsUsecase.
+func (s *statsUsecase) Summary(ctx context.Context, filter *domain.StatsFilter) (*domain.StatsSummary, error) {
+	if err := validateStatsFilter(filter); err != nil {
+		return nil, err
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	summary := &domain.StatsSummary{}
+	var err error
+	if summary.ByStatus, err = s.statsRepository.CountBy(c, filter, statsGroupings["status"]); err != nil {
+		return nil, err
+	}
+	for _, bucket := range summary.ByStatus {
+		summary.Total += bucket.Count
+	}
+	if summary.ByAssignee, err = s.statsRepository.CountBy(c, filter, statsGroupings["assignee"]); err != nil {
+		return nil, err
+	}
+	if summary.ByProject, err = s.statsRepository.CountBy(c, filter, statsGroupings["project"]); err != nil {
+		return nil, err
+	}
+	overdue, err := s.statsRepository.Overdue(c, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	summary.Overdue = sumBuckets(overdue)
+	if summary.Throughput, err = s.throughput(c, filter); err != nil {
+		return nil, err
+	}
+	if summary.Flow, err = s.statsRepository.Flow(c, filter); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// CountBy implements domains.StatsUsecase.
+func (s *statsUsecase) CountBy(ctx context.Context, filter *domain.StatsFilter, grouping string) ([]*domain.CountBucket, error) {
+	field, ok := statsGroupings[grouping]
+	if !ok {
+		return nil, fmt.Errorf("tasks can be counted by status, assignee or project")
+	}
+	if err := validateStatsFilter(filter); err != nil {
+		return nil, err
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.statsRepository.CountBy(c, filter, field)
+}
+
+// Overdue implements domains.StatsUsecase.
+func (s *statsUsecase) Overdue(ctx context.Context, filter *domain.StatsFilter) (*domain.OverdueStats, error) {
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+
+	buckets, err := s.statsRepository.Overdue(c, filter, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OverdueStats{Overdue: sumBuckets(buckets), ByAssignee: buckets}, nil
+}
+
+// Throughput implements domains.StatsUsecase.
+func (s *statsUsecase) Throughput(ctx context.Context, filter *domain.StatsFilter) ([]*domain.ThroughputWeek, error) {
+	if err := validateStatsFilter(filter); err != nil {
+		return nil, err
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.throughput(c, filter)
+}
+
+// Flow implements domains.StatsUsecase.
+func (s *statsUsecase) Flow(ctx context.Context, filter *domain.StatsFilter) (*domain.FlowStats, error) {
+	if err := validateStatsFilter(filter); err != nil {
+		return nil, err
+	}
+	c, cancel := context.WithTimeout(context.Background(), s.contextTimeout)
+	defer cancel()
+	return s.statsRepository.Flow(c, filter)
+}
+
+// throughput reports every week of the range, including the empty ones. The
+// range defaults to the last twelve weeks and is cut to whole weeks.
+func (s *statsUsecase) throughput(ctx context.Context, filter *domain.StatsFilter) ([]*domain.ThroughputWeek, error) {
+	to := time.Now().UTC()
+	if filter.To != nil {
+		to = filter.To.UTC()
+	}
+	from := weekStart(to).AddDate(0, 0, -7*(defaultThroughputWeeks-1))
+	if filter.From != nil {
+		from = weekStart(filter.From.UTC())
+	}
+	if to.Sub(from) > maxThroughputWeeks*7*24*time.Hour {
+		return nil, fmt.Errorf("throughput covers at most %d weeks", maxThroughputWeeks)
+	}
+
+	weeks, err := s.statsRepository.Throughput(ctx, &domain.StatsFilter{ProjectID: filter.ProjectID, From: &from, To: &to})
+	if err != nil {
+		return nil, err
+	}
+	found := map[time.Time]*domain.ThroughputWeek{}
+	for _, week := range weeks {
+		found[week.WeekStart] = week
+	}
+	throughput := []*domain.ThroughputWeek{}
+	for start := from; start.Before(to); start = start.AddDate(0, 0, 7) {
+		week, ok := found[start]
+		if !ok {
+			week = &domain.ThroughputWeek{WeekStart: start}
+		}
+		throughput = append(throughput, week)
+	}
+	return throughput, nil
+}
+
+// weekStart returns midnight UTC of the Monday starting t's week.
+func weekStart(t time.Time) time.Time {
+	day := utcDay(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func sumBuckets(buckets []*domain.CountBucket) int64 {
+	var total int64
+	for _, bucket := range buckets {
+		total += bucket.Count
+	}
+	return total
+}
+
+func validateStatsFilter(filter *domain.StatsFilter) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("from must be before to")
+	}
+	return nil
+}
+
+func NewStatsUsecase(statsRepository domain.StatsRepository, contextTimeout time.Duration) domain.StatsUsecase {
+	return &statsUsecase{
+		statsRepository: statsRepository,
+		contextTimeout:  contextTimeout,
+	}
+}
@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"context"
+	"log"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// statusHistoryRecorder stores every status a task moves through so sprint
+// charts can be rebuilt for any past day.
+type statusHistoryRecorder struct {
+	historyRepository domain.TaskStatusHistoryRepository
+}
+
+// HandleTaskEvent implements domains.TaskEventHandler.
+func (s *statusHistoryRecorder) HandleTaskEvent(ctx context.Context, event *domain.TaskEvent) {
+	change := &domain.TaskStatusChange{
+		TaskID:    event.Task.TaskID,
+		To:        event.Task.Status,
+		ChangedBy: event.ActorID,
+		ChangedAt: event.OccurredAt,
+	}
+	switch event.Type {
+	case domain.TaskCreated:
+	case domain.TaskStatusChanged:
+		change.From = event.Previous.Status
+	default:
+		return
+	}
+
+	if err := s.historyRepository.Create(ctx, change); err != nil {
+		log.Println("error recording task status change:", err)
+	}
+}
+
+func NewStatusHistoryRecorder(historyRepository domain.TaskStatusHistoryRepository) domain.TaskEventHandler {
+	return &statusHistoryRecorder{
+		historyRepository: historyRepository,
+	}
+}
@@ -0,0 +1,250 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	maxTemplateTasks = 100
+	maxTemplateDepth = 5
+)
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+type taskTemplateUsecase struct {
+	templateRepository domain.TaskTemplateRepository
+	taskUsecase        domain.TaskUsecase
+	transactions       domain.TransactionManager
+	contextTimeout     time.Duration
+}
+
+// Create implements domains.TaskTemplateUsecase.
+func (t *taskTemplateUsecase) Create(ctx context.Context, template *domain.TaskTemplate) error {
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.templateRepository.Create(c, template)
+}
+
+// FetchAll implements domains.TaskTemplateUsecase.
+func (t *taskTemplateUsecase) FetchAll(ctx context.Context, projectID string) ([]*domain.TaskTemplate, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.templateRepository.FetchAll(c, projectID)
+}
+
+// FetchById implements domains.TaskTemplateUsecase.
+func (t *taskTemplateUsecase) FetchById(ctx context.Context, templateID string) (*domain.TaskTemplate, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.templateRepository.FetchById(c, templateID)
+}
+
+// UpdateById implements domains.TaskTemplateUsecase.
+func (t *taskTemplateUsecase) UpdateById(ctx context.Context, templateID string, userID string, userType string, template *domain.TaskTemplate) error {
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	if err := t.checkOwner(c, templateID, userID, userType); err != nil {
+		return err
+	}
+	return t.templateRepository.UpdateById(c, templateID, template)
+}
+
+// DeleteById implements domains.TaskTemplateUsecase.
+func (t *taskTemplateUsecase) DeleteById(ctx context.Context, templateID string, userID string, userType string) error {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	if err := t.checkOwner(c, templateID, userID, userType); err != nil {
+		return err
+	}
+	return t.templateRepository.DeleteById(c, templateID)
+}
+
+// checkOwner returns domains.ErrTemplateChangeNotAllowed unless the user
+// created the template or is an ADMIN.
+func (t *taskTemplateUsecase) checkOwner(ctx context.Context, templateID string, userID string, userType string) error {
+	template, err := t.templateRepository.FetchById(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if userID != template.CreatedBy && userType != "ADMIN" {
+		return domain.ErrTemplateChangeNotAllowed
+	}
+	return nil
+}
+
+// Instantiate implements domains.TaskTemplateUsecase. The whole tree is
+// created through the task usecase inside one transaction, so a failure part
+// way through leaves no tasks behind. The created events are published after
+// the commit.
+func (t *taskTemplateUsecase) Instantiate(ctx context.Context, templateID string, userID string, request *domain.TemplateInstantiation) ([]*domain.Task, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	template, err := t.templateRepository.FetchById(c, templateID)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, variable := range template.Variables {
+		if strings.TrimSpace(request.Variables[variable]) == "" {
+			missing = append(missing, variable)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing values for variables: %s", strings.Join(missing, ", "))
+	}
+
+	base := request.StartDate
+	if base.IsZero() {
+		base = time.Now()
+	}
+	base = truncateToDay(base)
+	projectID := request.ProjectID
+	if projectID == "" {
+		projectID = template.ProjectID
+	}
+
+	var created []*domain.Task
+	var pending *pendingTaskEvents
+	err = t.transactions.WithTransaction(c, func(txCtx context.Context) error {
+		// the transaction may be retried, so the tree is rebuilt on every attempt
+		created = nil
+		txCtx, pending = deferTaskEvents(txCtx)
+		var createTree func(nodes []domain.TemplateTask, parentID string) error
+		createTree = func(nodes []domain.TemplateTask, parentID string) error {
+			for _, node := range nodes {
+				task := instantiateTask(node, request.Variables, base, projectID, parentID, request.AssigneeID, userID)
+				if err := t.taskUsecase.Create(txCtx, task); err != nil {
+					return fmt.Errorf("creating '%s': %w", task.Title, err)
+				}
+				created = append(created, task)
+				if err := createTree(node.Children, task.TaskID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return createTree(template.Tasks, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	pending.Publish(c)
+	return created, nil
+}
+
+func instantiateTask(node domain.TemplateTask, variables map[string]string, base time.Time, projectID, parentID, assigneeID, userID string) *domain.Task {
+	now := time.Now()
+	task := &domain.Task{
+		ID:              primitive.NewObjectID(),
+		Title:           substituteVariables(node.Title, variables),
+		Description:     substituteVariables(node.Description, variables),
+		Status:          domain.TaskStatusTodo,
+		Priority:        node.Priority,
+		StoryPoints:     node.StoryPoints,
+		EstimateMinutes: node.EstimateMinutes,
+		StartDate:       base.AddDate(0, 0, node.StartOffsetDays),
+		DueDate:         base.AddDate(0, 0, node.DueOffsetDays),
+		ProjectID:       projectID,
+		ParentID:        parentID,
+		AssigneeID:      assigneeID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		CreatedBy:       userID,
+		UpdatedBy:       userID,
+	}
+	task.TaskID = task.ID.Hex()
+	return task
+}
+
+func substituteVariables(text string, variables map[string]string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := templatePlaceholder.FindStringSubmatch(placeholder)[1]
+		return variables[name]
+	})
+}
+
+// validateTemplate checks the task tree and records the placeholders it uses.
+func validateTemplate(template *domain.TaskTemplate) error {
+	template.Name = strings.TrimSpace(template.Name)
+	if template.Name == "" || len(template.Name) > 50 {
+		return fmt.Errorf("template name must be between 1 and 50 characters")
+	}
+	if len(template.Tasks) == 0 {
+		return fmt.Errorf("a template needs at least one task")
+	}
+
+	variables := map[string]bool{}
+	count := 0
+	var walk func(nodes []domain.TemplateTask, depth int) error
+	walk = func(nodes []domain.TemplateTask, depth int) error {
+		if depth > maxTemplateDepth {
+			return fmt.Errorf("templates can be nested at most %d levels deep", maxTemplateDepth)
+		}
+		for i := range nodes {
+			node := &nodes[i]
+			count++
+			node.Title = strings.TrimSpace(node.Title)
+			if node.Title == "" {
+				return fmt.Errorf("every template task needs a title")
+			}
+			if node.StartOffsetDays < 0 || node.DueOffsetDays < 0 {
+				return fmt.Errorf("offsets of '%s' must not be negative", node.Title)
+			}
+			if node.DueOffsetDays < node.StartOffsetDays {
+				return fmt.Errorf("'%s' is due before it starts", node.Title)
+			}
+			probe := &domain.Task{Priority: node.Priority, StoryPoints: node.StoryPoints, EstimateMinutes: node.EstimateMinutes}
+			if err := validateTask(probe); err != nil {
+				return fmt.Errorf("'%s': %w", node.Title, err)
+			}
+			for _, text := range []string{node.Title, node.Description} {
+				for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+					variables[match[1]] = true
+				}
+			}
+			if err := walk(node.Children, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(template.Tasks, 1); err != nil {
+		return err
+	}
+	if count > maxTemplateTasks {
+		return fmt.Errorf("a template can hold at most %d tasks", maxTemplateTasks)
+	}
+
+	template.Variables = make([]string, 0, len(variables))
+	for variable := range variables {
+		template.Variables = append(template.Variables, variable)
+	}
+	sort.Strings(template.Variables)
+	return nil
+}
+
+func NewTaskTemplateUsecase(templateRepository domain.TaskTemplateRepository, taskUsecase domain.TaskUsecase, transactions domain.TransactionManager, contextTimeout time.Duration) domain.TaskTemplateUsecase {
+	return &taskTemplateUsecase{
+		templateRepository: templateRepository,
+		taskUsecase:        taskUsecase,
+		transactions:       transactions,
+		contextTimeout:     contextTimeout,
+	}
+}
@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+type fakeTaskTemplateRepository struct {
+	domain.TaskTemplateRepository
+	templates map[string]*domain.TaskTemplate
+}
+
+func (r *fakeTaskTemplateRepository) FetchById(ctx context.Context, templateID string) (*domain.TaskTemplate, error) {
+	template, ok := r.templates[templateID]
+	if !ok {
+		return nil, fmt.Errorf("no template found with id '%s'", templateID)
+	}
+	copied := *template
+	return &copied, nil
+}
+
+func (r *fakeTaskTemplateRepository) UpdateById(ctx context.Context, templateID string, template *domain.TaskTemplate) error {
+	r.templates[templateID].Name = template.Name
+	return nil
+}
+
+func (r *fakeTaskTemplateRepository) DeleteById(ctx context.Context, templateID string) error {
+	delete(r.templates, templateID)
+	return nil
+}
+
+func TestTaskTemplateChangesNeedCreatorOrAdmin(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		userType string
+		allowed  bool
+	}{
+		{"creator", "alice", "USER", true},
+		{"admin", "root", "ADMIN", true},
+		{"someone else", "mallory", "USER", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			templates := &fakeTaskTemplateRepository{templates: map[string]*domain.TaskTemplate{
+				"tpl": {TemplateID: "tpl", Name: "Onboarding", CreatedBy: "alice"},
+			}}
+			usecase := NewTaskTemplateUsecase(templates, nil, fakeTransactions{}, 5*time.Second)
+
+			update := &domain.TaskTemplate{Name: "Offboarding", Tasks: []domain.TemplateTask{{Title: "Return the laptop"}}}
+			err := usecase.UpdateById(context.Background(), "tpl", test.userID, test.userType, update)
+			if test.allowed != (err == nil) {
+				t.Fatalf("update: err = %v, allowed %v", err, test.allowed)
+			}
+			if !test.allowed && !errors.Is(err, domain.ErrTemplateChangeNotAllowed) {
+				t.Errorf("update: err = %v, want ErrTemplateChangeNotAllowed", err)
+			}
+
+			err = usecase.DeleteById(context.Background(), "tpl", test.userID, test.userType)
+			if test.allowed != (err == nil) {
+				t.Fatalf("delete: err = %v, allowed %v", err, test.allowed)
+			}
+			if _, kept := templates.templates["tpl"]; kept == test.allowed {
+				t.Errorf("template kept = %v after delete, allowed %v", kept, test.allowed)
+			}
+		})
+	}
+}
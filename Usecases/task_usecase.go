@@ -2,28 +2,67 @@ package usecases
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	domain "github.com/segnig/task-manager/Domains"
 )
 
 type taskUsecase struct {
-	taskRepository domain.TaskRepository
-	contextTimeout time.Duration
+	taskRepository     domain.TaskRepository
+	calendarRepository domain.CalendarRepository
+	contextTimeout     time.Duration
+	eventHandlers      []domain.TaskEventHandler
 }
 
 // Create implements domains.TaskUsecase.
 func (t *taskUsecase) Create(ctx context.Context, task *domain.Task) error {
-	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	task.CompletedAt = completionTime(nil, task.Status, task.CreatedAt)
+
+	// ctx is kept so tasks created inside a transaction join it
+	c, cancel := context.WithTimeout(ctx, t.contextTimeout)
 	defer cancel()
-	return t.taskRepository.Create(c, task)
+	if err := t.resolveDueDate(c, task, task.CreatedBy); err != nil {
+		return err
+	}
+	if err := t.taskRepository.Create(c, task); err != nil {
+		return err
+	}
+	publishTaskEvent(c, t.eventHandlers, &domain.TaskEvent{
+		Type:       domain.TaskCreated,
+		Task:       task,
+		ActorID:    task.CreatedBy,
+		OccurredAt: time.Now(),
+	})
+	return nil
 }
 
 // DeleteById implements domains.TaskUsecase.
 func (t *taskUsecase) DeleteById(ctx context.Context, taskId string, userID string) error {
 	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
 	defer cancel()
-	return t.taskRepository.DeleteById(c, taskId, userID)
+
+	previous, err := t.taskRepository.FetchById(c, taskId)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	if err := t.taskRepository.DeleteById(c, taskId, userID); err != nil {
+		return err
+	}
+	publishTaskEvent(c, t.eventHandlers, &domain.TaskEvent{
+		Type:       domain.TaskDeleted,
+		Task:       previous,
+		Previous:   previous,
+		ActorID:    userID,
+		OccurredAt: time.Now(),
+	})
+	return nil
 }
 
 // FetchAll implements domains.TaskUsecase.
@@ -33,6 +72,96 @@ func (t *taskUsecase) FetchAll(ctx context.Context) ([]*domain.Task, error) {
 	return t.taskRepository.FetchAll(c)
 }
 
+// Export implements domains.TaskUsecase. It returns every matching task in
+// the requested order, up to maxExportTasks.
+func (t *taskUsecase) Export(ctx context.Context, query *domain.TaskQuery) ([]*domain.Task, error) {
+	if err := normalizeTaskQuery(query); err != nil {
+		return nil, err
+	}
+	query.Page = 1
+	query.Limit = maxExportTasks
+
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	page, err := t.taskRepository.FetchPage(c, query)
+	if err != nil {
+		return nil, err
+	}
+	if page.Total > maxExportTasks {
+		return nil, fmt.Errorf("%d tasks match; narrow the filter to at most %d", page.Total, maxExportTasks)
+	}
+	return page.Tasks, nil
+}
+
+// FetchPage implements domains.TaskUsecase.
+func (t *taskUsecase) FetchPage(ctx context.Context, query *domain.TaskQuery) (*domain.TaskPage, error) {
+	if err := normalizeTaskQuery(query); err != nil {
+		return nil, err
+	}
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.taskRepository.FetchPage(c, query)
+}
+
+const (
+	defaultTaskPageLimit = 20
+	maxTaskPageLimit     = 100
+	maxExportTasks       = 5000
+)
+
+// validateTaskSortField accepts the built-in sort fields and cf.<key> for
+// custom fields.
+func validateTaskSortField(field string) error {
+	if key, ok := strings.CutPrefix(field, domain.CustomFieldSortPrefix); ok {
+		if !domain.CustomFieldKeyPattern.MatchString(key) {
+			return fmt.Errorf("cannot sort tasks by '%s'", field)
+		}
+		return nil
+	}
+	if _, ok := domain.TaskSortFields[field]; !ok {
+		return fmt.Errorf("cannot sort tasks by '%s'", field)
+	}
+	return nil
+}
+
+func normalizeTaskQuery(query *domain.TaskQuery) error {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultTaskPageLimit
+	}
+	if query.Limit > maxTaskPageLimit {
+		query.Limit = maxTaskPageLimit
+	}
+	if query.Sort.Field == "" {
+		query.Sort.Field = "created_at"
+	}
+	if err := validateTaskSortField(query.Sort.Field); err != nil {
+		return err
+	}
+	if query.Sort.Order == "" {
+		query.Sort.Order = "desc"
+	}
+	if query.Sort.Order != "asc" && query.Sort.Order != "desc" {
+		return fmt.Errorf("sort order must be 'asc' or 'desc'")
+	}
+	if query.Filter.LabelMatch != "" && query.Filter.LabelMatch != domain.LabelMatchAny && query.Filter.LabelMatch != domain.LabelMatchAll {
+		return fmt.Errorf("label_match must be 'any' or 'all'")
+	}
+	for _, condition := range query.Filter.CustomFields {
+		if !domain.CustomFieldKeyPattern.MatchString(condition.Key) {
+			return fmt.Errorf("'%s' is not a valid custom field key", condition.Key)
+		}
+		switch condition.Op {
+		case domain.CustomFieldEq, domain.CustomFieldGte, domain.CustomFieldLte:
+		default:
+			return fmt.Errorf("custom field filters support 'eq', 'gte' and 'lte', not '%s'", condition.Op)
+		}
+	}
+	return nil
+}
+
 // FetchById implements domains.TaskUsecase.
 func (t *taskUsecase) FetchById(ctx context.Context, taskId string) (*domain.Task, error) {
 	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
@@ -42,14 +171,207 @@ func (t *taskUsecase) FetchById(ctx context.Context, taskId string) (*domain.Tas
 
 // UpdateById implements domains.TaskUsecase.
 func (t *taskUsecase) UpdateById(ctx context.Context, taskId string, userID string, task *domain.Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	previous, err := t.taskRepository.FetchById(c, taskId)
+	if err != nil {
+		return fmt.Errorf("no task found with id '%s'", taskId)
+	}
+	task.CompletedAt = completionTime(previous, task.Status, task.UpdatedAt)
+	if err := t.resolveDueDate(c, task, userID); err != nil {
+		return err
+	}
+
+	if err := t.taskRepository.UpdateById(c, taskId, userID, task); err != nil {
+		return err
+	}
+	if len(t.eventHandlers) == 0 {
+		return nil
+	}
+
+	updated, err := t.taskRepository.FetchById(c, taskId)
+	if err != nil {
+		return err
+	}
+	event := &domain.TaskEvent{
+		Type:       domain.TaskUpdated,
+		Task:       updated,
+		Previous:   previous,
+		ActorID:    userID,
+		OccurredAt: time.Now(),
+	}
+	publishTaskEvent(c, t.eventHandlers, event)
+	if updated.Status != previous.Status {
+		statusEvent := *event
+		statusEvent.Type = domain.TaskStatusChanged
+		publishTaskEvent(c, t.eventHandlers, &statusEvent)
+	}
+	return nil
+}
+
+// resolveDueDate turns a due day, or a number of working days from now, into
+// the due date at which the task becomes overdue. Days are read in the task's
+// calendar, see taskCalendar; plain due dates are kept as given.
+func (t *taskUsecase) resolveDueDate(ctx context.Context, task *domain.Task, userID string) error {
+	if task.DueInWorkingDays == nil && task.DueDay == "" {
+		return nil
+	}
+	calendar, err := taskCalendar(ctx, t.calendarRepository, task.ProjectID, userID)
+	if err != nil {
+		return err
+	}
+
+	if task.DueInWorkingDays != nil {
+		if *task.DueInWorkingDays < 0 || *task.DueInWorkingDays > maxDueWorkingDays {
+			return fmt.Errorf("due_in_working_days must be between 0 and %d", maxDueWorkingDays)
+		}
+		task.DueDay = calendar.AddWorkingDays(time.Now(), *task.DueInWorkingDays)
+		task.DueInWorkingDays = nil
+	}
+	dueDate, err := calendar.EndOfDay(task.DueDay)
+	if err != nil {
+		return fmt.Errorf("due_day must be a date like 2025-08-07")
+	}
+	task.DueDate = dueDate
+	return nil
+}
+
+// checkTaskEditor returns domains.ErrTaskChangeNotAllowed unless the user
+// created the task or is assigned to it.
+func checkTaskEditor(task *domain.Task, userID string) error {
+	if userID != task.CreatedBy && userID != task.AssigneeID {
+		return domain.ErrTaskChangeNotAllowed
+	}
+	return nil
+}
+
+// completionTime keeps CompletedAt in step with the status: it is set when a
+// task becomes DONE, kept while it stays DONE and cleared when it is reopened.
+func completionTime(previous *domain.Task, status string, now time.Time) *time.Time {
+	if status != domain.TaskStatusDone {
+		return nil
+	}
+	if previous != nil && previous.Status == domain.TaskStatusDone && previous.CompletedAt != nil {
+		return previous.CompletedAt
+	}
+	return &now
+}
+
+func publishTaskEvent(ctx context.Context, handlers []domain.TaskEventHandler, event *domain.TaskEvent) {
+	if pending, ok := ctx.Value(pendingTaskEventsKey{}).(*pendingTaskEvents); ok {
+		pending.publish = append(pending.publish, func(ctx context.Context) {
+			publishTaskEvent(ctx, handlers, event)
+		})
+		return
+	}
+	for _, handler := range handlers {
+		handler.HandleTaskEvent(ctx, event)
+	}
+}
+
+type pendingTaskEventsKey struct{}
+
+// pendingTaskEvents holds the events raised inside a transaction. They are
+// published once it is committed, so handlers neither see writes that were
+// rolled back nor run again when the transaction is retried.
+type pendingTaskEvents struct {
+	publish []func(ctx context.Context)
+}
+
+// deferTaskEvents makes the task events raised with the returned context wait
+// until Publish is called.
+func deferTaskEvents(ctx context.Context) (context.Context, *pendingTaskEvents) {
+	pending := &pendingTaskEvents{}
+	return context.WithValue(ctx, pendingTaskEventsKey{}, pending), pending
+}
+
+func (p *pendingTaskEvents) Publish(ctx context.Context) {
+	for _, publish := range p.publish {
+		publish(ctx)
+	}
+}
+
+// Next implements domains.TaskUsecase.
+func (t *taskUsecase) Next(ctx context.Context, userID string, limit int) ([]*domain.ScoredTask, error) {
 	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
 	defer cancel()
-	return t.taskRepository.UpdateById(c, taskId, userID, task)
+
+	tasks, err := t.taskRepository.FetchOpenAssigned(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scored := make([]*domain.ScoredTask, 0, len(tasks))
+	for _, task := range tasks {
+		scored = append(scored, &domain.ScoredTask{Task: task, Score: scoreTask(task, now)})
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+
+	if limit < 1 {
+		limit = 5
+	}
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored, nil
+}
+
+const (
+	priorityWeight = 10.0
+	dueWeight      = 30.0
+	dueHorizonDays = 14.0
+	ageWeight      = 10.0
+	ageHorizonDays = 30.0
+)
+
+// scoreTask ranks a task for the "what should I work on next" list. Priority
+// contributes up to 40 points, a due date approaching within two weeks up to
+// 30 (overdue tasks get all of it) and age up to 10 over the first month.
+func scoreTask(task *domain.Task, now time.Time) float64 {
+	score := float64(domain.PriorityRanks[task.Priority]) * priorityWeight
+
+	if !task.DueDate.IsZero() {
+		daysLeft := task.DueDate.Sub(now).Hours() / 24
+		score += dueWeight * math.Min(1, math.Max(0, 1-daysLeft/dueHorizonDays))
+	}
+	if !task.CreatedAt.IsZero() {
+		ageDays := now.Sub(task.CreatedAt).Hours() / 24
+		score += ageWeight * math.Min(1, math.Max(0, ageDays/ageHorizonDays))
+	}
+	return math.Round(score*100) / 100
+}
+
+func validateTask(task *domain.Task) error {
+	if task.Priority == "" {
+		task.Priority = domain.PriorityMedium
+	}
+	rank, ok := domain.PriorityRanks[task.Priority]
+	if !ok {
+		return fmt.Errorf("priority must be one of LOW, MEDIUM, HIGH or URGENT")
+	}
+	task.PriorityRank = rank
+
+	if !domain.StoryPoints[task.StoryPoints] {
+		return fmt.Errorf("story points must be one of 0, 0.5, 1, 2, 3, 5, 8, 13 or 21")
+	}
+	if task.EstimateMinutes < 0 {
+		return fmt.Errorf("estimate must not be negative")
+	}
+	return nil
 }
 
-func NewTaskUsecase(taskRepository domain.TaskRepository, contextTimeout time.Duration) domain.TaskUsecase {
+func NewTaskUsecase(taskRepository domain.TaskRepository, calendarRepository domain.CalendarRepository, contextTimeout time.Duration, eventHandlers ...domain.TaskEventHandler) domain.TaskUsecase {
 	return &taskUsecase{
-		taskRepository: taskRepository,
-		contextTimeout: contextTimeout,
+		taskRepository:     taskRepository,
+		calendarRepository: calendarRepository,
+		contextTimeout:     contextTimeout,
+		eventHandlers:      eventHandlers,
 	}
 }
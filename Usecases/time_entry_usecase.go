@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxManualEntry = 24 * time.Hour
+
+type timeEntryUsecase struct {
+	timeEntryRepository domain.TimeEntryRepository
+	taskRepository      domain.TaskRepository
+	contextTimeout      time.Duration
+}
+
+// StartTimer implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) StartTimer(ctx context.Context, taskID string, userID string, note string) (*domain.TimeEntry, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	if _, err := t.taskRepository.FetchById(c, taskID); err != nil {
+		return nil, fmt.Errorf("no task found with id '%s'", taskID)
+	}
+
+	entry := &domain.TimeEntry{
+		ID:        primitive.NewObjectID(),
+		TaskID:    taskID,
+		UserID:    userID,
+		Source:    domain.TimeEntryTimer,
+		Note:      note,
+		StartedAt: time.Now(),
+		Running:   true,
+		CreatedAt: time.Now(),
+	}
+	entry.EntryID = entry.ID.Hex()
+
+	if err := t.timeEntryRepository.StartTimer(c, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// StopTimer implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) StopTimer(ctx context.Context, userID string) (*domain.TimeEntry, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.timeEntryRepository.StopTimer(c, userID, time.Now())
+}
+
+// FetchRunning implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) FetchRunning(ctx context.Context, userID string) (*domain.TimeEntry, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.timeEntryRepository.FetchRunning(c, userID)
+}
+
+// CreateManual implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) CreateManual(ctx context.Context, entry *domain.TimeEntry) error {
+	if entry.StartedAt.IsZero() || entry.EndedAt == nil {
+		return fmt.Errorf("started_at and ended_at are required")
+	}
+	if !entry.EndedAt.After(entry.StartedAt) {
+		return fmt.Errorf("ended_at must be after started_at")
+	}
+	if entry.EndedAt.After(time.Now()) {
+		return fmt.Errorf("time entries cannot end in the future")
+	}
+	if entry.EndedAt.Sub(entry.StartedAt) > maxManualEntry {
+		return fmt.Errorf("a time entry cannot be longer than 24 hours")
+	}
+	if len(entry.Note) > 200 {
+		return fmt.Errorf("note must be at most 200 characters")
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	if _, err := t.taskRepository.FetchById(c, entry.TaskID); err != nil {
+		return fmt.Errorf("no task found with id '%s'", entry.TaskID)
+	}
+	entry.Source = domain.TimeEntryManual
+	entry.Running = false
+	entry.DurationSeconds = int64(entry.EndedAt.Sub(entry.StartedAt).Seconds())
+	return t.timeEntryRepository.Create(c, entry)
+}
+
+// Fetch implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) Fetch(ctx context.Context, filter *domain.TimeEntryFilter) ([]*domain.TimeEntry, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.timeEntryRepository.Fetch(c, filter)
+}
+
+// DeleteById implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) DeleteById(ctx context.Context, entryID string, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+	return t.timeEntryRepository.DeleteById(c, entryID, userID)
+}
+
+// Timesheet implements domains.TimeEntryUsecase.
+func (t *timeEntryUsecase) Timesheet(ctx context.Context, filter *domain.TimeEntryFilter) (*domain.Timesheet, error) {
+	c, cancel := context.WithTimeout(context.Background(), t.contextTimeout)
+	defer cancel()
+
+	rows, err := t.timeEntryRepository.Timesheet(c, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var totalSeconds int64
+	for _, row := range rows {
+		row.Hours = secondsToHours(row.Seconds)
+		totalSeconds += row.Seconds
+	}
+	return &domain.Timesheet{Rows: rows, TotalHours: secondsToHours(totalSeconds)}, nil
+}
+
+func secondsToHours(seconds int64) float64 {
+	return math.Round(float64(seconds)/36) / 100
+}
+
+func NewTimeEntryUsecase(timeEntryRepository domain.TimeEntryRepository, taskRepository domain.TaskRepository, contextTimeout time.Duration) domain.TimeEntryUsecase {
+	return &timeEntryUsecase{
+		timeEntryRepository: timeEntryRepository,
+		taskRepository:      taskRepository,
+		contextTimeout:      contextTimeout,
+	}
+}
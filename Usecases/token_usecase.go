@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type tokenUsecase struct {
+	userToken              domain.IUserToken
+	refreshTokenRepository domain.RefreshTokenRepository
+	revocations            domain.TokenRevocationStore
+	sessionRepository      domain.SessionRepository
+	userRepository         domain.UserRepository
+	contextTimeout         time.Duration
+}
+
+// Issue implements domains.TokenUsecase. The session ID doubles as the
+// family ID of the tokens.
+func (tu *tokenUsecase) Issue(ctx context.Context, user *domain.User, client *domain.SessionClient) (*domain.TokenPair, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	session := &domain.Session{
+		SessionID:  primitive.NewObjectID().Hex(),
+		UserID:     user.UserID,
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(domain.RefreshTokenLifetime),
+	}
+	if err := tu.sessionRepository.Create(c, session); err != nil {
+		return nil, err
+	}
+	return tu.issue(c, user, session.SessionID)
+}
+
+// Refresh implements domains.TokenUsecase. The refresh token is exchanged for
+// a new pair and cannot be used again. Presenting it a second time means it
+// leaked, so every token of its family is revoked, its session ends and the
+// user has to log in.
+func (tu *tokenUsecase) Refresh(ctx context.Context, refreshToken string, client *domain.SessionClient) (*domain.TokenPair, error) {
+	claims, err := tu.userToken.ValidateToken(refreshToken)
+	if err != nil || !claims.Refresh || claims.Id == "" {
+		return nil, fmt.Errorf("refresh token is invalid or expired")
+	}
+
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	stored, err := tu.refreshTokenRepository.FetchById(c, claims.Id)
+	if err != nil {
+		return nil, fmt.Errorf("refresh token is invalid or expired")
+	}
+	if stored.Revoked {
+		return nil, fmt.Errorf("refresh token has been revoked")
+	}
+	generation, err := tu.revocations.Generation(c, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Generation < generation {
+		return nil, fmt.Errorf("refresh token has been revoked")
+	}
+	session, err := tu.sessionRepository.FetchById(c, stored.FamilyID)
+	if err != nil || !session.Active(time.Now()) {
+		return nil, fmt.Errorf("refresh token has been revoked")
+	}
+	fresh, err := tu.refreshTokenRepository.MarkUsed(c, stored.TokenID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		if err := tu.refreshTokenRepository.RevokeFamily(c, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		// the access tokens of the family go with the session
+		if err := tu.sessionRepository.Revoke(c, stored.UserID, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		log.Printf("refresh token %s of user %s was reused, revoked family %s", stored.TokenID, stored.UserID, stored.FamilyID)
+		return nil, fmt.Errorf("refresh token was already used; log in again")
+	}
+
+	// read the user again so a changed username or role shows in the new token
+	user, err := tu.userRepository.FetchById(c, stored.UserID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("no user found with id '%s'", stored.UserID)
+	}
+	pair, err := tu.issue(c, user, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tu.userRepository.UpdateAllToken(c, pair.Token, pair.RefreshToken, user.UserID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := tu.sessionRepository.Touch(c, session.SessionID, client.IP, now, now.Add(domain.RefreshTokenLifetime)); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout implements domains.TokenUsecase. The access token is denied until
+// it expires and the refresh tokens of its login stop working.
+func (tu *tokenUsecase) Logout(ctx context.Context, claims *domain.SignedDetails) error {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	if claims.Id != "" {
+		if err := tu.revocations.RevokeToken(c, claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+			return err
+		}
+	}
+	if claims.Family == "" {
+		return nil
+	}
+	if err := tu.refreshTokenRepository.RevokeFamily(c, claims.Family); err != nil {
+		return err
+	}
+	// tokens from before sessions were tracked have no session to end
+	if err := tu.sessionRepository.Revoke(c, claims.Uid, claims.Family); err != nil {
+		log.Println("error ending session:", err)
+	}
+	return nil
+}
+
+// LogoutAll implements domains.TokenUsecase. Bumping the generation rejects
+// every token minted before, including ones issued before token IDs existed.
+func (tu *tokenUsecase) LogoutAll(ctx context.Context, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	if _, err := tu.revocations.BumpGeneration(c, userID); err != nil {
+		return err
+	}
+	if err := tu.refreshTokenRepository.RevokeUser(c, userID); err != nil {
+		return err
+	}
+	return tu.sessionRepository.RevokeUser(c, userID)
+}
+
+func (tu *tokenUsecase) issue(ctx context.Context, user *domain.User, familyID string) (*domain.TokenPair, error) {
+	generation, err := tu.revocations.Generation(ctx, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	record := &domain.RefreshToken{
+		TokenID:   primitive.NewObjectID().Hex(),
+		FamilyID:  familyID,
+		UserID:    user.UserID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(domain.RefreshTokenLifetime),
+	}
+	token, refreshToken, err := tu.userToken.GenerateAllTokens(&domain.TokenSubject{
+		Username:   user.Username,
+		Uid:        user.UserID,
+		UserType:   user.UserType,
+		Generation: generation,
+		FamilyID:   familyID,
+		AccessID:   primitive.NewObjectID().Hex(),
+		RefreshID:  record.TokenID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tu.refreshTokenRepository.Create(ctx, record); err != nil {
+		return nil, err
+	}
+	return &domain.TokenPair{Token: token, RefreshToken: refreshToken}, nil
+}
+
+func NewTokenUsecase(userToken domain.IUserToken, refreshTokenRepository domain.RefreshTokenRepository, revocations domain.TokenRevocationStore, sessionRepository domain.SessionRepository, userRepository domain.UserRepository, contextTimeout time.Duration) domain.TokenUsecase {
+	return &tokenUsecase{
+		userToken:              userToken,
+		refreshTokenRepository: refreshTokenRepository,
+		revocations:            revocations,
+		sessionRepository:      sessionRepository,
+		userRepository:         userRepository,
+		contextTimeout:         contextTimeout,
+	}
+}
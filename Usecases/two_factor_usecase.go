@@ -0,0 +1,385 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+// recoveryCodeAlphabet leaves out characters that are easily confused.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+type twoFactorUsecase struct {
+	twoFactorRepository domain.TwoFactorRepository
+	challengeRepository domain.TwoFactorChallengeRepository
+	policyRepository    domain.TwoFactorPolicyRepository
+	userRepository      domain.UserRepository
+	totp                domain.TOTPProvider
+	password            domain.PasswordServiceProvider
+	issuer              string
+	contextTimeout      time.Duration
+}
+
+// Challenge implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) Challenge(ctx context.Context, user *domain.User) (*domain.TwoFactorChallenge, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := tu.twoFactorRepository.Fetch(c, user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	purpose := domain.ChallengeVerify
+	if twoFactor == nil || !twoFactor.Enabled {
+		required, err := tu.policyRepository.Required(c, user.UserType)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		purpose = domain.ChallengeSetup
+	}
+
+	// the ID is all the second step has to show, so it must not be guessable
+	challengeID, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	challenge := &domain.TwoFactorChallenge{
+		ChallengeID: challengeID,
+		UserID:      user.UserID,
+		Purpose:     purpose,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(domain.TwoFactorChallengeLifetime),
+	}
+	if err := tu.challengeRepository.Create(c, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// Verify implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) Verify(ctx context.Context, request *domain.TwoFactorLoginRequest) (string, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	challenge, err := tu.challenge(c, request.ChallengeID, domain.ChallengeVerify)
+	if err != nil {
+		return "", err
+	}
+	twoFactor, err := tu.twoFactorRepository.Fetch(c, challenge.UserID)
+	if err != nil {
+		return "", err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return "", fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	var ok bool
+	if request.RecoveryCode != "" {
+		ok, err = tu.useRecoveryCode(c, twoFactor, request.RecoveryCode)
+	} else {
+		ok, err = tu.useCode(c, twoFactor, request.Code)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", tu.fail(c, challenge)
+	}
+	if err := tu.challengeRepository.Delete(c, challenge.ChallengeID); err != nil {
+		return "", err
+	}
+	return challenge.UserID, nil
+}
+
+// BeginSetup implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) BeginSetup(ctx context.Context, challengeID string) (*domain.TwoFactorEnrollment, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	challenge, err := tu.challenge(c, challengeID, domain.ChallengeSetup)
+	if err != nil {
+		return nil, err
+	}
+	user, err := tu.userRepository.FetchById(c, challenge.UserID)
+	if err != nil || user == nil {
+		return nil, fmt.Errorf("no user found with id '%s'", challenge.UserID)
+	}
+	return tu.Begin(c, user.UserID, user.Username)
+}
+
+// ConfirmSetup implements domains.TwoFactorUsecase. It returns the user ID
+// so that the login can finish.
+func (tu *twoFactorUsecase) ConfirmSetup(ctx context.Context, challengeID string, code string) (string, *domain.RecoveryCodes, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	challenge, err := tu.challenge(c, challengeID, domain.ChallengeSetup)
+	if err != nil {
+		return "", nil, err
+	}
+	codes, err := tu.Confirm(c, challenge.UserID, code)
+	if err != nil {
+		// wrong codes count here too; a used up challenge is refused above
+		if _, failErr := tu.challengeRepository.Fail(c, challenge.ChallengeID); failErr != nil {
+			return "", nil, failErr
+		}
+		return "", nil, err
+	}
+	if err := tu.challengeRepository.Delete(c, challenge.ChallengeID); err != nil {
+		return "", nil, err
+	}
+	return challenge.UserID, codes, nil
+}
+
+// Status implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) Status(ctx context.Context, userID string, userType string) (*domain.TwoFactorStatus, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := tu.twoFactorRepository.Fetch(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := tu.policyRepository.Required(c, userType)
+	if err != nil {
+		return nil, err
+	}
+	status := &domain.TwoFactorStatus{Required: required}
+	if twoFactor != nil && twoFactor.Enabled {
+		status.Enabled = true
+		status.RecoveryCodesLeft = len(twoFactor.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// Begin implements domains.TwoFactorUsecase. Starting again replaces a
+// pending enrollment.
+func (tu *twoFactorUsecase) Begin(ctx context.Context, userID string, username string) (*domain.TwoFactorEnrollment, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	secret, err := tu.totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	err = tu.twoFactorRepository.Save(c, &domain.TwoFactor{
+		UserID:        userID,
+		Secret:        secret,
+		RecoveryCodes: []string{},
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TwoFactorEnrollment{Secret: secret, URI: tu.totp.URI(tu.issuer, username, secret)}, nil
+}
+
+// Confirm implements domains.TwoFactorUsecase. The first valid code turns
+// 2FA on; the recovery codes are shown this once.
+func (tu *twoFactorUsecase) Confirm(ctx context.Context, userID string, code string) (*domain.RecoveryCodes, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := tu.twoFactorRepository.Fetch(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || twoFactor.Enabled {
+		return nil, fmt.Errorf("no pending two-factor enrollment")
+	}
+	step, ok := tu.totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("code is invalid")
+	}
+	codes, hashed, err := tu.recoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := tu.twoFactorRepository.Enable(c, userID, hashed, step, time.Now()); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes implements domains.TwoFactorUsecase. The old codes
+// stop working.
+func (tu *twoFactorUsecase) RegenerateRecoveryCodes(ctx context.Context, userID string, code string) (*domain.RecoveryCodes, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	twoFactor, err := tu.enabled(c, userID, code)
+	if err != nil {
+		return nil, err
+	}
+	codes, hashed, err := tu.recoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := tu.twoFactorRepository.ReplaceRecoveryCodes(c, twoFactor.UserID, hashed); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) Disable(ctx context.Context, userID string, userType string, code string) error {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	required, err := tu.policyRepository.Required(c, userType)
+	if err != nil {
+		return err
+	}
+	if required {
+		return fmt.Errorf("two-factor authentication is required for %s users", userType)
+	}
+	if _, err := tu.enabled(c, userID, code); err != nil {
+		return err
+	}
+	return tu.twoFactorRepository.Delete(c, userID)
+}
+
+// Policies implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) Policies(ctx context.Context) ([]*domain.TwoFactorPolicy, error) {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+	return tu.policyRepository.FetchAll(c)
+}
+
+// SetPolicy implements domains.TwoFactorUsecase. Users of the type who have
+// not enrolled are asked to at their next login.
+func (tu *twoFactorUsecase) SetPolicy(ctx context.Context, userType string, required bool, updatedBy string) (*domain.TwoFactorPolicy, error) {
+	if userType != "ADMIN" && userType != "USER" {
+		return nil, fmt.Errorf("user type must be ADMIN or USER")
+	}
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+
+	policy := &domain.TwoFactorPolicy{
+		UserType:  userType,
+		Required:  required,
+		UpdatedBy: updatedBy,
+		UpdatedAt: time.Now(),
+	}
+	if err := tu.policyRepository.Upsert(c, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Reset implements domains.TwoFactorUsecase.
+func (tu *twoFactorUsecase) Reset(ctx context.Context, userID string) error {
+	c, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+	defer cancel()
+	return tu.twoFactorRepository.Delete(c, userID)
+}
+
+func (tu *twoFactorUsecase) challenge(ctx context.Context, challengeID string, purpose string) (*domain.TwoFactorChallenge, error) {
+	challenge, err := tu.challengeRepository.FetchById(ctx, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.Purpose != purpose || !challenge.ExpiresAt.After(time.Now()) || challenge.Attempts >= domain.MaxTwoFactorAttempts {
+		return nil, fmt.Errorf("login challenge is unknown or has expired")
+	}
+	return challenge, nil
+}
+
+// fail counts a wrong code; too many end the challenge.
+func (tu *twoFactorUsecase) fail(ctx context.Context, challenge *domain.TwoFactorChallenge) error {
+	attempts, err := tu.challengeRepository.Fail(ctx, challenge.ChallengeID)
+	if err != nil {
+		return err
+	}
+	if attempts >= domain.MaxTwoFactorAttempts {
+		tu.challengeRepository.Delete(ctx, challenge.ChallengeID)
+		return fmt.Errorf("too many wrong codes, log in again")
+	}
+	return fmt.Errorf("code is invalid")
+}
+
+// enabled returns the user's enrollment after checking a current code.
+func (tu *twoFactorUsecase) enabled(ctx context.Context, userID string, code string) (*domain.TwoFactor, error) {
+	twoFactor, err := tu.twoFactorRepository.Fetch(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return nil, fmt.Errorf("two-factor authentication is not enabled")
+	}
+	ok, err := tu.useCode(ctx, twoFactor, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("code is invalid")
+	}
+	return twoFactor, nil
+}
+
+func (tu *twoFactorUsecase) useCode(ctx context.Context, twoFactor *domain.TwoFactor, code string) (bool, error) {
+	step, ok := tu.totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return tu.twoFactorRepository.UseStep(ctx, twoFactor.UserID, step)
+}
+
+func (tu *twoFactorUsecase) useRecoveryCode(ctx context.Context, twoFactor *domain.TwoFactor, code string) (bool, error) {
+	code = normalizeRecoveryCode(code)
+	for _, hashed := range twoFactor.RecoveryCodes {
+		if ok, _ := tu.password.VerifyPassword(hashed, code); ok {
+			return tu.twoFactorRepository.UseRecoveryCode(ctx, twoFactor.UserID, hashed)
+		}
+	}
+	return false, nil
+}
+
+// recoveryCodes returns new codes like "k7mpq-2xrwd" and their hashes.
+func (tu *twoFactorUsecase) recoveryCodes() (*domain.RecoveryCodes, []string, error) {
+	codes := &domain.RecoveryCodes{RecoveryCodes: make([]string, 0, domain.RecoveryCodeCount)}
+	hashed := make([]string, 0, domain.RecoveryCodeCount)
+	limit := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for range domain.RecoveryCodeCount {
+		var code strings.Builder
+		for i := 0; i < 10; i++ {
+			if i == 5 {
+				code.WriteByte('-')
+			}
+			index, err := rand.Int(rand.Reader, limit)
+			if err != nil {
+				return nil, nil, err
+			}
+			code.WriteByte(recoveryCodeAlphabet[index.Int64()])
+		}
+		codes.RecoveryCodes = append(codes.RecoveryCodes, code.String())
+		hashed = append(hashed, tu.password.HashPassword(normalizeRecoveryCode(code.String())))
+	}
+	return codes, hashed, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+}
+
+func NewTwoFactorUsecase(twoFactorRepository domain.TwoFactorRepository, challengeRepository domain.TwoFactorChallengeRepository, policyRepository domain.TwoFactorPolicyRepository, userRepository domain.UserRepository, totp domain.TOTPProvider, password domain.PasswordServiceProvider, issuer string, contextTimeout time.Duration) domain.TwoFactorUsecase {
+	return &twoFactorUsecase{
+		twoFactorRepository: twoFactorRepository,
+		challengeRepository: challengeRepository,
+		policyRepository:    policyRepository,
+		userRepository:      userRepository,
+		totp:                totp,
+		password:            password,
+		issuer:              issuer,
+		contextTimeout:      contextTimeout,
+	}
+}
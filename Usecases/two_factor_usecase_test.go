@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+	"github.com/segnig/task-manager/Intrastructures"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeTOTPProvider accepts the codes it knows, each for its own step.
+type fakeTOTPProvider struct {
+	steps map[string]int64
+}
+
+func (p *fakeTOTPProvider) GenerateSecret() (string, error) {
+	return "SECRET", nil
+}
+
+func (p *fakeTOTPProvider) URI(issuer string, account string, secret string) string {
+	return ""
+}
+
+func (p *fakeTOTPProvider) Validate(secret string, code string, now time.Time) (int64, bool) {
+	step, ok := p.steps[code]
+	return step, ok
+}
+
+// fakeTwoFactorRepository records steps the way the mongo repository does:
+// only a step after the last one is used.
+type fakeTwoFactorRepository struct {
+	domain.TwoFactorRepository
+	twoFactor *domain.TwoFactor
+}
+
+func (r *fakeTwoFactorRepository) Fetch(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	copied := *r.twoFactor
+	return &copied, nil
+}
+
+func (r *fakeTwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	if r.twoFactor.LastStep >= step {
+		return false, nil
+	}
+	r.twoFactor.LastStep = step
+	return true, nil
+}
+
+func (r *fakeTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, recoveryCodes []string) error {
+	r.twoFactor.RecoveryCodes = recoveryCodes
+	return nil
+}
+
+func TestTwoFactorCodesCannotBeReplayed(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes []string
+		want  []bool
+	}{
+		{"a code once", []string{"100000"}, []bool{true}},
+		{"the same code twice", []string{"100000", "100000"}, []bool{true, false}},
+		{"the next step after", []string{"100000", "101000"}, []bool{true, true}},
+		{"an older step after a newer one", []string{"101000", "100000"}, []bool{true, false}},
+		{"the step used at enrollment", []string{"099000"}, []bool{false}},
+		{"an unknown code", []string{"123456", "100000"}, []bool{false, true}},
+	}
+	totp := &fakeTOTPProvider{steps: map[string]int64{"099000": 99, "100000": 100, "101000": 101}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			twoFactors := &fakeTwoFactorRepository{twoFactor: &domain.TwoFactor{UserID: "alice", Secret: "SECRET", Enabled: true, LastStep: 99}}
+			usecase := NewTwoFactorUsecase(twoFactors, nil, nil, nil, totp, Intrastructures.NewPasswordProvider(bcrypt.MinCost), "Task Manager", 5*time.Second)
+
+			for i, code := range test.codes {
+				_, err := usecase.RegenerateRecoveryCodes(context.Background(), "alice", code)
+				if (err == nil) != test.want[i] {
+					t.Errorf("code %d (%s): err = %v, want accepted %v", i, code, err, test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -48,9 +48,11 @@ func (u *userUsecase) FetchById(ctx context.Context, userId string) (*domain.Use
 }
 
 // UpdateById implements domains.TaskUsecase.
-func (u *userUsecase) UpdateById(ctx context.Context, userId string, user *domain.User) error {
+func (u *userUsecase) UpdateById(ctx context.Context, userId string, request *domain.UpdateUserRequest) error {
 	c, cancel := context.WithTimeout(context.Background(), u.contextTimeout)
 	defer cancel()
+
+	user := &domain.User{FirstName: request.FirstName, LastName: request.LastName}
 	return u.userRepository.UpdateById(c, userId, user)
 }
 
@@ -0,0 +1,435 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	domain "github.com/segnig/task-manager/Domains"
+)
+
+const (
+	defaultWorkloadWeeks     = 4
+	maxWorkloadWeeks         = 26
+	maxWeeklyCapacityMinutes = 80 * 60
+	maxTimeOffDays           = 366
+	maxSpreadDays            = 366
+	maxRebalanceSuggestions  = 50
+)
+
+type workloadUsecase struct {
+	capacityRepository domain.CapacityRepository
+	taskRepository     domain.TaskRepository
+	userRepository     domain.UserRepository
+	contextTimeout     time.Duration
+}
+
+// FetchCapacity implements domains.WorkloadUsecase.
+func (w *workloadUsecase) FetchCapacity(ctx context.Context, userID string) (*domain.Capacity, error) {
+	c, cancel := context.WithTimeout(context.Background(), w.contextTimeout)
+	defer cancel()
+	return w.capacityRepository.Fetch(c, userID)
+}
+
+// SetWeeklyCapacity implements domains.WorkloadUsecase.
+func (w *workloadUsecase) SetWeeklyCapacity(ctx context.Context, userID string, minutes int) (*domain.Capacity, error) {
+	if minutes < 0 || minutes > maxWeeklyCapacityMinutes {
+		return nil, fmt.Errorf("weekly capacity must be between 0 and %d minutes", maxWeeklyCapacityMinutes)
+	}
+	c, cancel := context.WithTimeout(context.Background(), w.contextTimeout)
+	defer cancel()
+
+	if err := w.capacityRepository.SetWeeklyMinutes(c, userID, minutes); err != nil {
+		return nil, err
+	}
+	return w.capacityRepository.Fetch(c, userID)
+}
+
+// AddTimeOff implements domains.WorkloadUsecase.
+func (w *workloadUsecase) AddTimeOff(ctx context.Context, userID string, timeOff *domain.TimeOff) error {
+	if err := validateTimeOff(timeOff); err != nil {
+		return err
+	}
+	c, cancel := context.WithTimeout(context.Background(), w.contextTimeout)
+	defer cancel()
+	return w.capacityRepository.AddTimeOff(c, userID, timeOff)
+}
+
+// RemoveTimeOff implements domains.WorkloadUsecase.
+func (w *workloadUsecase) RemoveTimeOff(ctx context.Context, userID string, timeOffID string) error {
+	c, cancel := context.WithTimeout(context.Background(), w.contextTimeout)
+	defer cancel()
+	return w.capacityRepository.RemoveTimeOff(c, userID, timeOffID)
+}
+
+// Workload implements domains.WorkloadUsecase. It covers the assignees of the
+// matching tasks, and the requested user even when they have none.
+func (w *workloadUsecase) Workload(ctx context.Context, query *domain.WorkloadQuery) ([]*domain.UserWorkload, error) {
+	normalizeWorkloadQuery(query)
+	c, cancel := context.WithTimeout(context.Background(), w.contextTimeout)
+	defer cancel()
+
+	users := []string{}
+	if query.UserID != "" {
+		users = append(users, query.UserID)
+	}
+	plan, err := w.plan(c, query, users)
+	if err != nil {
+		return nil, err
+	}
+	return plan.workloads(), nil
+}
+
+// Suggestions implements domains.WorkloadUsecase. For every over-allocated
+// week it proposes handing tasks nobody started yet to the user with the
+// most room that week, as long as the task fits their capacity in every week
+// it spans. Suggestions are made one after another on the adjusted plan, so
+// they can all be applied together.
+func (w *workloadUsecase) Suggestions(ctx context.Context, query *domain.WorkloadQuery) ([]*domain.RebalanceSuggestion, error) {
+	query.UserID = ""
+	normalizeWorkloadQuery(query)
+	c, cancel := context.WithTimeout(context.Background(), w.contextTimeout)
+	defer cancel()
+
+	allUsers, err := w.userRepository.FetchAll(c)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]string, 0, len(allUsers))
+	for _, user := range allUsers {
+		users = append(users, user.UserID)
+	}
+	plan, err := w.plan(c, query, users)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []*domain.RebalanceSuggestion{}
+	for _, from := range plan.userIDs() {
+		for week := range plan.weeks {
+			for plan.over(from, week) > 0 && len(suggestions) < maxRebalanceSuggestions {
+				suggestion := plan.moveOne(from, week)
+				if suggestion == nil {
+					break
+				}
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+	return suggestions, nil
+}
+
+// plan loads the open assigned tasks matching the query and spreads their
+// estimates over the query's weeks.
+func (w *workloadUsecase) plan(ctx context.Context, query *domain.WorkloadQuery, users []string) (*workloadPlan, error) {
+	tasks, err := w.taskRepository.FetchMatching(ctx, &domain.TaskFilter{
+		ProjectID:  query.ProjectID,
+		AssigneeID: query.UserID,
+		Statuses:   []string{domain.TaskStatusTodo, domain.TaskStatusInProgress},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, userID := range users {
+		seen[userID] = true
+	}
+	open := []*domain.Task{}
+	for _, task := range tasks {
+		if task.AssigneeID == "" {
+			continue
+		}
+		open = append(open, task)
+		if !seen[task.AssigneeID] {
+			seen[task.AssigneeID] = true
+			users = append(users, task.AssigneeID)
+		}
+	}
+
+	capacities, err := w.capacityRepository.FetchMany(ctx, users)
+	if err != nil {
+		return nil, err
+	}
+	return newWorkloadPlan(query, time.Now(), open, capacities), nil
+}
+
+// workloadPlan holds the minutes planned per user, week and task. Weeks are
+// indexes into weeks; minutes falling outside them are left out.
+type workloadPlan struct {
+	today       time.Time
+	weeks       []time.Time
+	tasks       map[string]*domain.Task
+	capacities  map[string]*domain.Capacity
+	capacity    map[string][]int
+	allocated   map[string][]int
+	allocations map[string]map[string][]int
+	unscheduled map[string][]*domain.Task
+}
+
+func newWorkloadPlan(query *domain.WorkloadQuery, now time.Time, tasks []*domain.Task, capacities map[string]*domain.Capacity) *workloadPlan {
+	plan := &workloadPlan{
+		today:       utcDay(now),
+		tasks:       map[string]*domain.Task{},
+		capacities:  capacities,
+		capacity:    map[string][]int{},
+		allocated:   map[string][]int{},
+		allocations: map[string]map[string][]int{},
+		unscheduled: map[string][]*domain.Task{},
+	}
+	for i := 0; i < query.Weeks; i++ {
+		plan.weeks = append(plan.weeks, query.From.AddDate(0, 0, 7*i))
+	}
+
+	for userID, capacity := range capacities {
+		plan.capacity[userID] = make([]int, len(plan.weeks))
+		plan.allocated[userID] = make([]int, len(plan.weeks))
+		plan.allocations[userID] = map[string][]int{}
+		for week, start := range plan.weeks {
+			for day := 0; day < 7; day++ {
+				if capacity.WorksOn(start.AddDate(0, 0, day)) {
+					plan.capacity[userID][week] += capacity.DailyMinutes()
+				}
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		if task.EstimateMinutes <= 0 {
+			continue
+		}
+		plan.tasks[task.TaskID] = task
+		if task.DueDate.IsZero() {
+			plan.unscheduled[task.AssigneeID] = append(plan.unscheduled[task.AssigneeID], task)
+			continue
+		}
+		plan.assign(task.AssigneeID, task.TaskID, plan.spread(task, capacities[task.AssigneeID]))
+	}
+	return plan
+}
+
+// spread splits the task's estimate evenly over the days the user works from
+// today, or the task's start date, until it is due. Overdue tasks land on
+// today, and tasks with no working day left on their due date.
+func (p *workloadPlan) spread(task *domain.Task, capacity *domain.Capacity) []int {
+	minutes := make([]int, len(p.weeks))
+	place := func(day time.Time, amount int) {
+		week := int(day.Sub(p.weeks[0]).Hours() / (7 * 24))
+		if day.Before(p.weeks[0]) || week >= len(p.weeks) {
+			return
+		}
+		minutes[week] += amount
+	}
+
+	end := utcDay(task.DueDate)
+	if end.Before(p.today) {
+		place(p.today, task.EstimateMinutes)
+		return minutes
+	}
+	start := p.today
+	if day := utcDay(task.StartDate); day.After(start) {
+		start = day
+	}
+	if start.After(end) {
+		start = end
+	}
+	// a due date years away is spread over the coming year at most
+	if limit := start.AddDate(0, 0, maxSpreadDays); end.After(limit) {
+		end = limit
+	}
+
+	days := []time.Time{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if capacity.WorksOn(day) {
+			days = append(days, day)
+		}
+	}
+	if len(days) == 0 {
+		place(end, task.EstimateMinutes)
+		return minutes
+	}
+	share, remainder := task.EstimateMinutes/len(days), task.EstimateMinutes%len(days)
+	for i, day := range days {
+		amount := share
+		if i < remainder {
+			amount++
+		}
+		place(day, amount)
+	}
+	return minutes
+}
+
+func (p *workloadPlan) assign(userID string, taskID string, minutes []int) {
+	p.allocations[userID][taskID] = minutes
+	for week, amount := range minutes {
+		p.allocated[userID][week] += amount
+	}
+}
+
+func (p *workloadPlan) unassign(userID string, taskID string) {
+	for week, amount := range p.allocations[userID][taskID] {
+		p.allocated[userID][week] -= amount
+	}
+	delete(p.allocations[userID], taskID)
+}
+
+func (p *workloadPlan) over(userID string, week int) int {
+	return p.allocated[userID][week] - p.capacity[userID][week]
+}
+
+// moveOne hands the biggest unstarted task of the user's week to whoever has
+// the most room for it, and reports nil when no task can move.
+func (p *workloadPlan) moveOne(from string, week int) *domain.RebalanceSuggestion {
+	candidates := []string{}
+	for taskID, minutes := range p.allocations[from] {
+		if minutes[week] > 0 && p.tasks[taskID].Status == domain.TaskStatusTodo {
+			candidates = append(candidates, taskID)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := p.allocations[from][candidates[i]][week], p.allocations[from][candidates[j]][week]
+		if a != b {
+			return a > b
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	over := p.over(from, week)
+	for _, taskID := range candidates {
+		task := p.tasks[taskID]
+		best, bestRoom := "", 0
+		var bestMinutes []int
+		for _, to := range p.userIDs() {
+			if to == from {
+				continue
+			}
+			minutes := p.spread(task, p.capacities[to])
+			fits := true
+			for w, amount := range minutes {
+				if amount > 0 && p.allocated[to][w]+amount > p.capacity[to][w] {
+					fits = false
+					break
+				}
+			}
+			if room := p.capacity[to][week] - p.allocated[to][week]; fits && room > bestRoom {
+				best, bestRoom, bestMinutes = to, room, minutes
+			}
+		}
+		if best == "" {
+			continue
+		}
+
+		moved := p.allocations[from][taskID][week]
+		p.unassign(from, taskID)
+		p.assign(best, taskID, bestMinutes)
+		return &domain.RebalanceSuggestion{
+			TaskID:     taskID,
+			Title:      task.Title,
+			WeekStart:  p.weeks[week],
+			Minutes:    moved,
+			FromUserID: from,
+			ToUserID:   best,
+			Reason: fmt.Sprintf("%s is %d minutes over capacity in the week of %s and %s has %d minutes free",
+				from, over, p.weeks[week].Format(time.DateOnly), best, bestRoom),
+		}
+	}
+	return nil
+}
+
+func (p *workloadPlan) userIDs() []string {
+	userIDs := make([]string, 0, len(p.capacities))
+	for userID := range p.capacities {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	return userIDs
+}
+
+func (p *workloadPlan) workloads() []*domain.UserWorkload {
+	workloads := []*domain.UserWorkload{}
+	for _, userID := range p.userIDs() {
+		workload := &domain.UserWorkload{UserID: userID, Weeks: []*domain.WorkloadWeek{}, UnscheduledTasks: []string{}}
+		for week, start := range p.weeks {
+			entry := &domain.WorkloadWeek{
+				WeekStart:        start,
+				CapacityMinutes:  p.capacity[userID][week],
+				AllocatedMinutes: p.allocated[userID][week],
+				OverMinutes:      max(0, p.over(userID, week)),
+				Tasks:            []*domain.TaskAllocation{},
+			}
+			if entry.CapacityMinutes > 0 {
+				entry.Utilization = math.Round(float64(entry.AllocatedMinutes)/float64(entry.CapacityMinutes)*100) / 100
+			}
+			for taskID, minutes := range p.allocations[userID] {
+				if minutes[week] > 0 {
+					task := p.tasks[taskID]
+					entry.Tasks = append(entry.Tasks, &domain.TaskAllocation{TaskID: taskID, Title: task.Title, Status: task.Status, Minutes: minutes[week]})
+				}
+			}
+			sort.Slice(entry.Tasks, func(i, j int) bool {
+				if entry.Tasks[i].Minutes != entry.Tasks[j].Minutes {
+					return entry.Tasks[i].Minutes > entry.Tasks[j].Minutes
+				}
+				return entry.Tasks[i].TaskID < entry.Tasks[j].TaskID
+			})
+			workload.OverAllocated = workload.OverAllocated || entry.OverMinutes > 0
+			workload.Weeks = append(workload.Weeks, entry)
+		}
+		for _, task := range p.unscheduled[userID] {
+			workload.UnscheduledMinutes += task.EstimateMinutes
+			workload.UnscheduledTasks = append(workload.UnscheduledTasks, task.TaskID)
+		}
+		workloads = append(workloads, workload)
+	}
+	return workloads
+}
+
+func normalizeWorkloadQuery(query *domain.WorkloadQuery) {
+	if query.Weeks < 1 {
+		query.Weeks = defaultWorkloadWeeks
+	}
+	if query.Weeks > maxWorkloadWeeks {
+		query.Weeks = maxWorkloadWeeks
+	}
+	if query.From.IsZero() {
+		query.From = time.Now()
+	}
+	query.From = weekStart(query.From)
+}
+
+func validateTimeOff(timeOff *domain.TimeOff) error {
+	if timeOff.From.IsZero() || timeOff.To.IsZero() {
+		return fmt.Errorf("time off needs a from and a to date")
+	}
+	timeOff.From = utcDay(timeOff.From)
+	timeOff.To = utcDay(timeOff.To)
+	if timeOff.To.Before(timeOff.From) {
+		return fmt.Errorf("time off cannot end before it starts")
+	}
+	if timeOff.To.Sub(timeOff.From) >= maxTimeOffDays*24*time.Hour {
+		return fmt.Errorf("time off can span at most %d days", maxTimeOffDays)
+	}
+	timeOff.Note = strings.TrimSpace(timeOff.Note)
+	if len(timeOff.Note) > 100 {
+		return fmt.Errorf("note must be at most 100 characters")
+	}
+	return nil
+}
+
+// utcDay returns midnight UTC of t's day.
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func NewWorkloadUsecase(capacityRepository domain.CapacityRepository, taskRepository domain.TaskRepository, userRepository domain.UserRepository, contextTimeout time.Duration) domain.WorkloadUsecase {
+	return &workloadUsecase{
+		capacityRepository: capacityRepository,
+		taskRepository:     taskRepository,
+		userRepository:     userRepository,
+		contextTimeout:     contextTimeout,
+	}
+}